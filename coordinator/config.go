@@ -0,0 +1,71 @@
+package main
+
+// This file handles loading coordinator configuration from a file.
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile returns the path of the config file applied if --config is not given:
+// omen/coordinator.yaml within the user's config directory (ex: ~/.config/omen/coordinator.yaml).
+func defaultConfigFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "omen", "coordinator.yaml"), nil
+}
+
+// applyConfigFile reads the YAML file at pth and sets each flag it names.
+// Keys in the file must match flag names (ex: `grafana-port: 3001`).
+// Flags explicitly set on the command line take precedence over the file and are left untouched.
+// If pth is not explicit (it is the default file), it need not exist.
+func applyConfigFile(fs *pflag.FlagSet, pth string, explicit bool) error {
+	data, err := os.ReadFile(pth)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		log.Debug().Str("path", pth).Msg("no default config file")
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("parse config file %s: %w", pth, err)
+	}
+
+	for key, value := range values {
+		f := fs.Lookup(key)
+		if f == nil {
+			return fmt.Errorf("config file %s: unknown option %q", pth, key)
+		} else if key == "config" {
+			return fmt.Errorf("config file %s: cannot reference another config file", pth)
+		}
+		if f.Changed { // command line overrides the file
+			log.Debug().Str("option", key).Msg("option set by flag; ignoring config file value")
+			continue
+		}
+		var str string
+		switch v := value.(type) {
+		case []any: // slice flags accept comma-separated values
+			parts := make([]string, len(v))
+			for i := range v {
+				parts[i] = fmt.Sprint(v[i])
+			}
+			str = strings.Join(parts, ",")
+		default:
+			str = fmt.Sprint(v)
+		}
+		if err := fs.Set(key, str); err != nil {
+			return fmt.Errorf("config file %s: option %q: %w", pth, key, err)
+		}
+		log.Debug().Str("option", key).Str("value", str).Msg("set option from config file")
+	}
+	return nil
+}
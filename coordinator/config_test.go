@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// testFlagSet returns a flag set resembling the coordinator's, with db given on the command line.
+func testFlagSet(t *testing.T) *pflag.FlagSet {
+	t.Helper()
+	fs := pflag.NewFlagSet("coordinator", pflag.ContinueOnError)
+	fs.Uint16("grafana-port", 3000, "")
+	fs.String("db", DefaultDBPath, "")
+	fs.Bool("merge", false, "")
+	fs.StringSlice("labels", nil, "")
+	fs.String("config", "", "")
+	if err := fs.Parse([]string{"--db=cli.db"}); err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+func Test_applyConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr string // substring of the expected error; empty if the file applies
+	}{
+		{"values", "grafana-port: 3001\nmerge: true\nlabels: [a, b]\n", ""},
+		{"flag overrides file", "grafana-port: 3001\nmerge: true\nlabels: [a, b]\ndb: file.db\n", ""},
+		{"empty", "", ""},
+		{"unknown key", "grafana-port: 3001\ngrafana-prot: 3002\n", `unknown option "grafana-prot"`},
+		{"nested config", "config: other.yaml\n", "cannot reference another config file"},
+		{"invalid value", "grafana-port: not-a-port\n", `option "grafana-port"`},
+		{"malformed", "grafana-port: [3001\n", "parse config file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pth := filepath.Join(t.TempDir(), "coordinator.yaml")
+			if err := os.WriteFile(pth, []byte(tt.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+			fs := testFlagSet(t)
+			err := applyConfigFile(fs, pth, true)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applyConfigFile() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("applyConfigFile() = %v", err)
+			}
+
+			if db, _ := fs.GetString("db"); db != "cli.db" {
+				t.Errorf("db = %q, want the command line's %q", db, "cli.db")
+			}
+			if tt.file == "" {
+				return
+			}
+			if port, _ := fs.GetUint16("grafana-port"); port != 3001 {
+				t.Errorf("grafana-port = %d, want 3001", port)
+			}
+			if merge, _ := fs.GetBool("merge"); !merge {
+				t.Error("merge = false, want true")
+			}
+			if labels, _ := fs.GetStringSlice("labels"); !slices.Equal(labels, []string{"a", "b"}) {
+				t.Errorf("labels = %q, want [a b]", labels)
+			}
+		})
+	}
+}
+
+func Test_applyConfigFile_missing(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "coordinator.yaml")
+	if err := applyConfigFile(testFlagSet(t), pth, false); err != nil {
+		t.Errorf("applyConfigFile() of a missing default file = %v, want nil", err)
+	}
+	if err := applyConfigFile(testFlagSet(t), pth, true); err == nil {
+		t.Error("applyConfigFile() of a missing --config file succeeded")
+	}
+
+	// a default file that exists is held to the same rules as one given explicitly
+	if err := os.WriteFile(pth, []byte("grafana-prot: 3001\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyConfigFile(testFlagSet(t), pth, false); err == nil {
+		t.Error("applyConfigFile() of a default file with an unknown key succeeded")
+	}
+}
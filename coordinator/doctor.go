@@ -0,0 +1,242 @@
+package main
+
+// This file implements the doctor subcommand, which checks that the local and remote environments can run the pipeline.
+
+import (
+	omen "Omen"
+	"Omen/modules/1_spawn_topology/models"
+	"Omen/ssh"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const doctorTimeout = 10 * time.Second
+
+// sshInfo is the subset of the input JSON required to reach the mininet host.
+type sshInfo struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Address  string `json:"address"`
+}
+
+// a doctorCheck is a single, named item on the checklist.
+// If a check depends on another, it is skipped when its dependency did not pass.
+type doctorCheck struct {
+	name      string
+	dependsOn int // index of the check this check requires to have passed; -1 for none
+	run       func() error
+}
+
+// newDoctorCommand returns the doctor subcommand.
+// remoteFs holds the flags (--local, --jump, --identity) deciding how the test runner reaches the mininet host, so doctor checks the host the same way.
+func newDoctorCommand(remoteFs *pflag.FlagSet) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor [<input>.json]",
+		Short: "check that the environment is capable of executing the pipeline",
+		Long: "Doctor checks that docker is reachable, the required images are present, and python3 is in your PATH.\n" +
+			"If an input file is given, doctor also checks that its mininet host is reachable over SSH (through --jump, if given) and has mininet installed.\n" +
+			"Under --local, doctor instead checks that mininet is installed on this machine.",
+		Example: appName + " doctor\n" + appName + " doctor topology1.json\n" + appName + " doctor --jump me@bastion.example.edu -i ~/.ssh/id_ed25519 topology1.json",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			defer dCLI.Close()
+			local, err := cmd.Flags().GetBool("local")
+			if err != nil {
+				return err
+			}
+			var jump *models.JumpHost
+			if raw, err := cmd.Flags().GetString("jump"); err != nil {
+				return err
+			} else if raw = strings.TrimSpace(raw); raw != "" {
+				if local {
+					return errors.New("--jump cannot be combined with --local")
+				} else if jump, err = models.ParseJumpHost(raw); err != nil {
+					return err
+				}
+			}
+			identity, err := cmd.Flags().GetString("identity")
+			if err != nil {
+				return err
+			} else if identity = strings.TrimSpace(identity); identity != "" && local {
+				return errors.New("--identity cannot be combined with --local")
+			}
+
+			checks := []doctorCheck{
+				{"docker engine is reachable", -1, func() error {
+					ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+					defer cancel()
+					_, err := dCLI.Ping(ctx)
+					return err
+				}},
+				{"image " + inputValidatorImage + " is present", 0, imagePresent(inputValidatorImage + ":" + inputValidatorImageTag)},
+				{"image " + omen.VisualizationGrafanaImage + " is present", 0, imagePresent(omen.VisualizationGrafanaImage)},
+				{"python3 is in PATH", -1, func() error {
+					_, err := exec.LookPath("python3")
+					return err
+				}},
+			}
+			if local { // the test runner does not use SSH, so neither do we
+				checks = append(checks, doctorCheck{"mininet is installed on this machine", -1, func() error {
+					_, err := exec.LookPath("mn")
+					return err
+				}})
+			} else if len(args) == 1 {
+				info, err := readSSHInfo(args[0])
+				if err != nil {
+					return err
+				}
+				// through a jump host, the mininet host need not be reachable from here; only the jump host must be
+				reachable, dialAddr := "mininet host "+info.Address, info.Address
+				if jump != nil {
+					reachable, dialAddr = "jump host "+jump.String(), jump.Addr
+				}
+				reachableIdx := len(checks)
+				checks = append(checks,
+					doctorCheck{reachable + " is reachable", -1, func() error {
+						conn, err := net.DialTimeout("tcp", dialAddr, doctorTimeout)
+						if err != nil {
+							return err
+						}
+						return conn.Close()
+					}},
+					doctorCheck{"mininet is installed on " + info.Address, reachableIdx, func() error {
+						return remoteHasMininet(info, jump, identity)
+					}},
+				)
+			}
+
+			return runDoctorChecks(checks)
+		},
+	}
+	cmd.Flags().AddFlagSet(remoteFs)
+	return cmd
+}
+
+// runDoctorChecks executes each check in order, printing a pass/fail/skip checklist.
+// Returns an error if any check did not pass.
+func runDoctorChecks(checks []doctorCheck) error {
+	var (
+		passed = make([]bool, len(checks))
+		failed uint
+	)
+	for i, c := range checks {
+		if c.dependsOn >= 0 && !passed[c.dependsOn] {
+			fmt.Printf("%s %s\n", omen.WarningHeaderSty.Render("SKIP"), c.name)
+			failed += 1
+			continue
+		}
+		if err := c.run(); err != nil {
+			fmt.Printf("%s %s\n\t%v\n", omen.ErrorHeaderSty.Render("FAIL"), c.name, err)
+			failed += 1
+			continue
+		}
+		passed[i] = true
+		fmt.Printf("%s %s\n", omen.SuccessHeaderSty.Render("PASS"), c.name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks did not pass", failed, len(checks))
+	}
+	return nil
+}
+
+// imagePresent returns a check that the given image exists in the local docker engine.
+func imagePresent(image string) func() error {
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+		defer cancel()
+		if _, err := dCLI.ImageInspect(ctx, image); err != nil {
+			return fmt.Errorf("%w (have you run `mage`?)", err)
+		}
+		return nil
+	}
+}
+
+// readSSHInfo pulls the SSH information out of the input JSON at pth.
+// As with the test runner, port 22 is assumed if none is given (see models.ParseAddrPort).
+func readSSHInfo(pth string) (sshInfo, error) {
+	var info sshInfo
+	data, err := os.ReadFile(pth)
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("failed to parse %s: %w", pth, err)
+	}
+	if info.Address = strings.TrimSpace(info.Address); info.Address == "" {
+		return info, fmt.Errorf("%s does not specify an address", pth)
+	}
+	addr, _, err := models.ParseAddrPort(info.Address)
+	if err != nil {
+		return info, fmt.Errorf("%s has an invalid address: %w", pth, err)
+	}
+	info.Address = addr.String()
+	return info, nil
+}
+
+// remoteHasMininet connects to the mininet host (through jump, if non-nil) and checks that the mn binary is in the remote PATH.
+// As with the test runner, the identity key, if given, authenticates in place of the password, and authenticates jump unless
+// $jumpPasswordEnv is set.
+func remoteHasMininet(info sshInfo, jump *models.JumpHost, identity string) error {
+	creds := ssh.Credentials{Password: info.Password}
+	if identity != "" {
+		key, err := ssh.LoadPrivateKey(identity, os.Getenv(identityPassphraseEnv))
+		if errors.Is(err, ssh.ErrPassphraseMissing) {
+			return fmt.Errorf("%w (set $%s)", err, identityPassphraseEnv)
+		} else if err != nil {
+			return fmt.Errorf("--identity: %w", err)
+		}
+		creds.Key = key
+	}
+	if info.Username == "" || (creds.Password == "" && creds.Key == nil) {
+		return errors.New("input file must specify a username and password (or pass --identity)")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("locate known_hosts: %w", err)
+	}
+	// unknown hosts are rejected; the test runner asks to trust them on its first connection
+	hostKeys, err := ssh.KnownHosts(filepath.Join(home, ".ssh", "known_hosts"), nil)
+	if err != nil {
+		return err
+	}
+	var client *ssh.Client
+	if jump == nil {
+		if client, err = ssh.Connect(info.Address, info.Username, creds, hostKeys, doctorTimeout); err != nil {
+			return err
+		}
+	} else {
+		jumpCreds := ssh.Credentials{Password: os.Getenv(jumpPasswordEnv)}
+		if jumpCreds.Password == "" {
+			jumpCreds.Key = creds.Key
+		}
+		if jumpCreds.Password == "" && jumpCreds.Key == nil {
+			return fmt.Errorf("a password (or --identity) for jump host %v is required (set $%s)", jump, jumpPasswordEnv)
+		}
+		jc, err := ssh.Connect(jump.Addr, jump.Username, jumpCreds, hostKeys, doctorTimeout)
+		if err != nil {
+			return fmt.Errorf("jump host %v: %w", jump, err)
+		}
+		if client, err = ssh.ConnectVia(jc, info.Address, info.Username, creds, hostKeys, doctorTimeout); err != nil {
+			jc.Close()
+			return err
+		}
+	}
+	defer client.Close()
+	out, err := client.Run("command -v mn")
+	if err != nil {
+		return fmt.Errorf("mn not found in remote PATH: %w", err)
+	}
+	log.Debug().Str("path", strings.TrimSpace(out)).Msg("found mn on remote")
+	return nil
+}
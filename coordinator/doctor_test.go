@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_readSSHInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"with port", `{"username": "wifi", "password": "secret", "address": "192.168.64.5:2222"}`, "192.168.64.5:2222", false},
+		{"bare", `{"address": " 192.168.64.5 "}`, "192.168.64.5:22", false},
+		{"bracketed IPv6 with port", `{"address": "[2001:db8::1]:2222"}`, "[2001:db8::1]:2222", false},
+		{"bracketed IPv6", `{"address": "[2001:db8::1]"}`, "[2001:db8::1]:22", false},
+		{"bare IPv6", `{"address": "2001:db8::1"}`, "[2001:db8::1]:22", false},
+		{"no address", `{"username": "wifi"}`, "", true},
+		{"hostname", `{"address": "mininet.local"}`, "", true},
+		{"bad port", `{"address": "192.168.64.5:ssh"}`, "", true},
+		{"not JSON", `address: 192.168.64.5`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pth := filepath.Join(t.TempDir(), "in.json")
+			if err := os.WriteFile(pth, []byte(tt.input), 0644); err != nil {
+				t.Fatal(err)
+			}
+			info, err := readSSHInfo(pth)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readSSHInfo() = %+v, %v, wantErr %v", info, err, tt.wantErr)
+			} else if err == nil && info.Address != tt.want {
+				t.Errorf("readSSHInfo() address = %q, want %q", info.Address, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// lockFileName is the name of the file, within the working directory, that guards it against concurrent coordinators.
+const lockFileName string = ".omen.lock"
+
+// ErrWorkingDirLocked is returned when another coordinator is already running in the working directory.
+var ErrWorkingDirLocked = errors.New("another coordinator is already running in this directory")
+
+// lockFile is the held lock on the working directory, if any.
+// Released by cleanup.
+var lockFile *os.File
+
+// acquireLock takes an exclusive lock on the lock file in the current directory, failing fast if another coordinator holds it.
+// The lock is an advisory flock, so it is released by the OS if the coordinator dies without cleaning up;
+// the lock file itself is left in place and records the PID of the last coordinator to hold it.
+func acquireLock() error {
+	f, err := os.OpenFile(lockFileName, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file %s: %w", lockFileName, err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		defer f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			holder := "unknown"
+			if data, err := os.ReadFile(lockFileName); err == nil && strings.TrimSpace(string(data)) != "" {
+				holder = strings.TrimSpace(string(data))
+			}
+			wd, _ := os.Getwd()
+			return fmt.Errorf("%w (PID %s holds %s in %s). Wait for it to finish or use a different --working-dir",
+				ErrWorkingDirLocked, holder, lockFileName, wd)
+		}
+		return fmt.Errorf("failed to lock %s: %w", lockFileName, err)
+	}
+	// record our PID for the benefit of anyone who is turned away
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	lockFile = f
+	log.Debug().Str("file", lockFileName).Msg("acquired working directory lock")
+	return nil
+}
+
+// releaseLock releases the lock taken by acquireLock, if it is held.
+func releaseLock() {
+	if lockFile == nil {
+		return
+	}
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN); err != nil {
+		log.Warn().Err(err).Str("file", lockFileName).Msg("failed to release working directory lock")
+	}
+	lockFile.Close()
+	lockFile = nil
+}
@@ -2,6 +2,8 @@
 Package main implements the coordinator, a simple binary for sequentially executing each module in the Omen pipeline.
 
 Uses hardcoded paths and commands for module execution.
+The set of commands the coordinator may execute is enumerated by ModuleStep (see steps.go).
+The order they are executed in is set by the pipeline step registry (see pipeline.go).
 */
 package main
 
@@ -22,7 +24,8 @@ import (
 )
 
 // Hardcoded module names and paths.
-// For this to be actually modular, these should be fed in via config or env, ideally with enumerations to prevent executing arbitrary shell commands.
+// For this to be actually modular, these should be fed in via config or env.
+// Commands composed from these are restricted to the enumerated ModuleSteps.
 const (
 	appName                         string = "Omen"
 	inputValidatorImage             string = "0_omen-input-validator"
@@ -30,6 +33,15 @@ const (
 	DefaultTestRunnerBinaryPath     string = "./1_spawn"
 	DefaultCoalesceOutputBinaryPath string = "./2_output_processing"
 	DefaultLoaderScriptPath         string = "omenloader.py"
+	DefaultDriverScriptPath         string = "mininet-script.py" // the test runner's driver script
+	DefaultGrafanaDBTarget          string = "/var/lib/grafana/data.db"
+	DefaultDBPath                   string = "omen.db"
+)
+
+// Environment variables the test runner reads credentials from when it cannot prompt for them.
+const (
+	jumpPasswordEnv       string = "OMEN_JUMP_PASSWORD"
+	identityPassphraseEnv string = "OMEN_IDENTITY_PASSPHRASE"
 )
 
 var (
@@ -41,18 +53,8 @@ var (
 )
 
 func init() {
-	{ // spool up a dev logger that respects NO_COLOR
-		var nc bool
-		if v, found := os.LookupEnv("NO_COLOR"); found && (strings.TrimSpace(v) != "") {
-			nc = true
-		}
-
-		log = zerolog.New(zerolog.ConsoleWriter{
-			Out:        os.Stdout,
-			TimeFormat: time.RFC3339,
-			NoColor:    nc,
-		})
-	}
+	// spool up a dev logger; replaced in PreRunE if --json-logs is given
+	log = newLogger(false)
 	{ // connect to the local docker engine
 		var err error
 		if dCLI, err = client.NewClientWithOpts(client.FromEnv); err != nil {
@@ -61,13 +63,59 @@ func init() {
 	}
 }
 
+// newLogger returns a logger that writes to stdout.
+// If jsonOutput, the logger emits zerolog's default (JSON) format for log ingestion.
+// Otherwise, it emits human-friendly console output that respects NO_COLOR.
+func newLogger(jsonOutput bool) zerolog.Logger {
+	if jsonOutput {
+		return zerolog.New(os.Stdout).With().Timestamp().Logger()
+	}
+
+	var nc bool
+	if v, found := os.LookupEnv("NO_COLOR"); found && (strings.TrimSpace(v) != "") {
+		nc = true
+	}
+
+	return zerolog.New(zerolog.ConsoleWriter{
+		Out:        os.Stdout,
+		TimeFormat: time.RFC3339,
+		NoColor:    nc,
+	})
+}
+
 func main() {
 	// define flags
 	fs := pflag.FlagSet{}
 	fs.String("log-level", "INFO", "set verbosity of the logger. Must be one of {TRACE|DEBUG|INFO|WARN|ERROR|FATAL|PANIC}.")
+	fs.Bool("json-logs", false, "emit logs as JSON (one object per line) rather than human-friendly text")
 	fs.Uint16("grafana-port", 3000, "set the port the Grafana container should bind to")
+	fs.String("db", DefaultDBPath, "path to write the results database to and mount into Grafana (ex: baseline.db). Relative paths are resolved against --working-dir.")
+	fs.String("grafana-db-target", DefaultGrafanaDBTarget, "path within the Grafana container to mount the database at. Only needed for custom Grafana images.")
+	fs.Bool("grafana-db-read-only", true, "mount the database into the Grafana container read-only")
 	fs.StringP("test-runner", "1", DefaultTestRunnerBinaryPath, "override the path to the test runner binary")
 	fs.StringP("coalesce-output", "2", DefaultCoalesceOutputBinaryPath, "override the path to the coalesce output binary")
+	fs.String("config", "", "path to a YAML file of flag values (ex: `grafana-port: 3001`). Flags given on the command line override the file. "+
+		"Defaults to omen/coordinator.yaml within your config directory (ex: ~/.config/omen/coordinator.yaml), if it exists.")
+	fs.Duration("max-runtime", 0, "abort the pipeline (and remove any containers it started) if it has not completed within this duration (ex: 2h30m). 0 disables the limit.")
+	fs.String("working-dir", "", "directory to execute the pipeline within (created if it does not exist). All artefacts (database, results, logs) are written here. Defaults to the current directory.")
+	fs.Bool("merge", false, "append this run to the database at --db (stamping its rows with a run ID and timestamp) rather than recreating its tables. The database must have been created with --merge.")
+	fs.BoolP("assume-yes", "y", false, "answer every confirmation of the pipeline (including the test runner's) with yes. "+
+		"The test runner is always run with --interactive=false, so, without this, its confirmations fail rather than proceed.")
+	// how the mininet host is reached; shared with doctor, so it checks the host the same way the test runner reaches it
+	remoteFs := pflag.FlagSet{}
+	remoteFs.Bool("local", false, "run the test runner against mininet on this machine (see the test runner's --local) rather than connecting to the topology's address over SSH. "+
+		"As the test runner is non-interactive, sudo must not prompt for a password (ex: NOPASSWD).")
+	remoteFs.String("jump", "", "bastion the test runner tunnels its SSH connection through (see the test runner's --jump), of the form <user>@<host>[:<port>]. "+
+		"As the test runner is non-interactive, the bastion's password must be set in $"+jumpPasswordEnv+" unless --identity authenticates it.")
+	remoteFs.StringP("identity", "i", "", "private key the test runner authenticates with in place of the password (see the test runner's --identity). "+
+		"As the test runner is non-interactive, an encrypted key's passphrase must be set in $"+identityPassphraseEnv+".")
+	fs.Uint("repetitions", 1, "number of times the test runner measures the pingall matrix each timeframe (see the test runner's --repetitions). "+
+		"Repeated pings are aggregated into ping_stats.csv.")
+	fs.Duration("test-runner-timeout", 0, "kill the test runner (and fail the pipeline) if it has not completed within this duration (ex: 30m). 0 disables the limit.")
+	fs.Duration("coalesce-timeout", 0, "kill the coalesce output module (and fail the pipeline) if it has not completed within this duration. 0 disables the limit.")
+	fs.Duration("loader-timeout", 0, "kill each loader invocation (and fail the pipeline) if it has not completed within this duration. 0 disables the limit.")
+	fs.Bool("validate-output", false, "check the header and a sample of rows of each coalesced CSV against its expected schema before loading it, failing on the first malformed file")
+	fs.String("notify-url", "", "POST a JSON summary of the run (status, input, duration, and the Grafana URL or error) to this webhook (ex: a Slack incoming webhook) when the pipeline completes or fails. Best-effort: an unreachable webhook does not fail the run.")
 
 	// generate the command tree
 	root := &cobra.Command{
@@ -76,6 +124,24 @@ func main() {
 		Long: appName + ` is a helper pipeline capable of building topologies and testing them automatically.
 Because Omen is a set of disparate modules run in sequence, this binary (the Coordinator) just serves to invoke each module and ensure its input/output are prepared.`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// load the config file given, or the default file if there is one
+			if cfgPath, err := fs.GetString("config"); err != nil {
+				return err
+			} else if cfgPath = strings.TrimSpace(cfgPath); cfgPath != "" {
+				if err := applyConfigFile(cmd.Flags(), cfgPath, true); err != nil {
+					return err
+				}
+			} else if cfgPath, err := defaultConfigFile(); err == nil {
+				if err := applyConfigFile(cmd.Flags(), cfgPath, false); err != nil {
+					return err
+				}
+			}
+			// select log format
+			if jl, err := fs.GetBool("json-logs"); err != nil {
+				return err
+			} else if jl {
+				log = newLogger(true)
+			}
 			// set log level
 			ll, err := fs.GetString("log-level")
 			if err != nil {
@@ -94,11 +160,15 @@ Because Omen is a set of disparate modules run in sequence, this binary (the Coo
 	}
 	// attach flags
 	root.Flags().AddFlagSet(&fs)
+	root.Flags().AddFlagSet(&remoteFs)
+	omen.AttachJSONVersion(root)
+	root.AddCommand(newDoctorCommand(&remoteFs))
 
 	// NOTE(rlandau): because of how cobra works, the actual main function is a stub. run() is the real "main" function
 	if err := fang.Execute(context.Background(), root,
 		fang.WithoutCompletions(),
 		fang.WithVersion(omen.Version),
+		fang.WithCommit(omen.Commit),
 		fang.WithErrorHandler(omen.FangErrorHandler)); err != nil {
 		// fang logs returned errors for us
 		os.Exit(1)
@@ -107,8 +177,10 @@ Because Omen is a set of disparate modules run in sequence, this binary (the Coo
 
 // cleanup shutters the docker containers it spun up if the pipeline failed.
 // Otherwise, leaves a message about still-spinning containers.
+// Releases the lock on the working directory.
 func cleanup(errored bool) {
 	defer dCLI.Close()
+	defer releaseLock()
 	if errored && grafanaContainerID != "" { // force-shutter the grafana container
 		if err := dCLI.ContainerRemove(context.Background(), grafanaContainerID, container.RemoveOptions{Force: true}); err != nil {
 			log.Error().Err(err).Msg("failed to force-remove the Grafana container")
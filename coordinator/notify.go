@@ -0,0 +1,109 @@
+package main
+
+// This file implements --notify-url, which reports the outcome of the pipeline to a webhook.
+
+import (
+	omen "Omen"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+)
+
+// notifyTimeout bounds how long we wait on the webhook, so an unresponsive endpoint cannot hold the coordinator open.
+const notifyTimeout = 10 * time.Second
+
+// Values of notification.Status.
+const (
+	notifySucceeded string = "succeeded"
+	notifyFailed    string = "failed"
+)
+
+// notification is the JSON payload POSTed to --notify-url on completion of the pipeline.
+type notification struct {
+	// Text is a human-readable summary of the remaining fields.
+	// Slack (and compatible) incoming webhooks display it as the message.
+	Text       string  `json:"text"`
+	Status     string  `json:"status"`                // notifySucceeded or notifyFailed
+	Input      string  `json:"input"`                 // absolute path to the input file
+	StartedAt  string  `json:"started_at"`            // RFC3339
+	DurationS  float64 `json:"duration_s"`            // seconds the pipeline ran for
+	GrafanaURL string  `json:"grafana_url,omitempty"` // set on success
+	Error      string  `json:"error,omitempty"`       // set on failure
+	RunID      string  `json:"run_id,omitempty"`      // set if --merge
+	Version    string  `json:"version"`               // omen.Version of the coordinator
+}
+
+// parseNotifyURL validates the --notify-url flag's value.
+// An empty raw disables notifications.
+func parseNotifyURL(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("--notify-url: %w", err)
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("--notify-url must be an absolute http(s) URL, not %q", raw)
+	}
+	return u, nil
+}
+
+// newNotification composes the payload reporting a run of the pipeline against inputPath, started at start, that returned pipelineErr.
+func newNotification(inputPath, runID, grafanaPort string, start time.Time, pipelineErr error) notification {
+	if abs, err := filepath.Abs(inputPath); err == nil {
+		inputPath = abs
+	}
+	duration := time.Since(start)
+	n := notification{
+		Input:     inputPath,
+		StartedAt: start.UTC().Format(time.RFC3339),
+		DurationS: duration.Round(time.Millisecond).Seconds(),
+		RunID:     runID,
+		Version:   omen.Version,
+	}
+	if pipelineErr != nil {
+		n.Status = notifyFailed
+		n.Error = pipelineErr.Error()
+		n.Text = fmt.Sprintf("%s run of %s failed after %v: %v", appName, filepath.Base(inputPath), duration.Round(time.Second), pipelineErr)
+	} else {
+		n.Status = notifySucceeded
+		n.GrafanaURL = "http://localhost:" + grafanaPort
+		n.Text = fmt.Sprintf("%s run of %s succeeded after %v. Results are available @ %s", appName, filepath.Base(inputPath), duration.Round(time.Second), n.GrafanaURL)
+	}
+	return n
+}
+
+// sendNotification POSTs n to the webhook at u.
+// Notifications are best-effort: failures are logged rather than returned, so they never fail the run.
+func sendNotification(u *url.URL, n notification) {
+	body, err := json.Marshal(n)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to marshal notification")
+		return
+	}
+	// the pipeline's context may already be done (ex: --max-runtime), so do not derive from it
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to compose notification request")
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Warn().Err(err).Str("host", u.Host).Msg("failed to send notification")
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		log.Warn().Int("status", resp.StatusCode).Str("host", u.Host).Msg("webhook rejected notification")
+		return
+	}
+	log.Info().Str("host", u.Host).Str("status", n.Status).Msg("sent notification")
+}
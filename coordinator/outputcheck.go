@@ -0,0 +1,138 @@
+package main
+
+// This file implements --validate-output, which checks the coalesced CSVs are well-formed before they are loaded.
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// pipelineStepValidateOutput names the step that checks the coalesced output (if --validate-output).
+const pipelineStepValidateOutput string = "validate-output"
+
+// outputSampleRows is the number of rows (after the header) checked in each CSV.
+const outputSampleRows int = 1000
+
+// ErrMalformedOutput is returned when a CSV produced by the coalesce output module does not match its expected schema.
+var ErrMalformedOutput = errors.New("malformed coalesced output")
+
+// outputSchema describes a CSV produced by the coalesce output module (see MODULE_CONTRACTS.md).
+type outputSchema struct {
+	header   []string // expected header, in order
+	required []string // columns that cannot be empty
+}
+
+// Schemas of the CSVs the loader consumes.
+var (
+	pingSchema = outputSchema{
+		header: []string{
+			"data_type", "movement_number", "test_file", "node_name", "position",
+			"src", "dst", "tx", "rx", "loss_pct", "avg_rtt_ms", "timestamp",
+		},
+		required: []string{"data_type", "movement_number", "src", "dst"},
+	}
+	nodesSchema = outputSchema{
+		header:   []string{"id", "title", "position", "rx_bytes", "rx_packets", "tx_bytes", "tx_packets", "success_pct_rate"},
+		required: []string{"id"},
+	}
+	edgesSchema = outputSchema{
+		header:   []string{"id", "source", "target"},
+		required: []string{"id", "source", "target"},
+	}
+)
+
+func init() {
+	registerStepBefore(pipelineStepLoad, pipelineStepValidateOutput, validateOutputStep)
+}
+
+// validateOutputStep checks each CSV the loader consumes from resultsDir against its schema, if --validate-output.
+func validateOutputStep(_ context.Context, st *runState) error {
+	if !st.validateOutput {
+		return nil
+	}
+	files, err := expectedOutputs(resultsDir)
+	if err != nil {
+		return err
+	}
+	for _, pth := range slices.Sorted(maps.Keys(files)) {
+		if err := checkOutputCSV(pth, files[pth]); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrMalformedOutput, pth, err)
+		}
+	}
+	log.Info().Int("files", len(files)).Str("results", resultsDir).Msg("validated coalesced output")
+	return nil
+}
+
+// expectedOutputs returns the CSVs under dir the loader consumes, mapped to their schemas:
+// ping_data.csv and, per timeframe directory, nodes.csv, edges.csv, and any ping_data_movement_*.csv.
+func expectedOutputs(dir string) (map[string]outputSchema, error) {
+	files := map[string]outputSchema{filepath.Join(dir, "ping_data.csv"): pingSchema}
+	timeframes, err := filepath.Glob(filepath.Join(dir, "timeframe*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, tf := range timeframes {
+		if inf, err := os.Stat(tf); err != nil || !inf.IsDir() {
+			continue
+		}
+		files[filepath.Join(tf, "nodes.csv")] = nodesSchema
+		files[filepath.Join(tf, "edges.csv")] = edgesSchema
+		movements, err := filepath.Glob(filepath.Join(tf, "ping_data_movement_*.csv"))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range movements {
+			files[m] = pingSchema
+		}
+	}
+	return files, nil
+}
+
+// checkOutputCSV confirms the header of the CSV at pth matches schema and that the first outputSampleRows rows
+// have as many fields as the header and no empty required fields.
+func checkOutputCSV(pth string, schema outputSchema) error {
+	f, err := os.Open(pth)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f) // fields per record defaults to the header's count
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return errors.New("file is empty (expected a header)")
+	} else if err != nil {
+		return err
+	}
+	if !slices.Equal(header, schema.header) {
+		return fmt.Errorf("header (%s) does not match the expected header (%s)", strings.Join(header, ","), strings.Join(schema.header, ","))
+	}
+	required := make(map[int]string, len(schema.required))
+	for _, col := range schema.required {
+		required[slices.Index(header, col)] = col
+	}
+
+	for range outputSampleRows {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err // a *csv.ParseError, which reports the line
+		}
+		for i, col := range required {
+			if strings.TrimSpace(record[i]) == "" {
+				line, _ := r.FieldPos(i)
+				return fmt.Errorf("line %d: required column %s is empty", line, col)
+			}
+		}
+	}
+	return nil
+}
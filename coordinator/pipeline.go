@@ -0,0 +1,88 @@
+package main
+
+// This file implements the step registry executePipeline iterates.
+// The built-in steps (implemented in run.go) seed the registry; additional steps (ex: pre-processing or notifications) are registered at init, relative to them.
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Names of the built-in pipeline steps, in the order they execute.
+const (
+	pipelineStepValidate string = "validate" // validates the input file
+	pipelineStepSpawn    string = "spawn"    // executes the topology tests against each validated file
+	pipelineStepCoalesce string = "coalesce" // coalesces the raw results of each test run
+	pipelineStepLoad     string = "load"     // loads the coalesced results into the database
+	pipelineStepGrafana  string = "grafana"  // spins up the visualization container
+)
+
+// runState is threaded through each step of a single pipeline execution.
+// Steps read the fields set by prior steps and set those later steps rely on.
+type runState struct {
+	exe       *stepExecutor
+	inputPath string
+	dbPath    string
+	gOpts     grafanaOptions
+
+	validateOutput bool          // check the coalesced output prior to loading it
+	timeouts       stageTimeouts // bounds on each stage's subprocess
+
+	validated []string // input files that passed validation; set by the validate step
+	rawDirs   []string // raw results directory of each validated file, in the same order; set by the spawn step
+}
+
+// stageTimeouts bounds how long the subprocess of each stage may run.
+// Zero durations leave the stage bounded only by --max-runtime.
+type stageTimeouts struct {
+	testRunner time.Duration // per validated file
+	coalesce   time.Duration // per raw results directory
+	loader     time.Duration // per loader invocation
+}
+
+// stepFunc executes a single step of the pipeline.
+// Returning an error stops the pipeline.
+type stepFunc func(ctx context.Context, st *runState) error
+
+// pipelineStep is a named step of the pipeline.
+type pipelineStep struct {
+	name string
+	run  stepFunc
+}
+
+// pipeline is the ordered set of registered steps.
+// The built-in steps are registered during variable initialization, so they precede every init regardless of file order.
+var pipeline = []pipelineStep{
+	{pipelineStepValidate, validateStep},
+	{pipelineStepSpawn, spawnStep},
+	{pipelineStepCoalesce, coalesceStep},
+	{pipelineStepLoad, loadStep},
+	{pipelineStepGrafana, grafanaStep},
+}
+
+// registerStepBefore inserts a step into the pipeline immediately prior to the step named before.
+// Panics if before is not registered or a step of the same name is, as registration occurs at init.
+func registerStepBefore(before, name string, run stepFunc) {
+	insertStep(stepIndex(before), name, run)
+}
+
+// stepIndex returns the position of the named step in the pipeline.
+// Panics if it is not registered.
+func stepIndex(name string) int {
+	i := slices.IndexFunc(pipeline, func(s pipelineStep) bool { return s.name == name })
+	if i < 0 {
+		panic(fmt.Sprintf("pipeline step %q is not registered", name))
+	}
+	return i
+}
+
+func insertStep(at int, name string, run stepFunc) {
+	if slices.ContainsFunc(pipeline, func(s pipelineStep) bool { return s.name == name }) {
+		panic(fmt.Sprintf("pipeline step %q is already registered", name))
+	} else if run == nil {
+		panic(fmt.Sprintf("pipeline step %q has no function", name))
+	}
+	pipeline = slices.Insert(pipeline, at, pipelineStep{name: name, run: run})
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"os/exec"
 	"path"
@@ -15,6 +17,7 @@ import (
 	"time"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/go-connections/nat"
 	"github.com/spf13/cobra"
@@ -25,18 +28,62 @@ const (
 	testRunnerStderrLog     string = "test_runner.err.log"
 	coalesceOutputStdoutLog string = "coalesce_output.out.log"
 	coalesceOutputStderrLog string = "coalesce_output.err.log"
+	loaderStdoutLog         string = "loader.out.log"
+	loaderStderrLog         string = "loader.err.log"
+	resultsDir              string = "./results" // where the coalesce output module writes the CSVs the loader consumes
 )
 
 // ErrNoFilesValidated returns an error as it says on the tin
 var ErrNoFilesValidated = errors.New("no files passed validation")
 
+// ErrMaxRuntimeExceeded is returned when the pipeline does not complete within --max-runtime.
+var ErrMaxRuntimeExceeded = errors.New("pipeline exceeded its maximum runtime")
+
+// ErrStageTimeout is returned when a stage's subprocess does not complete within its --*-timeout.
+var ErrStageTimeout = errors.New("stage timed out")
+
+// ErrDatabaseLocked is returned when the loader cannot write to the database because another process holds it.
+var ErrDatabaseLocked = errors.New("database is locked")
+
+// ErrPortInUse is returned when the port Grafana should bind to is already bound.
+var ErrPortInUse = errors.New("port is in use")
+
+// Labels attached to the Grafana container, so concurrent runs can be told apart (ex: docker ps --filter label=omen.input=...).
+const (
+	labelInput   string = "omen.input"   // absolute path to the input file the run was executed against
+	labelVersion string = "omen.version" // omen.Version of the coordinator
+	labelCreated string = "omen.created" // RFC3339 time the container was created at
+	labelDB      string = "omen.db"      // absolute path to the database mounted into the container
+)
+
+// grafanaOptions configures the Grafana visualization container.
+type grafanaOptions struct {
+	port       string // host port to bind the container to
+	dbTarget   string // path within the container the database is mounted at
+	dbReadOnly bool   // mount the database read-only
+}
+
 // run is the primary driver function.
 // It is responsible for preparing all information, driving the pipeline, and managing docker containers.
 func run(cmd *cobra.Command, args []string) error {
 	var (
-		grafanaPortStr           string
+		gOpts                    grafanaOptions
 		testRunnerBinaryPath     string
 		coalesceOutputBinaryPath string
+		workingDir               string
+		dbPath                   string
+		maxRuntime               time.Duration
+		merge                    bool
+		assumeYes                bool
+		local                    bool
+		jump                     string
+		identity                 string
+		repetitions              uint
+		validateOutput           bool
+		timeouts                 stageTimeouts
+		notifyURL                *url.URL
+		loaderScriptPath         = DefaultLoaderScriptPath
+		driverScriptPath         string // left empty for the test runner to find its driver script in its working directory
 	)
 	// consume flags
 	{
@@ -44,7 +91,13 @@ func run(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
-		grafanaPortStr = strconv.FormatUint(uint64(grafanaPort), 10)
+		gOpts.port = strconv.FormatUint(uint64(grafanaPort), 10)
+		if gOpts.dbTarget, err = cmd.Flags().GetString("grafana-db-target"); err != nil {
+			return err
+		}
+		if gOpts.dbReadOnly, err = cmd.Flags().GetBool("grafana-db-read-only"); err != nil {
+			return err
+		}
 
 		if testRunnerBinaryPath, err = cmd.Flags().GetString("test-runner"); err != nil {
 			return err
@@ -52,7 +105,66 @@ func run(cmd *cobra.Command, args []string) error {
 		if coalesceOutputBinaryPath, err = cmd.Flags().GetString("coalesce-output"); err != nil {
 			return err
 		}
-
+		if workingDir, err = cmd.Flags().GetString("working-dir"); err != nil {
+			return err
+		}
+		if dbPath, err = cmd.Flags().GetString("db"); err != nil {
+			return err
+		} else if dbPath = strings.TrimSpace(dbPath); dbPath == "" {
+			return errors.New("--db cannot be empty")
+		}
+		if maxRuntime, err = cmd.Flags().GetDuration("max-runtime"); err != nil {
+			return err
+		} else if maxRuntime < 0 {
+			return errors.New("--max-runtime cannot be negative")
+		}
+		if merge, err = cmd.Flags().GetBool("merge"); err != nil {
+			return err
+		}
+		if validateOutput, err = cmd.Flags().GetBool("validate-output"); err != nil {
+			return err
+		}
+		if assumeYes, err = cmd.Flags().GetBool("assume-yes"); err != nil {
+			return err
+		}
+		if local, err = cmd.Flags().GetBool("local"); err != nil {
+			return err
+		}
+		if jump, err = cmd.Flags().GetString("jump"); err != nil {
+			return err
+		} else if jump = strings.TrimSpace(jump); jump != "" && local {
+			return errors.New("--jump cannot be combined with --local")
+		}
+		if identity, err = cmd.Flags().GetString("identity"); err != nil {
+			return err
+		} else if identity = strings.TrimSpace(identity); identity != "" && local {
+			return errors.New("--identity cannot be combined with --local")
+		}
+		if repetitions, err = cmd.Flags().GetUint("repetitions"); err != nil {
+			return err
+		} else if repetitions == 0 {
+			return errors.New("--repetitions must be at least 1")
+		}
+		for flag, timeout := range map[string]*time.Duration{
+			"test-runner-timeout": &timeouts.testRunner,
+			"coalesce-timeout":    &timeouts.coalesce,
+			"loader-timeout":      &timeouts.loader,
+		} {
+			if *timeout, err = cmd.Flags().GetDuration(flag); err != nil {
+				return err
+			} else if *timeout < 0 {
+				return fmt.Errorf("--%s cannot be negative", flag)
+			}
+		}
+		if raw, err := cmd.Flags().GetString("notify-url"); err != nil {
+			return err
+		} else if notifyURL, err = parseNotifyURL(strings.TrimSpace(raw)); err != nil {
+			return err
+		}
+	}
+	// check the port up front so we do not discover it is taken after the tests have run
+	if err := checkPortAvailable(gOpts.port); err != nil {
+		return err
 	}
 	// validate input file
 	inputPath := strings.TrimSpace(args[0])
@@ -64,142 +176,290 @@ func run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("input json cannot be a directory")
 	}
 
-	err := executePipeline(inputPath, testRunnerBinaryPath, coalesceOutputBinaryPath, grafanaPortStr)
+	if workingDir = strings.TrimSpace(workingDir); workingDir != "" {
+		// paths given relative to the original directory must be resolved prior to changing directories
+		var err error
+		if inputPath, err = filepath.Abs(inputPath); err != nil {
+			return err
+		}
+		if testRunnerBinaryPath, err = absBinaryPath(testRunnerBinaryPath); err != nil {
+			return err
+		}
+		if coalesceOutputBinaryPath, err = absBinaryPath(coalesceOutputBinaryPath); err != nil {
+			return err
+		}
+		if loaderScriptPath, err = filepath.Abs(loaderScriptPath); err != nil {
+			return err
+		}
+		if identity != "" {
+			if identity, err = filepath.Abs(identity); err != nil {
+				return err
+			}
+		}
+		// the test runner looks for its driver script in its working directory by default,
+		// so point it at the one beside us (if there is one) before we leave
+		if _, err := os.Stat(DefaultDriverScriptPath); err == nil {
+			if driverScriptPath, err = filepath.Abs(DefaultDriverScriptPath); err != nil {
+				return err
+			}
+		}
+		if err := enterWorkingDir(workingDir); err != nil {
+			return err
+		}
+	}
+
+	// concurrent coordinators in the same directory would clobber each other's database, results, and logs
+	if err := acquireLock(); err != nil {
+		return err
+	}
+
+	exe := &stepExecutor{
+		testRunnerBinaryPath:     testRunnerBinaryPath,
+		coalesceOutputBinaryPath: coalesceOutputBinaryPath,
+		loaderScriptPath:         loaderScriptPath,
+		driverScriptPath:         driverScriptPath,
+		assumeYes:                assumeYes,
+		local:                    local,
+		jump:                     jump,
+		identity:                 identity,
+		repetitions:              repetitions,
+	}
+	if merge {
+		exe.runID, exe.runTS = newRunID(inputPath, time.Now())
+		log.Info().Str("run ID", exe.runID).Str("database", dbPath).Msg("merging this run into the database")
+	}
+
+	ctx := cmd.Context()
+	if maxRuntime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, maxRuntime)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := executePipeline(ctx, &runState{exe: exe, inputPath: inputPath, dbPath: dbPath, gOpts: gOpts, validateOutput: validateOutput, timeouts: timeouts})
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// in-flight steps were killed; report the budget rather than whatever error the killed step returned
+		log.Debug().Err(err).Msg("pipeline error after deadline")
+		err = fmt.Errorf("%w (%v). In-flight steps were cancelled", ErrMaxRuntimeExceeded, maxRuntime)
+	}
+	if notifyURL != nil {
+		sendNotification(notifyURL, newNotification(inputPath, exe.runID, gOpts.port, start, err))
+	}
 	if err == nil {
-		fmt.Println("Results are available @ localhost:" + grafanaPortStr)
+		fmt.Println("Results are available @ localhost:" + gOpts.port)
 	}
 	cleanup(err != nil)
 	return err
 }
 
-func executePipeline(inputPath, testRunnerBinaryPath, coalesceOutputBinaryPath, grafanaPortStr string) error {
-	paths, err := runInputValidationModule([]string{inputPath})
+// enterWorkingDir changes the current directory to dir, creating it if it does not exist.
+func enterWorkingDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create working directory %s: %w", dir, err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("failed to enter working directory %s: %w", dir, err)
+	}
+	log.Info().Str("working directory", dir).Msg("entered working directory")
+	return nil
+}
+
+// absBinaryPath returns the absolute path to the binary at pth.
+// Bare names (no path separator) are returned as-is so they continue to be looked up in PATH.
+func absBinaryPath(pth string) (string, error) {
+	if !strings.ContainsRune(pth, filepath.Separator) {
+		return pth, nil
+	}
+	return filepath.Abs(pth)
+}
+
+// newRunID returns the ID and (RFC3339) timestamp to stamp the rows of a merged run with.
+// The ID is composed from the input file's name and the start time, so runs of the same topology remain distinguishable.
+func newRunID(inputPath string, start time.Time) (id, ts string) {
+	start = start.UTC()
+	stem := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	return stem + "_" + start.Format(omen.RunDirNameFormat), start.Format(time.RFC3339)
+}
+
+// executePipeline runs each registered step of the pipeline against the file at st.inputPath, loading the results into the database at st.dbPath,
+// then spins up the visualization container.
+// Dies on the first step to fail.
+// Steps in flight are killed if ctx is done.
+func executePipeline(ctx context.Context, st *runState) error {
+	for _, step := range pipeline {
+		log.Debug().Str("step", step.name).Msg("executing pipeline step")
+		if err := step.run(ctx, st); err != nil {
+			log.Debug().Err(err).Str("step", step.name).Msg("pipeline step failed")
+			return err
+		}
+	}
+	return nil
+}
+
+// validateStep validates the input file, recording those that passed.
+func validateStep(ctx context.Context, st *runState) error {
+	paths, err := runInputValidationModule(ctx, st.exe, []string{st.inputPath})
 	if err != nil {
 		return err
 	}
-
 	// NOTE(rlandau): as we only accept a single file atn, `paths` should be at most 1 element
-	// Further, dies on first error
 	for _, path := range paths {
 		log.Info().Str("path", path).Msg("validated file")
+	}
+	st.validated = paths
+	return nil
+}
 
-		var sbOut, sbErr strings.Builder
-
-		// execute the test runner module
-		log.Info().Str("path", path).Msg("executing topology tests")
-		cmd := exec.Command(testRunnerBinaryPath, "--interactive=false", path)
-		log.Debug().Str("path", cmd.Path).Strs("args", cmd.Args).Msg("executing test runner binary")
-		cmd.Stdout = &sbOut
-		cmd.Stderr = &sbErr
-		result := make(chan error)
-		go func() {
-			if err := cmd.Run(); err != nil {
-				log.Error().Err(err).Str("path", cmd.Path).Msg("failed to run test runner binary")
-				// write the binary's outputs to files
-				if err := os.WriteFile(testRunnerStdoutLog, []byte(sbOut.String()), 0644); err != nil {
-					log.Error().Err(err).Msgf("failed to write %v's stdout to %v", cmd.Path, testRunnerStdoutLog)
-				}
-				if err := os.WriteFile(testRunnerStderrLog, []byte(sbErr.String()), 0644); err != nil {
-					log.Error().Err(err).Msgf("failed to write %v's stderr to %v", cmd.Path, testRunnerStderrLog)
-				}
-				result <- fmt.Errorf("failed to run test runner binary (%s): %w.\nSee '%v' and `%v` for details", cmd.Path, err, testRunnerStdoutLog, testRunnerStderrLog)
-				return
-			}
-			log.Debug().Msg("finished processing successfully")
-			result <- nil
-		}()
+// spawnStep executes the test runner module against each validated file, recording the raw results directory it reports.
+func spawnStep(ctx context.Context, st *runState) error {
+	for _, path := range st.validated {
+		rawDir, err := runTestRunner(ctx, st, path)
+		if err != nil {
+			return err
+		}
+		st.rawDirs = append(st.rawDirs, rawDir)
+	}
+	return nil
+}
+
+// runTestRunner executes the test runner module against the validated file at path, returning the raw results directory it reports.
+// The test runner is killed if it has not completed within --test-runner-timeout.
+func runTestRunner(ctx context.Context, st *runState, path string) (rawDir string, _ error) {
+	var sbOut, sbErr strings.Builder
 
-		if err := waitDisplay(result, 5); err != nil {
+	// execute the test runner module
+	log.Info().Str("path", path).Msg("executing topology tests")
+	stageCtx, cancel := withStageTimeout(ctx, st.timeouts.testRunner)
+	defer cancel()
+	cmd, err := st.exe.command(stageCtx, StepTestRunner, path)
+	if err != nil {
+		return "", err
+	}
+	cmd.Stdout = &sbOut
+	cmd.Stderr = &sbErr
+	result := make(chan error)
+	go func() {
+		result <- cmd.Run()
+	}()
+
+	if err := waitDisplay(result, 5); err != nil {
+		log.Error().Err(err).Str("path", cmd.Path).Msg("failed to run test runner binary")
+		writeStageLogs(cmd, sbOut.String(), sbErr.String(), testRunnerStdoutLog, testRunnerStderrLog)
+		if timedOut(ctx, stageCtx) {
+			return "", fmt.Errorf("%w: test runner did not complete within %v (--test-runner-timeout).\nSee '%v' and `%v` for its output",
+				ErrStageTimeout, st.timeouts.testRunner, testRunnerStdoutLog, testRunnerStderrLog)
+		}
+		return "", fmt.Errorf("failed to run test runner binary (%s): %w.\nSee '%v' and `%v` for details", cmd.Path, err, testRunnerStdoutLog, testRunnerStderrLog)
+	}
+	log.Debug().Msg("finished processing successfully")
+	return resultsDirFromOutput(sbOut.String()), nil
+}
+
+// coalesceStep executes the coalesce output module against each raw results directory.
+func coalesceStep(ctx context.Context, st *runState) error {
+	for i, rawDir := range st.rawDirs {
+		if err := runCoalesceOutput(ctx, st, st.validated[i], rawDir); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		sbOut.Reset()
-		sbErr.Reset()
-
-		// execute coalesce output module
-		log.Info().Str("path", path).Msg("coalescing raw test output")
-		cmd = exec.Command(coalesceOutputBinaryPath, "mn_result_raw/")
-		log.Debug().Str("path", cmd.Path).Strs("args", cmd.Args).Msg("executing coalesce output binary")
-		cmd.Stdout = &sbOut
-		cmd.Stderr = &sbErr
-		if err := cmd.Run(); err != nil {
-			log.Error().Err(err).Str("path", cmd.Path).Msg("failed to run coalesce output binary")
-			// write the binary's outputs to files
-			if err := os.WriteFile(coalesceOutputStdoutLog, []byte(sbOut.String()), 0644); err != nil {
-				log.Error().Err(err).Msgf("failed to write %v's stdout to %v", cmd.Path, coalesceOutputStdoutLog)
-			}
-			if err := os.WriteFile(coalesceOutputStderrLog, []byte(sbErr.String()), 0644); err != nil {
-				log.Error().Err(err).Msgf("failed to write %v's stderr to %v", cmd.Path, coalesceOutputStderrLog)
-			}
-			return fmt.Errorf("failed to run coalesce output binary (%s): %w.\nSee '%v' and `%v` for details", cmd.Path, err, coalesceOutputStdoutLog, coalesceOutputStderrLog)
+// runCoalesceOutput executes the coalesce output module against the raw results (of the file at path) in rawDir.
+// The module is killed if it has not completed within --coalesce-timeout.
+func runCoalesceOutput(ctx context.Context, st *runState, path, rawDir string) error {
+	var sbOut, sbErr strings.Builder
+
+	// execute coalesce output module
+	log.Info().Str("path", path).Str("raw results", rawDir).Msg("coalescing raw test output")
+	stageCtx, cancel := withStageTimeout(ctx, st.timeouts.coalesce)
+	defer cancel()
+	cmd, err := st.exe.command(stageCtx, StepCoalesceOutput, rawDir)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = &sbOut
+	cmd.Stderr = &sbErr
+	if err := cmd.Run(); err != nil {
+		log.Error().Err(err).Str("path", cmd.Path).Msg("failed to run coalesce output binary")
+		writeStageLogs(cmd, sbOut.String(), sbErr.String(), coalesceOutputStdoutLog, coalesceOutputStderrLog)
+		if timedOut(ctx, stageCtx) {
+			return fmt.Errorf("%w: coalesce output did not complete within %v (--coalesce-timeout).\nSee '%v' and `%v` for its output",
+				ErrStageTimeout, st.timeouts.coalesce, coalesceOutputStdoutLog, coalesceOutputStderrLog)
 		}
+		return fmt.Errorf("failed to run coalesce output binary (%s): %w.\nSee '%v' and `%v` for details", cmd.Path, err, coalesceOutputStdoutLog, coalesceOutputStderrLog)
 	}
+	return nil
+}
 
-	var sbErr strings.Builder
-	// generate the database
-	{
-		cmd := exec.Command("python3", DefaultLoaderScriptPath, "graph",
-			"--db", "omen.db",
-			"--recreate",
-			"--root", "./results",
-			"--set1-prefix", "netA", "--set1-dir", "timeframe0", "--set1-ts", "timeframe0/ping_data_movement_0.csv",
-			"--set2-prefix", "netB", "--set2-dir", "timeframe1", "--set2-ts", "timeframe1/ping_data_movement_1.csv",
-			"--set3-prefix", "netC", "--set3-dir", "timeframe2", "--set3-ts", "timeframe2/ping_data_movement_2.csv",
-		)
-		log.Debug().Strs("args", cmd.Args).Msg("executing visualization loader binary (graph)")
-		cmd.Stderr = &sbErr
-		if _, err := cmd.Output(); err != nil {
-			log.Error().Err(err).Msg("failed to run visualization loader module (graph)")
-			return errors.New(sbErr.String())
-		}
-	}
-	sbErr.Reset()
-	const dbOut string = "omen.db"
-	{
-		cmd := exec.Command("python3", DefaultLoaderScriptPath, "timeseries",
-			"--root", "./results",
-			"--csv", "ping_data.csv",
-			"--db", dbOut,
-			"--table", "ping_data",
-			"--if-exists", "replace",
-			"--aggregate-by", "movement_number",
-		)
-		log.Debug().Strs("args", cmd.Args).Msg("executing visualization loader binary (graph)")
-		cmd.Stderr = &sbErr
-		if _, err := cmd.Output(); err != nil {
-			log.Error().Err(err).Msg("failed to run visualization loader module (graph)")
-			return errors.New(sbErr.String())
-		}
-	}
-	sbErr.Reset()
+// loadStep generates the database from the coalesced results.
+func loadStep(ctx context.Context, st *runState) error {
+	for _, step := range []ModuleStep{StepLoaderGraph, StepLoaderTimeseries} {
+		err := runLoaderStep(ctx, st.exe, step, st.dbPath, st.timeouts.loader)
+		if errors.Is(err, ErrDatabaseLocked) {
+			// most likely, a Grafana container from a prior run still has the database open
+			log.Warn().Str("database", st.dbPath).Msg("database is locked; removing prior Grafana containers and retrying")
+			if n, rmErr := removeGrafanaContainers(ctx, st.dbPath); rmErr != nil {
+				log.Error().Err(rmErr).Msg("failed to remove prior Grafana containers")
+			} else if n > 0 {
+				err = runLoaderStep(ctx, st.exe, step, st.dbPath, st.timeouts.loader)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// grafanaStep boots the visualization container, mounting the database into it.
+func grafanaStep(ctx context.Context, st *runState) error {
+	gOpts := st.gOpts
 	// because host mounts must be absolute, we need to get the full path to the local file first
-	abspth, err := filepath.Abs("omen.db")
+	abspth, err := filepath.Abs(st.dbPath)
+	if err != nil {
+		return err
+	}
+
+	absInput, err := filepath.Abs(st.inputPath)
 	if err != nil {
 		return err
 	}
 
+	// re-check the port as it may have been taken while the pipeline was executing
+	if err := checkPortAvailable(gOpts.port); err != nil {
+		return err
+	}
+
 	// boot visualization container
-	cr, err := dCLI.ContainerCreate(context.TODO(),
+	cr, err := dCLI.ContainerCreate(ctx,
 		&container.Config{
 			ExposedPorts: nat.PortSet{nat.Port("3000/tcp"): struct{}{}},
 			Image:        omen.VisualizationGrafanaImage,
+			Labels: map[string]string{
+				labelInput:   absInput,
+				labelVersion: omen.Version,
+				labelCreated: time.Now().UTC().Format(time.RFC3339),
+				labelDB:      abspth,
+			},
 		},
 		&container.HostConfig{
 			PortBindings: nat.PortMap{
-				nat.Port("3000/tcp"): []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: grafanaPortStr}},
+				nat.Port("3000/tcp"): []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: gOpts.port}},
 			},
 			Mounts: []mount.Mount{
 				{
-					Type:   mount.TypeBind,
-					Source: abspth,
-					Target: "/var/lib/grafana/data.db",
+					Type:     mount.TypeBind,
+					Source:   abspth,
+					Target:   gOpts.dbTarget,
+					ReadOnly: gOpts.dbReadOnly,
 				},
 			},
 		},
 		nil,
 		nil,
-		"OmenVizGrafana_p"+grafanaPortStr)
+		"OmenVizGrafana_p"+gOpts.port)
 	if err != nil {
 		return fmt.Errorf("failed to create grafana container: %w", err)
 	}
@@ -209,7 +469,7 @@ func executePipeline(inputPath, testRunnerBinaryPath, coalesceOutputBinaryPath,
 		log.Info().Str("container ID", cr.ID).Msg("created grafana container")
 	}
 
-	if err := dCLI.ContainerStart(context.Background(), cr.ID, container.StartOptions{}); err != nil {
+	if err := dCLI.ContainerStart(ctx, cr.ID, container.StartOptions{}); err != nil {
 		return fmt.Errorf("failed to spin up grafana container: %w", err)
 	}
 	grafanaContainerID = cr.ID
@@ -217,6 +477,114 @@ func executePipeline(inputPath, testRunnerBinaryPath, coalesceOutputBinaryPath,
 	return nil
 }
 
+// resultsDirFromOutput returns the raw results directory reported in the test runner's stdout.
+// If the test runner did not report one, falls back to the directory of all raw results (from which the coalesce output module picks the latest).
+func resultsDirFromOutput(stdout string) string {
+	const fallback string = "mn_result_raw/"
+	var dir string
+	for line := range strings.Lines(stdout) {
+		if d, found := strings.CutPrefix(strings.TrimSpace(line), omen.ResultsDirPrefix); found {
+			dir = d // the last report wins
+		}
+	}
+	if dir == "" {
+		log.Warn().Str("fallback", fallback).Msg("test runner did not report a results directory")
+		return fallback
+	}
+	return dir
+}
+
+// runLoaderStep executes the given loader step against the database at dbPath, killing it if it has not completed within timeout (if positive).
+// Returns ErrDatabaseLocked if the loader could not write to the database because it is in use.
+func runLoaderStep(ctx context.Context, exe *stepExecutor, step ModuleStep, dbPath string, timeout time.Duration) error {
+	stageCtx, cancel := withStageTimeout(ctx, timeout)
+	defer cancel()
+	cmd, err := exe.command(stageCtx, step, dbPath, resultsDir)
+	if err != nil {
+		return err
+	}
+	var sbErr strings.Builder
+	cmd.Stderr = &sbErr
+	if out, err := cmd.Output(); err != nil {
+		log.Error().Err(err).Msgf("failed to run %v module", step)
+		if timedOut(ctx, stageCtx) {
+			writeStageLogs(cmd, string(out), sbErr.String(), loaderStdoutLog, loaderStderrLog)
+			return fmt.Errorf("%w: %v did not complete within %v (--loader-timeout).\nSee '%v' and `%v` for its output",
+				ErrStageTimeout, step, timeout, loaderStdoutLog, loaderStderrLog)
+		}
+		if strings.Contains(sbErr.String(), "database is locked") {
+			return fmt.Errorf("%w: %s is in use (is a prior Grafana container still running?). "+
+				"Remove it with `docker rm -f <container ID>`", ErrDatabaseLocked, dbPath)
+		}
+		return errors.New(sbErr.String())
+	}
+	return nil
+}
+
+// withStageTimeout returns a copy of ctx that is also done once timeout elapses.
+// A non-positive timeout leaves the stage bounded only by ctx.
+func withStageTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timedOut reports whether stageCtx (derived by withStageTimeout) hit its own deadline, rather than being cancelled along with its parent, ctx.
+func timedOut(ctx, stageCtx context.Context) bool {
+	return ctx.Err() == nil && errors.Is(stageCtx.Err(), context.DeadlineExceeded)
+}
+
+// writeStageLogs writes the captured stdout and stderr of a failed stage's cmd to the files at stdoutLog and stderrLog.
+func writeStageLogs(cmd *exec.Cmd, stdout, stderr, stdoutLog, stderrLog string) {
+	if err := os.WriteFile(stdoutLog, []byte(stdout), 0644); err != nil {
+		log.Error().Err(err).Msgf("failed to write %v's stdout to %v", cmd.Path, stdoutLog)
+	}
+	if err := os.WriteFile(stderrLog, []byte(stderr), 0644); err != nil {
+		log.Error().Err(err).Msgf("failed to write %v's stderr to %v", cmd.Path, stderrLog)
+	}
+}
+
+// removeGrafanaContainers force-removes the containers (running or not) created from the Grafana image that mount the database at dbPath.
+// Containers mounting other databases (ex: those of other users or working directories) are left alone.
+// Returns the number of containers removed.
+func removeGrafanaContainers(ctx context.Context, dbPath string) (removed int, _ error) {
+	absDBPath, err := filepath.Abs(dbPath)
+	if err != nil {
+		return 0, err
+	}
+	containers, err := dCLI.ContainerList(ctx, container.ListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("ancestor", omen.VisualizationGrafanaImage),
+			filters.Arg("label", labelDB+"="+absDBPath),
+		),
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, c := range containers {
+		if err := dCLI.ContainerRemove(ctx, c.ID, container.RemoveOptions{Force: true}); err != nil {
+			return removed, fmt.Errorf("failed to remove container %s: %w", c.ID, err)
+		}
+		log.Info().Str("container ID", c.ID).Strs("names", c.Names).
+			Str("input", c.Labels[labelInput]).Str("created", c.Labels[labelCreated]).
+			Msg("removed prior Grafana container")
+		removed += 1
+	}
+	return removed, nil
+}
+
+// checkPortAvailable ensures the given port can be bound on all interfaces by briefly listening on it.
+func checkPortAvailable(port string) error {
+	l, err := net.Listen("tcp", net.JoinHostPort("0.0.0.0", port))
+	if err != nil {
+		log.Debug().Err(err).Str("port", port).Msg("failed to bind port")
+		return fmt.Errorf("%w: %s (is a prior Grafana container still running?). Pick another with --grafana-port", ErrPortInUse, port)
+	}
+	return l.Close()
+}
+
 // waitDisplay awaits any value on the result channel.
 // In the meantime, it prints a simple, looping string to represent that processing is still occurring.
 //
@@ -309,21 +677,22 @@ type invalidInput struct {
 // Returns an array of paths for files that passed validation.
 //
 // NOTE(rlandau): assumes a unix-like host for path prefixing
-func runInputValidationModule(inputPaths []string) ([]string, error) {
+func runInputValidationModule(ctx context.Context, exe *stepExecutor, inputPaths []string) ([]string, error) {
 	var passed []string
 
 	for _, inPath := range inputPaths {
 		if strings.TrimSpace(inPath) == "" {
 			continue
 		}
-		filename := path.Base(inPath)
 		// Docker requires paths to be prefixed with ./ or be absolute
 		if !path.IsAbs(inPath) && !strings.HasPrefix(inPath, "./") {
 			inPath = "./" + inPath
 		}
 		// execute input validation
-		cmd := exec.Command("docker", "run", "--rm", "-v", inPath+":/input/"+path.Base(filename), inputValidatorImage+":"+inputValidatorImageTag, "/input/"+filename)
-		log.Debug().Strs("args", cmd.Args).Msg("executing validator script")
+		cmd, err := exe.command(ctx, StepInputValidation, inPath)
+		if err != nil {
+			return nil, err
+		}
 		if stdout, err := cmd.Output(); err != nil {
 			ee, ok := err.(*exec.ExitError)
 			if !ok || ee.ExitCode() != 1 {
@@ -0,0 +1,167 @@
+package main
+
+// This file defines the fixed set of commands the coordinator is allowed to execute.
+// Every exec.Command in the coordinator must be composed here so the command surface of the pipeline stays auditable.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ModuleStep enumerates the commands the coordinator may execute.
+type ModuleStep uint8
+
+const (
+	StepInputValidation  ModuleStep = iota // validates a single input file via the input validator container
+	StepTestRunner                         // executes the tests in a validated input file via the test runner binary
+	StepCoalesceOutput                     // coalesces raw test output via the coalesce output binary
+	StepLoaderGraph                        // generates the node/edge tables via the loader script
+	StepLoaderTimeseries                   // generates the timeseries table via the loader script
+)
+
+func (s ModuleStep) String() string {
+	switch s {
+	case StepInputValidation:
+		return "input validation"
+	case StepTestRunner:
+		return "test runner"
+	case StepCoalesceOutput:
+		return "coalesce output"
+	case StepLoaderGraph:
+		return "visualization loader (graph)"
+	case StepLoaderTimeseries:
+		return "visualization loader (timeseries)"
+	default:
+		return fmt.Sprintf("unknown step (%d)", uint8(s))
+	}
+}
+
+// ErrUnknownStep is returned when composing a step that is not enumerated.
+var ErrUnknownStep = errors.New("unknown module step")
+
+// stepExecutor composes the command for each ModuleStep.
+// The binary and flags of each step are fixed; only the operands (typically paths) are supplied by the caller.
+type stepExecutor struct {
+	testRunnerBinaryPath     string
+	coalesceOutputBinaryPath string
+	loaderScriptPath         string
+	driverScriptPath         string // passed to the test runner as --driver-script, if set
+	assumeYes                bool   // passed to the test runner as --assume-yes
+	local                    bool   // passed to the test runner as --local
+	jump                     string // passed to the test runner as --jump, if set
+	identity                 string // passed to the test runner as --identity, if set
+	repetitions              uint   // passed to the test runner as --repetitions, if above 1
+	runID, runTS             string // if set, loader steps merge into the database under this run rather than recreating it
+}
+
+// loaderRunArgs returns the loader arguments that stamp rows with the run, if merging.
+func (e *stepExecutor) loaderRunArgs() []string {
+	if e.runID == "" {
+		return nil
+	}
+	return []string{"--run-id", e.runID, "--run-ts", e.runTS}
+}
+
+// command returns the command for the given step, composed from the step's fixed template and the given operands.
+// Operands must be non-empty and cannot look like flags.
+// The command is killed if ctx is done before it completes.
+//
+// Expected operands:
+//
+// StepInputValidation: input file path
+//
+// StepTestRunner: input file path
+//
+// StepCoalesceOutput: raw results directory
+//
+// StepLoaderGraph: database path, results directory (whose timeframes are each passed to the loader as a set; see discoverTimeframes)
+//
+// StepLoaderTimeseries: database path, results directory
+func (e *stepExecutor) command(ctx context.Context, step ModuleStep, operands ...string) (*exec.Cmd, error) {
+	var want int
+	switch step {
+	case StepInputValidation, StepTestRunner, StepCoalesceOutput:
+		want = 1
+	case StepLoaderGraph, StepLoaderTimeseries:
+		want = 2
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownStep, step)
+	}
+	if len(operands) != want {
+		return nil, fmt.Errorf("%v step expects %d operand(s), got %d", step, want, len(operands))
+	}
+	for i, op := range operands {
+		if strings.TrimSpace(op) == "" {
+			return nil, fmt.Errorf("%v step: operand %d cannot be empty", step, i)
+		} else if strings.HasPrefix(op, "-") {
+			return nil, fmt.Errorf("%v step: operand %d (%q) cannot begin with '-'", step, i, op)
+		}
+	}
+
+	var cmd *exec.Cmd
+	switch step {
+	case StepInputValidation:
+		inPath := operands[0]
+		if strings.Contains(inPath, ":") {
+			return nil, fmt.Errorf("%v step: input path %q cannot contain ':'", step, inPath)
+		}
+		filename := path.Base(inPath)
+		cmd = exec.CommandContext(ctx, "docker", "run", "--rm",
+			"-v", inPath+":/input/"+filename,
+			inputValidatorImage+":"+inputValidatorImageTag,
+			"/input/"+filename)
+	case StepTestRunner:
+		args := []string{"--interactive=false"}
+		if e.assumeYes {
+			args = append(args, "--assume-yes")
+		}
+		if e.local {
+			args = append(args, "--local")
+		}
+		if e.jump != "" {
+			args = append(args, "--jump="+e.jump)
+		}
+		if e.identity != "" {
+			args = append(args, "--identity="+e.identity)
+		}
+		if e.repetitions > 1 {
+			args = append(args, "--repetitions="+strconv.FormatUint(uint64(e.repetitions), 10))
+		}
+		if e.driverScriptPath != "" {
+			args = append(args, "--driver-script="+e.driverScriptPath)
+		}
+		cmd = exec.CommandContext(ctx, e.testRunnerBinaryPath, append(args, operands[0])...)
+	case StepCoalesceOutput:
+		cmd = exec.CommandContext(ctx, e.coalesceOutputBinaryPath, operands[0])
+	case StepLoaderGraph:
+		sets, err := discoverTimeframes(operands[1])
+		if err != nil {
+			return nil, fmt.Errorf("%v step: %w", step, err)
+		}
+		args := append([]string{e.loaderScriptPath, "graph",
+			"--db", operands[0],
+			"--root", operands[1],
+		}, loaderSetArgs(sets)...)
+		if e.runID == "" {
+			args = append(args, "--recreate")
+		}
+		cmd = exec.CommandContext(ctx, "python3", append(args, e.loaderRunArgs()...)...)
+	case StepLoaderTimeseries:
+		args := []string{e.loaderScriptPath, "timeseries",
+			"--root", operands[1],
+			"--csv", "ping_data.csv",
+			"--db", operands[0],
+			"--table", "ping_data",
+			"--if-exists", "replace",
+			"--aggregate-by", "movement_number",
+		}
+		cmd = exec.CommandContext(ctx, "python3", append(args, e.loaderRunArgs()...)...)
+	}
+	log.Debug().Str("step", step.String()).Strs("args", cmd.Args).Msg("composed step command")
+	return cmd, nil
+}
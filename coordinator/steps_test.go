@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func Test_stepExecutor_command_rejects(t *testing.T) {
+	e := &stepExecutor{testRunnerBinaryPath: "1_spawn", coalesceOutputBinaryPath: "2_coalesce", loaderScriptPath: "omenloader.py"}
+	tests := []struct {
+		name     string
+		step     ModuleStep
+		operands []string
+		wantErr  error // if non-nil, the error must wrap it
+	}{
+		{"unknown step", ModuleStep(200), []string{"in.json"}, ErrUnknownStep},
+		{"step past the last", StepLoaderTimeseries + 1, []string{"in.json"}, ErrUnknownStep},
+		{"validation without operands", StepInputValidation, nil, nil},
+		{"validation with extra operands", StepInputValidation, []string{"a.json", "b.json"}, nil},
+		{"test runner with extra operands", StepTestRunner, []string{"a.json", "b.json"}, nil},
+		{"coalesce without operands", StepCoalesceOutput, []string{}, nil},
+		{"graph loader with one operand", StepLoaderGraph, []string{"omen.db"}, nil},
+		{"timeseries loader with three operands", StepLoaderTimeseries, []string{"omen.db", "results", "extra"}, nil},
+		{"empty operand", StepTestRunner, []string{""}, nil},
+		{"whitespace operand", StepCoalesceOutput, []string{" \t"}, nil},
+		{"empty second operand", StepLoaderTimeseries, []string{"omen.db", ""}, nil},
+		{"flag operand", StepTestRunner, []string{"--local"}, nil},
+		{"short flag operand", StepCoalesceOutput, []string{"-h"}, nil},
+		{"flag second operand", StepLoaderGraph, []string{"omen.db", "--recreate"}, nil},
+		{"validator path with a colon", StepInputValidation, []string{"/tmp/in.json:/etc/passwd"}, nil},
+		{"validator path with a trailing colon", StepInputValidation, []string{"in.json:"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := e.command(context.Background(), tt.step, tt.operands...)
+			if err == nil {
+				t.Fatalf("command(%v, %q) = %v, want an error", tt.step, tt.operands, cmd.Args)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("command(%v, %q) = %v, want it to wrap %v", tt.step, tt.operands, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_stepExecutor_command(t *testing.T) {
+	e := &stepExecutor{testRunnerBinaryPath: "1_spawn", coalesceOutputBinaryPath: "2_coalesce", loaderScriptPath: "omenloader.py",
+		assumeYes: true, repetitions: 3}
+	results := t.TempDir()
+	if err := os.Mkdir(filepath.Join(results, "timeframe0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		step     ModuleStep
+		operands []string
+		want     []string
+	}{
+		{StepInputValidation, []string{"/tmp/in.json"},
+			[]string{"docker", "run", "--rm", "-v", "/tmp/in.json:/input/in.json", inputValidatorImage + ":" + inputValidatorImageTag, "/input/in.json"}},
+		{StepTestRunner, []string{"in.json"},
+			[]string{"1_spawn", "--interactive=false", "--assume-yes", "--repetitions=3", "in.json"}},
+		{StepCoalesceOutput, []string{"raw"}, []string{"2_coalesce", "raw"}},
+		{StepLoaderGraph, []string{"omen.db", results},
+			[]string{"python3", "omenloader.py", "graph", "--db", "omen.db", "--root", results,
+				"--set1-prefix", "netA", "--set1-dir", "timeframe0", "--recreate"}},
+	}
+	for _, tt := range tests {
+		cmd, err := e.command(context.Background(), tt.step, tt.operands...)
+		if err != nil {
+			t.Errorf("command(%v, %q) = %v", tt.step, tt.operands, err)
+		} else if !slices.Equal(cmd.Args, tt.want) {
+			t.Errorf("command(%v, %q) = %q, want %q", tt.step, tt.operands, cmd.Args, tt.want)
+		}
+	}
+}
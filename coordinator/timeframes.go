@@ -0,0 +1,148 @@
+package main
+
+// This file discovers the timeframes Coalesce Output produced, so the loader is given one set per timeframe.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// coalesceManifestFile is the file, within the results directory, in which Coalesce Output describes what it wrote.
+const coalesceManifestFile string = "manifest.json"
+
+// coalesceManifestSchemaVersion is the manifest schema version the coordinator understands.
+const coalesceManifestSchemaVersion int = 1
+
+// ErrNoTimeframes is returned when the results directory contains no timeframes to load.
+var ErrNoTimeframes = errors.New("no timeframes found")
+
+// loaderSet is a single timeframe, as passed to the loader's graph subcommand.
+// Paths are relative to the results directory.
+type loaderSet struct {
+	timeframe uint
+	dir       string
+	ts        string // movement CSV; may be empty, in which case the loader looks for one in dir
+}
+
+// prefix returns the table prefix of the set: netA for timeframe 0, netB for timeframe 1, ..., netZ, netAA, netAB, ...
+// Prefixes are derived from the timeframe (rather than the set's position) so a timeframe's tables keep their name,
+// and thus their dashboard, regardless of which other timeframes exist.
+func (s loaderSet) prefix() string {
+	var letters []byte
+	for n := s.timeframe + 1; n > 0; n = (n - 1) / 26 {
+		letters = append(letters, byte('A'+(n-1)%26))
+	}
+	slices.Reverse(letters)
+	return "net" + string(letters)
+}
+
+// discoverTimeframes returns the timeframes within resultsDir, ordered by timeframe.
+// If Coalesce Output wrote a manifest, the timeframes are taken from it; otherwise, resultsDir is scanned for timeframeN directories.
+func discoverTimeframes(resultsDir string) ([]loaderSet, error) {
+	sets, err := manifestTimeframes(filepath.Join(resultsDir, coalesceManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		sets, err = scanTimeframes(resultsDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoTimeframes, resultsDir)
+	}
+	slices.SortFunc(sets, func(a, b loaderSet) int { return int(a.timeframe) - int(b.timeframe) })
+	return sets, nil
+}
+
+// manifestTimeframes returns the timeframes listed in the manifest at pth.
+// Returns an error wrapping os.ErrNotExist if there is no manifest.
+func manifestTimeframes(pth string) ([]loaderSet, error) {
+	data, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	var m struct {
+		SchemaVersion int `json:"schema_version"`
+		Timeframes    []struct {
+			Timeframe uint     `json:"timeframe"`
+			Dir       string   `json:"dir"`
+			Files     []string `json:"files"`
+		} `json:"timeframes"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", pth, err)
+	}
+	if m.SchemaVersion != coalesceManifestSchemaVersion {
+		return nil, fmt.Errorf("manifest %s has schema version %d (expected %d)", pth, m.SchemaVersion, coalesceManifestSchemaVersion)
+	}
+	sets := make([]loaderSet, 0, len(m.Timeframes))
+	for _, tf := range m.Timeframes {
+		if tf.Dir == "" {
+			return nil, fmt.Errorf("manifest %s: timeframe %d has no dir", pth, tf.Timeframe)
+		}
+		set := loaderSet{timeframe: tf.Timeframe, dir: filepath.FromSlash(tf.Dir)}
+		for _, f := range tf.Files {
+			if isMovementCSV(path.Base(f)) {
+				set.ts = filepath.FromSlash(f)
+				break
+			}
+		}
+		sets = append(sets, set)
+	}
+	log.Debug().Str("manifest", pth).Int("timeframes", len(sets)).Msg("discovered timeframes from manifest")
+	return sets, nil
+}
+
+// scanTimeframes returns a set for each timeframeN directory directly within resultsDir.
+func scanTimeframes(resultsDir string) ([]loaderSet, error) {
+	entries, err := os.ReadDir(resultsDir)
+	if err != nil {
+		return nil, err
+	}
+	var sets []loaderSet
+	for _, ent := range entries {
+		num, found := strings.CutPrefix(ent.Name(), "timeframe")
+		if !found || !ent.IsDir() {
+			continue
+		}
+		tf, err := strconv.ParseUint(num, 10, 32)
+		if err != nil {
+			continue
+		}
+		set := loaderSet{timeframe: uint(tf), dir: ent.Name()}
+		for _, ext := range []string{".csv", ".csv.gz"} {
+			ts := filepath.Join(ent.Name(), "ping_data_movement_"+num+ext)
+			if _, err := os.Stat(filepath.Join(resultsDir, ts)); err == nil {
+				set.ts = ts
+				break
+			}
+		}
+		sets = append(sets, set)
+	}
+	log.Debug().Str("results", resultsDir).Int("timeframes", len(sets)).Msg("discovered timeframes from directories")
+	return sets, nil
+}
+
+// isMovementCSV reports whether name is that of a per-timeframe movement CSV (optionally gzipped).
+func isMovementCSV(name string) bool {
+	return strings.HasPrefix(name, "ping_data_movement_") && (strings.HasSuffix(name, ".csv") || strings.HasSuffix(name, ".csv.gz"))
+}
+
+// loaderSetArgs returns the --setN-* arguments of the loader's graph subcommand, numbering sets from 1.
+func loaderSetArgs(sets []loaderSet) []string {
+	var args []string
+	for i, set := range sets {
+		flag := "--set" + strconv.Itoa(i+1)
+		args = append(args, flag+"-prefix", set.prefix(), flag+"-dir", set.dir)
+		if set.ts != "" {
+			args = append(args, flag+"-ts", set.ts)
+		}
+	}
+	return args
+}
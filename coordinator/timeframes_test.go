@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func Test_loaderSet_prefix(t *testing.T) {
+	tests := []struct {
+		timeframe uint
+		want      string
+	}{
+		{0, "netA"},
+		{1, "netB"},
+		{24, "netY"},
+		{25, "netZ"},
+		{26, "netAA"},
+		{27, "netAB"},
+		{51, "netAZ"},
+		{52, "netBA"},
+		{701, "netZZ"},
+		{702, "netAAA"},
+	}
+	for _, tt := range tests {
+		if got := (loaderSet{timeframe: tt.timeframe}).prefix(); got != tt.want {
+			t.Errorf("prefix() of timeframe %d = %q, want %q", tt.timeframe, got, tt.want)
+		}
+	}
+}
+
+func Test_discoverTimeframes(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string // path (relative to the results directory) -> contents; paths ending in / are directories
+		want    []loaderSet
+		wantErr error // if non-nil, the error must wrap it
+	}{
+		{"manifest", map[string]string{
+			"manifest.json": `{"schema_version": 1, "timeframes": [
+				{"timeframe": 0, "dir": "timeframe0", "files": ["timeframe0/ping_data.csv", "timeframe0/ping_data_movement_0.csv"]},
+				{"timeframe": 1, "dir": "timeframe1", "files": ["timeframe1/ping_data.csv"]}]}`,
+		}, []loaderSet{
+			{timeframe: 0, dir: "timeframe0", ts: filepath.Join("timeframe0", "ping_data_movement_0.csv")},
+			{timeframe: 1, dir: "timeframe1"},
+		}, nil},
+		{"manifest over directories", map[string]string{ // directories the manifest does not list are not loaded
+			"manifest.json": `{"schema_version": 1, "timeframes": [{"timeframe": 3, "dir": "tf3"}]}`,
+			"timeframe0/":   "",
+		}, []loaderSet{{timeframe: 3, dir: "tf3"}}, nil},
+		{"manifest out of order", map[string]string{
+			"manifest.json": `{"schema_version": 1, "timeframes": [{"timeframe": 2, "dir": "timeframe2"}, {"timeframe": 0, "dir": "timeframe0"}]}`,
+		}, []loaderSet{{timeframe: 0, dir: "timeframe0"}, {timeframe: 2, dir: "timeframe2"}}, nil},
+		{"manifest of another schema version", map[string]string{
+			"manifest.json": `{"schema_version": 2, "timeframes": [{"timeframe": 0, "dir": "timeframe0"}]}`,
+		}, nil, nil},
+		{"manifest timeframe without a dir", map[string]string{
+			"manifest.json": `{"schema_version": 1, "timeframes": [{"timeframe": 0}]}`,
+		}, nil, nil},
+		{"malformed manifest", map[string]string{"manifest.json": `{"schema_version": 1,`}, nil, nil},
+		{"empty manifest", map[string]string{"manifest.json": `{"schema_version": 1, "timeframes": []}`}, nil, ErrNoTimeframes},
+		{"directories", map[string]string{
+			"timeframe0/ping_data.csv":               "",
+			"timeframe0/ping_data_movement_0.csv":    "",
+			"timeframe1/ping_data_movement_1.csv.gz": "",
+			"timeframe2/":                            "",
+		}, []loaderSet{
+			{timeframe: 0, dir: "timeframe0", ts: filepath.Join("timeframe0", "ping_data_movement_0.csv")},
+			{timeframe: 1, dir: "timeframe1", ts: filepath.Join("timeframe1", "ping_data_movement_1.csv.gz")},
+			{timeframe: 2, dir: "timeframe2"},
+		}, nil},
+		{"directories in numeric order", map[string]string{ // not lexical: timeframe10 sorts after timeframe9
+			"timeframe10/": "", "timeframe9/": "", "timeframe1/": "",
+		}, []loaderSet{{timeframe: 1, dir: "timeframe1"}, {timeframe: 9, dir: "timeframe9"}, {timeframe: 10, dir: "timeframe10"}}, nil},
+		{"directories ignore others", map[string]string{
+			"timeframe0/": "", "timeframeX/": "", "timeframe1": "a file", "raw/": "", "ping_data.csv": "",
+		}, []loaderSet{{timeframe: 0, dir: "timeframe0"}}, nil},
+		{"no timeframes", map[string]string{"raw/": ""}, nil, ErrNoTimeframes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, contents := range tt.files {
+				pth := filepath.Join(dir, filepath.FromSlash(name))
+				if strings.HasSuffix(name, "/") {
+					if err := os.MkdirAll(pth, 0755); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				if err := os.MkdirAll(filepath.Dir(pth), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(pth, []byte(contents), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := discoverTimeframes(dir)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("discoverTimeframes() = %+v, want an error", got)
+				} else if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("discoverTimeframes() = %v, want it to wrap %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("discoverTimeframes() = %v", err)
+			} else if !slices.Equal(got, tt.want) {
+				t.Errorf("discoverTimeframes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := discoverTimeframes(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("discoverTimeframes() of a missing directory succeeded")
+	}
+}
+
+func Test_loaderSetArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		sets []loaderSet
+		want []string
+	}{
+		{"none", nil, nil},
+		{"single", []loaderSet{{timeframe: 0, dir: "timeframe0"}},
+			[]string{"--set1-prefix", "netA", "--set1-dir", "timeframe0"}},
+		{"multiple", []loaderSet{
+			{timeframe: 0, dir: "timeframe0", ts: "timeframe0/ping_data_movement_0.csv"},
+			{timeframe: 1, dir: "timeframe1"},
+			{timeframe: 2, dir: "timeframe2", ts: "timeframe2/ping_data_movement_2.csv.gz"},
+		}, []string{
+			"--set1-prefix", "netA", "--set1-dir", "timeframe0", "--set1-ts", "timeframe0/ping_data_movement_0.csv",
+			"--set2-prefix", "netB", "--set2-dir", "timeframe1",
+			"--set3-prefix", "netC", "--set3-dir", "timeframe2", "--set3-ts", "timeframe2/ping_data_movement_2.csv.gz",
+		}},
+		{"gaps and rollover", []loaderSet{ // sets are numbered by position, prefixes by timeframe
+			{timeframe: 2, dir: "timeframe2"},
+			{timeframe: 25, dir: "timeframe25"},
+			{timeframe: 26, dir: "timeframe26"},
+		}, []string{
+			"--set1-prefix", "netC", "--set1-dir", "timeframe2",
+			"--set2-prefix", "netZ", "--set2-dir", "timeframe25",
+			"--set3-prefix", "netAA", "--set3-dir", "timeframe26",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loaderSetArgs(tt.sets); !slices.Equal(got, tt.want) {
+				t.Errorf("loaderSetArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
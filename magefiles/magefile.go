@@ -9,7 +9,9 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/magefile/mage/mg"
 	"github.com/magefile/mage/sh"
@@ -21,8 +23,28 @@ const (
 	coordinatorBin   string = "coordinator"
 	spawnTopoBin     string = "1_spawn"
 	outputProcessBin string = "2_output_processing"
+
+	coverageProfile string = "coverage.out" // placed in buildDir
+
+	fixturesTag string = "fixtures" // build tag of the output processing fixture generator, which must not ship in the binary
 )
 
+// python scripts the pipeline ships alongside the binaries.
+var pythonScripts = []string{
+	"modules/1_spawn_topology/mininet-script.py",
+	"modules/3_output_visualization/omenloader.py",
+}
+
+// packages (relative to repo root) tested by Test.
+// omen-gui is excluded as it cannot compile without its frontend being built by wails.
+var testedPackages = []string{
+	".",
+	"./coordinator/...",
+	"./modules/0_input/...",
+	"./modules/1_spawn_topology/...",
+	"./modules/2_mn_raw_output_processing/...",
+}
+
 var Default = Build
 
 //#region module building
@@ -31,7 +53,7 @@ var Default = Build
 func BuildCoordinator() error {
 	mg.Deps(artefactDirectoryExists)
 	var sbErr strings.Builder
-	_, err := sh.Exec(nil, nil, &sbErr, "go", "build", "-o", "artefacts/"+coordinatorBin, "./coordinator")
+	_, err := sh.Exec(nil, nil, &sbErr, "go", "build", "-ldflags", ldflags(), "-o", "artefacts/"+coordinatorBin, "./coordinator")
 	if err != nil {
 		fmt.Fprintln(&sbErr)
 	}
@@ -47,7 +69,7 @@ func DockerizeIV() error {
 // BuildSpawnTopo builds the binary for the glue module.
 func BuildSpawnTopo() error {
 	mg.Deps(artefactDirectoryExists)
-	return sh.Run("go", "build", "-C", "modules/1_spawn_topology/", "-o", "../../artefacts/"+spawnTopoBin)
+	return sh.Run("go", "build", "-C", "modules/1_spawn_topology/", "-ldflags", ldflags(), "-o", "../../artefacts/"+spawnTopoBin)
 }
 
 // BuildOutputProcessing builds the binary for the output coalesce module.
@@ -56,7 +78,7 @@ func BuildOutputProcessing() error {
 
 	var sbErr strings.Builder
 
-	_, err := sh.Exec(nil, nil, &sbErr, "go", "build", "-C", "modules/2_mn_raw_output_processing/", "-o", "../../artefacts/"+outputProcessBin)
+	_, err := sh.Exec(nil, nil, &sbErr, "go", "build", "-C", "modules/2_mn_raw_output_processing/", "-ldflags", ldflags(), "-o", "../../artefacts/"+outputProcessBin)
 	if err != nil {
 		fmt.Println(sbErr.String())
 	}
@@ -72,6 +94,42 @@ func DockerizeOV() error {
 	return sh.Run("docker", "build", "-t", omen.VisualizationGrafanaImage, "-f", "modules/3_output_visualization/grafana-sqlite.Dockerfile", "modules/3_output_visualization")
 }
 
+// crossTargets are the platforms BuildCross compiles for.
+var crossTargets = []struct{ goos, goarch string }{
+	{"linux", "amd64"},
+	{"linux", "arm64"},
+}
+
+// BuildCross cross-compiles the coordinator and module binaries for each supported platform.
+// Each platform's binaries (and the scripts they depend on) are placed in ./artefacts/<os>_<arch>/.
+func BuildCross() error {
+	mg.Deps(artefactDirectoryExists)
+	binaries := []struct{ bin, pkg string }{
+		{coordinatorBin, "./coordinator"},
+		{spawnTopoBin, "./modules/1_spawn_topology"},
+		{outputProcessBin, "./modules/2_mn_raw_output_processing"},
+	}
+	for _, t := range crossTargets {
+		outDir := path.Join(buildDir, t.goos+"_"+t.goarch)
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return err
+		}
+		env := map[string]string{"GOOS": t.goos, "GOARCH": t.goarch, "CGO_ENABLED": "0"}
+		for _, b := range binaries {
+			fmt.Printf("building %s for %s/%s\n", b.bin, t.goos, t.goarch)
+			var sbErr strings.Builder
+			if _, err := sh.Exec(env, nil, &sbErr, "go", "build", "-ldflags", ldflags(), "-o", path.Join(outDir, b.bin), b.pkg); err != nil {
+				fmt.Println(sbErr.String())
+				return err
+			}
+		}
+		if err := copyScripts(outDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 //#endregion module building
 
 // Gui leverages wails to compile the GUI.
@@ -101,19 +159,57 @@ func Gui(debug bool) error {
 	return nil
 }
 
-// Build builds all required files and containers.
-func Build() error {
-	mg.Deps(DockerizeIV, BuildCoordinator, BuildSpawnTopo, BuildOutputProcessing, DockerizeOV)
+//#region testing
 
-	// copy the driver script into the artefacts directory so it can be passed by spawn topology
-	if err := sh.Copy(path.Join(buildDir, "mininet-script.py"), "modules/1_spawn_topology/mininet-script.py"); err != nil {
+// Test runs the tests of every module (including those against generated fixtures), writing a combined coverage profile to ./artefacts/coverage.out.
+func Test() error {
+	mg.Deps(artefactDirectoryExists)
+	args := append([]string{"test", "-tags", fixturesTag, "-coverprofile", path.Join(buildDir, coverageProfile)}, testedPackages...)
+	return sh.RunV("go", args...)
+}
+
+// GenFixtures writes the synthetic raw files (and the CSVs Coalesce Output must produce from them) that the output processing
+// tests run against into dir, one subdirectory per fixture.
+func GenFixtures(dir string) error {
+	abs, err := filepath.Abs(dir) // go test runs within the package directory
+	if err != nil {
 		return err
 	}
-	// copy the database generator script into artefacts for coordinator to invoke directly
-	if err := sh.Copy(path.Join(buildDir, "omenloader.py"), "modules/3_output_visualization/omenloader.py"); err != nil {
+	return sh.RunV("go", "test", "-tags", fixturesTag, "-run", "^Test_fixtures$", "./modules/2_mn_raw_output_processing",
+		"-args", "-fixtures.out="+abs)
+}
+
+// Cover runs the tests and opens the resulting coverage profile as HTML in the browser.
+func Cover() error {
+	mg.Deps(Test)
+	return sh.Run("go", "tool", "cover", "-html", path.Join(buildDir, coverageProfile))
+}
+
+// LintScripts compiles the python driver and loader scripts to catch syntax errors before they are shipped.
+// If flake8 is available, it is also run against the scripts (checking only for errors that would break at runtime).
+func LintScripts() error {
+	if _, err := exec.LookPath("python3"); err != nil {
 		return err
 	}
-	return nil
+	// keep bytecode out of the source tree
+	env := map[string]string{"PYTHONPYCACHEPREFIX": path.Join(os.TempDir(), "omen-pycache")}
+	if err := sh.RunWith(env, "python3", append([]string{"-m", "py_compile"}, pythonScripts...)...); err != nil {
+		return err
+	}
+	if _, err := exec.LookPath("flake8"); err != nil {
+		fmt.Println("flake8 not found in PATH; skipping")
+		return nil
+	}
+	return sh.RunV("flake8", append([]string{"--select=E9,F63,F7,F82"}, pythonScripts...)...)
+}
+
+//#endregion testing
+
+// Build builds all required files and containers.
+func Build() error {
+	mg.Deps(LintScripts, DockerizeIV, BuildCoordinator, BuildSpawnTopo, BuildOutputProcessing, DockerizeOV)
+
+	return copyScripts(buildDir)
 }
 
 // Clean deletes the build directory and everything in it.
@@ -123,6 +219,29 @@ func Clean() error {
 
 //#region helper functions
 
+// ldflags composes the linker flags that inject the current commit and build time into the shared omen package.
+// If the commit cannot be determined (ex: not in a git repo), it is omitted.
+func ldflags() string {
+	flags := "-X Omen.BuildTime=" + time.Now().UTC().Format(time.RFC3339)
+	if commit, err := sh.Output("git", "rev-parse", "HEAD"); err == nil && commit != "" {
+		flags += " -X Omen.Commit=" + commit
+	}
+	return flags
+}
+
+// copies the scripts the binaries depend on into dir.
+func copyScripts(dir string) error {
+	// copy the driver script into the artefacts directory so it can be passed by spawn topology
+	if err := sh.Copy(path.Join(dir, "mininet-script.py"), "modules/1_spawn_topology/mininet-script.py"); err != nil {
+		return err
+	}
+	// copy the database generator script into artefacts for coordinator to invoke directly
+	if err := sh.Copy(path.Join(dir, "omenloader.py"), "modules/3_output_visualization/omenloader.py"); err != nil {
+		return err
+	}
+	return nil
+}
+
 // ensures Docker is in path
 func dockerInPath() error {
 	_, err := exec.LookPath("docker")
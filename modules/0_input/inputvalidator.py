@@ -5,6 +5,7 @@ Network Config Validator (Wi-Fi/Mininet schema)
 
 Validates a JSON spec before handing it to the runner.
 Check include:
+   • Schema validation against input.schema.json (shared with the Go test runner)
    • Schema validation via Pydantic (structure, types, bounds)
    • Semantic validation (duplicates, unknown nodes, etc.)
    • Sanity checks (position format, channels, thresholds)
@@ -24,6 +25,7 @@ import sys, json, argparse, hashlib, re
 from pathlib import Path
 from typing import Literal, Optional, List, Dict, Tuple, DefaultDict
 from collections import defaultdict
+from jsonschema import Draft202012Validator
 from pydantic import BaseModel, Field, ValidationError, model_validator, field_validator
 
 # ---------------- Pydantic Models: Schema Definitions ----------------
@@ -52,6 +54,7 @@ class PropagationModel(BaseModel):
 # Network - level configuration
 class Nets(BaseModel):
     noise_th: float = Field(le=0)      # dBm threshold (should be negative e.g., -91)
+    net_size: Optional[float] = Field(default=None, gt=0)  # edge length (m) of the area nodes are expected within
     propagation_model: PropagationModel
 
 # Regex for position validation
@@ -108,6 +111,7 @@ class TestMove(BaseModel):
     timeframe: int = Field(ge=0)     # UPDATED: required timeframe
     node: str
     position: str
+    settle_ms: int = Field(default=0, ge=0)  # wait after moving, before measuring
 
     @field_validator("position")
     @classmethod
@@ -121,8 +125,19 @@ class TestIw(BaseModel):
     type: Literal["iw"]
     cmd: str            # should ideally contain {interface}
 
+# Ping test
+class TestPing(BaseModel):
+    # Pings dst from src at a given timeframe.
+    name: str
+    type: Literal["ping"]
+    timeframe: int = Field(ge=0)
+    src: str
+    dst: str
+    count: int = Field(default=0, ge=0)
+    deadline_s: int = Field(default=0, ge=0)
+
 # Union type for test vairnts
-TestVariant = TestMove | TestIw
+TestVariant = TestMove | TestIw | TestPing
 
 # Complete specification
 class Spec(BaseModel):
@@ -141,6 +156,18 @@ class Spec(BaseModel):
     password: str = ""
     address: str = ""
 
+# ---------------- Validate Against Shared Schema ----------------
+
+# The JSON Schema the Go test runner also validates against; keep the models above in step with it.
+SCHEMA_PATH = Path(__file__).resolve().parent / "input.schema.json"
+
+def validate_schema(data: dict) -> List[dict]:
+    # Check data against the shared JSON Schema, returning one error per violation.
+    with open(SCHEMA_PATH) as f:
+        schema = json.load(f)
+    return [{"loc": ".".join(map(str, e.absolute_path)) or "root", "code": "schema", "msg": e.message}
+            for e in sorted(Draft202012Validator(schema).iter_errors(data), key=lambda e: list(map(str, e.absolute_path)))]
+
 # ---------------- Validate Semantics ----------------
 
 def _spec_hash(spec_dict: dict) -> str:
@@ -255,6 +282,20 @@ def validate_semantics(spec: Spec) -> Dict[str, List[dict]]:
             "msg": f"noise_th {spec.topo.nets.noise_th} dBm is unusually high (less negative)"
         })
 
+    # Positions outside of net_size (if given)
+    size = spec.topo.nets.net_size
+    if size is not None:
+        placed = [(f"topo.aps[{i}].position", ap.position) for i, ap in enumerate(spec.topo.aps)]
+        placed += [(f"topo.stations[{i}].position", sta.position) for i, sta in enumerate(spec.topo.stations)]
+        placed += [(f"tests[{i}].position", t.position) for i, t in enumerate(spec.tests) if isinstance(t, TestMove)]
+        for loc, pos in placed:
+            if any(c < 0 or c > size for c in _positions_tuple(pos)):
+                warnings.append({
+                    "loc": loc,
+                    "code": "out_of_bounds",
+                    "msg": f"position ({pos}) is outside of the {size}m net_size; the node is likely out of range"
+                })
+
     return {"errors": errors, "warnings": warnings}
 
 # ---------------- CLI ----------------
@@ -304,6 +345,12 @@ def main():
     try:
         with open(cfg_path) as f:
             data = json.load(f)
+        schema_errors = validate_schema(data)
+        if schema_errors:
+            out = {"ok": False, "errors": schema_errors, "warnings": []}
+            print(json.dumps(out, indent=2))
+            _print_stderr("VALIDATION_ERROR: schema validation failed", out["errors"])
+            sys.exit(1)
         spec = Spec(**data)
     except ValidationError as ve:
         # Structural (schema) errors
@@ -0,0 +1,48 @@
+// Package inputschema embeds the JSON Schema of input topologies,
+// so the Go modules check inputs against the same document as the input validator (inputvalidator.py).
+package inputschema
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// SchemaFileName is the name of the schema within this directory (and within the input validator's image).
+const SchemaFileName string = "input.schema.json"
+
+//go:embed input.schema.json
+var schemaJSON []byte
+
+// schemaURL identifies the schema to the compiler (and in validation errors).
+const schemaURL string = "urn:omen:" + SchemaFileName
+
+// compiled is the schema, compiled on first use.
+var compiled = sync.OnceValues(func() (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schemaJSON))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", SchemaFileName, err)
+	}
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(schemaURL, doc); err != nil {
+		return nil, err
+	}
+	return c.Compile(schemaURL)
+})
+
+// Validate checks the JSON document data against the schema.
+// Returns a *jsonschema.ValidationError listing every violation if data does not conform.
+func Validate(data []byte) error {
+	sch, err := compiled()
+	if err != nil {
+		return fmt.Errorf("compile input schema: %w", err)
+	}
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("parse JSON: %w", err)
+	}
+	return sch.Validate(inst)
+}
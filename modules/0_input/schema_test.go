@@ -0,0 +1,67 @@
+package inputschema
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	good, err := os.ReadFile("input.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(good); err != nil {
+		t.Fatalf("input.json failed validation: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(in map[string]any)
+	}{
+		{"missing meta", func(in map[string]any) { delete(in, "meta") }},
+		{"unknown backend", func(in map[string]any) { in["meta"].(map[string]any)["backend"] = "ns3" }},
+		{"bad position", func(in map[string]any) { station(in, 0)["position"] = "0,10" }},
+		{"lognormal without s", func(in map[string]any) {
+			delete(in["topo"].(map[string]any)["nets"].(map[string]any)["propagation_model"].(map[string]any), "s")
+		}},
+		{"non-positive net_size", func(in map[string]any) { in["topo"].(map[string]any)["nets"].(map[string]any)["net_size"] = 0 }},
+		{"movement without node", func(in map[string]any) { delete(test(in, 0), "node") }},
+		{"negative settle_ms", func(in map[string]any) { test(in, 0)["settle_ms"] = -1 }},
+		{"unknown test type", func(in map[string]any) { test(in, 0)["type"] = "iperf" }},
+		{"negative ping count", func(in map[string]any) {
+			in["tests"] = append(in["tests"].([]any), map[string]any{
+				"name": "ping", "type": "ping", "timeframe": 1, "src": "sta1", "dst": "sta2", "count": -1,
+			})
+		}},
+		{"loss_pkt above 1", func(in map[string]any) {
+			in["topo"].(map[string]any)["links"] = []any{map[string]any{
+				"node_id_a": "ap1", "node_id_b": "ap2", "constraints": map[string]any{"loss_pkt": 1.5},
+			}}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var in map[string]any
+			if err := json.Unmarshal(good, &in); err != nil {
+				t.Fatal(err)
+			}
+			tt.mutate(in)
+			data, err := json.Marshal(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Validate(data); err == nil {
+				t.Error("Validate() succeeded unexpectedly")
+			}
+		})
+	}
+}
+
+func station(in map[string]any, i int) map[string]any {
+	return in["topo"].(map[string]any)["stations"].([]any)[i].(map[string]any)
+}
+
+func test(in map[string]any, i int) map[string]any {
+	return in["tests"].([]any)[i].(map[string]any)
+}
@@ -0,0 +1,91 @@
+package main
+
+// This file implements the test-connection subcommand, a fast preflight that confirms the remote is usable without uploading or running anything.
+
+import (
+	omen "Omen"
+	"Omen/modules/1_spawn_topology/models"
+	"Omen/ssh"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// newTestConnectionCommand returns the test-connection subcommand.
+// It resolves its config identically to the root command.
+func newTestConnectionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "test-connection <topo>.(json|yaml)",
+		Short: "check that the remote mininet host is reachable and usable",
+		Long: "test-connection connects to the remote host resolved from the topology and flags, then checks the remote user and that mininet is in its PATH.\n" +
+			"Nothing is uploaded or run.",
+		Example: appName + " test-connection input.json\n" +
+			appName + " test-connection --remote=wifi@127.0.0.1:22 input.json",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: loadConfig,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if config.TopoJSONFile != config.TopoFile { // clean up the topology converted from YAML
+				defer os.Remove(config.TopoJSONFile)
+			}
+			return testConnection(&config)
+		},
+	}
+}
+
+// testConnection dials the remote described by config, then runs whoami and `which mn` on it.
+// Each step is reported with its timing; the first failed step stops the test and is returned.
+func testConnection(config *models.Config) error {
+	var (
+		client *ssh.Client
+		start  = time.Now()
+	)
+	var via string
+	if config.Jump != nil {
+		via = " via " + config.Jump.String()
+	}
+	steps := []struct {
+		name string
+		run  func() (string, error)
+	}{
+		{"connect to " + config.Username + "@" + config.Host.String() + via, func() (_ string, err error) {
+			client, err = connect(config)
+			return "", err
+		}},
+		{"whoami", func() (string, error) {
+			return client.Run("whoami")
+		}},
+		{"which mn", func() (string, error) {
+			out, err := client.Run("which mn")
+			if err != nil {
+				return "", fmt.Errorf("mn not found in remote PATH: %w", err)
+			}
+			return out, nil
+		}},
+	}
+	defer func() {
+		if client != nil {
+			client.Close()
+		}
+	}()
+
+	for _, step := range steps {
+		stepStart := time.Now()
+		out, err := step.run()
+		elapsed := time.Since(stepStart).Round(time.Millisecond)
+		if err != nil {
+			fmt.Printf("%s %s (%v)\n\t%v\n", omen.ErrorHeaderSty.Render("FAIL"), step.name, elapsed, err)
+			return errors.New("connection test failed")
+		}
+		if out = strings.TrimSpace(out); out != "" {
+			fmt.Printf("%s %s (%v): %s\n", omen.SuccessHeaderSty.Render("PASS"), step.name, elapsed, out)
+		} else {
+			fmt.Printf("%s %s (%v)\n", omen.SuccessHeaderSty.Render("PASS"), step.name, elapsed)
+		}
+	}
+	fmt.Printf("Connection test passed in %v\n", time.Since(start).Round(time.Millisecond))
+	return nil
+}
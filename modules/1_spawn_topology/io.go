@@ -1,127 +1,117 @@
 package main
 
 import (
-	"bufio"
+	omen "Omen"
+	"Omen/prompt"
+	"Omen/ssh"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
-
-	"golang.org/x/crypto/ssh"
 )
 
-func getInput(prompt string) string {
-	fmt.Print(prompt)
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
+// remoteResultsDir is the directory on the remote host into which the driver script writes its (timestamped) results.
+const remoteResultsDir string = "/tmp/test_results"
 
-	// auto add port if not provided
-	if strings.Contains(prompt, "Enter a valid target of the form '<host>:<port>':") {
-		if !strings.Contains(input, ":") {
-			fmt.Printf("No port detected -> Using default port 22\n")
-			input = strings.TrimSpace(input) + ":22"
-		}
-	}
-	return strings.TrimSpace(input)
-}
+// localResultsDir is the local directory into which each run's raw results are copied (as a timestamped subdirectory).
+const localResultsDir string = "./mn_result_raw"
 
-func uploadFile(client *ssh.Client, localPath, remotePath string) error {
-	// Read local file
-	localData, err := os.ReadFile(localPath)
-	if err != nil {
-		return fmt.Errorf("read local file: %w", err)
-	}
+// resultsCompleteMarker is the file the driver script writes into its results directory once every test has completed.
+const resultsCompleteMarker string = ".complete"
 
-	// Create remote file using SSH session
-	session, err := client.NewSession()
-	if err != nil {
-		return fmt.Errorf("create session: %w", err)
-	}
-	defer session.Close()
-
-	// Use cat command to write file content
-	stdin, err := session.StdinPipe()
-	if err != nil {
-		return fmt.Errorf("create stdin pipe: %w", err)
-	}
-
-	// Start the cat command to write to remote file
-	if err := session.Start(fmt.Sprintf("cat > %s", remotePath)); err != nil {
-		return fmt.Errorf("start cat command: %w", err)
-	}
-
-	// Write file content
-	if _, err := stdin.Write(localData); err != nil {
-		return fmt.Errorf("write file content: %w", err)
-	}
-	stdin.Close()
-
-	// Wait for completion
-	if err := session.Wait(); err != nil {
-		return fmt.Errorf("wait for upload: %w", err)
-	}
-
-	return nil
+// getInput prompts the user for a line of input.
+// Fails immediately, rather than blocking, if --interactive=false.
+func getInput(label string) (string, error) {
+	return prompt.Prompt(label)
 }
 
-// copyResultsFromVM copies the latest test results from /tmp/test_results on the VM to ./mn_result_raw locally
-func copyResultsFromVM(client *ssh.Client) error {
+// copyResultsFromVM copies the latest test results from /tmp/test_results on the VM to ./mn_result_raw locally.
+// Returns the local directory the results were copied into (empty if there were no results).
+func copyResultsFromVM(client *ssh.Client) (string, error) {
 	// Find the latest results directory
 	latestDir, err := findLatestResultsDir(client)
 	if err != nil {
-		return fmt.Errorf("find latest results directory: %w", err)
+		return "", fmt.Errorf("find latest results directory: %w", err)
 	}
 
 	if latestDir == "" {
-		fmt.Println("No test results found to copy")
-		return nil
+		infoln("No test results found to copy")
+		return "", nil
 	}
 
-	fmt.Printf("Found latest results directory: %s\n", latestDir)
+	infof("Found latest results directory: %s\n", latestDir)
 
-	// Extract timestamp from the remote directory path
-	timestamp := filepath.Base(latestDir)
-
-	// Create local results directory with timestamp subdirectory
-	localBaseDir := "./mn_result_raw"
-	localDir := filepath.Join(localBaseDir, timestamp)
-	if err := os.MkdirAll(localDir, 0755); err != nil {
-		return fmt.Errorf("create local directory %s: %w", localDir, err)
+	// Create local results directory named by the remote directory's timestamp
+	localDir, err := makeLocalResultsDir(filepath.Base(latestDir))
+	if err != nil {
+		return "", err
 	}
 
 	// Copy all files from the remote directory to local timestamped directory
 	if err := copyDirectoryContents(client, latestDir, localDir); err != nil {
-		return fmt.Errorf("copy directory contents: %w", err)
+		return "", fmt.Errorf("copy directory contents: %w", err)
 	}
 
-	fmt.Printf("Successfully copied test results to %s\n", localDir)
-	return nil
+	infof("Successfully copied test results to %s\n", localDir)
+	return localDir, nil
+}
+
+// makeLocalResultsDir creates the directory within localResultsDir that the results of the run named timestamp are copied into.
+func makeLocalResultsDir(timestamp string) (string, error) {
+	if err := os.MkdirAll(localResultsDir, 0755); err != nil {
+		return "", fmt.Errorf("create local directory %s: %w", localResultsDir, err)
+	}
+	localDir, err := makeUniqueDir(filepath.Join(localResultsDir, timestamp))
+	if err != nil {
+		return "", fmt.Errorf("create local results directory: %w", err)
+	}
+	return localDir, nil
 }
 
-// findLatestResultsDir finds the latest timestamped directory in /tmp/test_results
+// makeUniqueDir creates the directory base or, if base already exists, base_N for the lowest free N (starting at 1).
+// As the remote directory name only has second precision, this keeps successive runs from colliding locally.
+//
+// Returns the path to the created directory.
+func makeUniqueDir(base string) (string, error) {
+	dir := base
+	for n := 1; ; n++ {
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			return dir, nil
+		} else if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+		dir = fmt.Sprintf("%s_%d", base, n)
+	}
+}
+
+// findLatestResultsDir finds the latest timestamped directory (see omen.LatestRunDirName) in /tmp/test_results.
+// Returns an empty string if there are none.
 func findLatestResultsDir(client *ssh.Client) (string, error) {
-	baseDir := "/tmp/test_results"
+	baseDir := remoteResultsDir
 
-	// Check if base directory exists and get latest timestamped directory
-	cmd := fmt.Sprintf("[ -d %s ] && ls -1 %s | grep -E '^[0-9]{8}_[0-9]{6}$' | sort | tail -1", baseDir, baseDir)
-	output, err := runSSHCommand(client, cmd)
+	// Check if base directory exists and list its contents
+	cmd := fmt.Sprintf("[ -d %s ] && ls -1 %s", baseDir, baseDir)
+	output, err := client.Run(cmd)
 	if err != nil {
 		return "", fmt.Errorf("find latest directory: %w", err)
 	}
 
-	output = strings.TrimSpace(output)
-	if output == "" {
+	latest, ok := omen.LatestRunDirName(strings.Fields(output))
+	if !ok {
 		return "", nil // No timestamped directories found
 	}
 
-	return filepath.Join(baseDir, output), nil
+	return filepath.Join(baseDir, latest), nil
 }
 
 // copyDirectoryContents copies all files from remote directory to local directory
 func copyDirectoryContents(client *ssh.Client, remoteDir, localDir string) error {
 	// Get list of all files in the remote directory (recursively)
 	cmd := fmt.Sprintf("find %s -type f", remoteDir)
-	output, err := runSSHCommand(client, cmd)
+	output, err := client.Run(cmd)
 	if err != nil {
 		return fmt.Errorf("list files in %s: %w", remoteDir, err)
 	}
@@ -146,50 +136,11 @@ func copyDirectoryContents(client *ssh.Client, remoteDir, localDir string) error
 		}
 
 		// Copy file
-		if err := downloadFile(client, filePath, localPath); err != nil {
+		if err := client.Download(filePath, localPath); err != nil {
 			return fmt.Errorf("copy file %s: %w", filePath, err)
 		}
-		fmt.Printf("Copied: %s\n", relPath)
+		infof("Copied: %s\n", relPath)
 	}
 
 	return nil
 }
-
-// downloadFile downloads a single file from remote to local using SSH commands
-func downloadFile(client *ssh.Client, remotePath, localPath string) error {
-	// Create SSH session
-	session, err := client.NewSession()
-	if err != nil {
-		return fmt.Errorf("create session: %w", err)
-	}
-	defer session.Close()
-
-	// Get file content using cat
-	fileContent, err := session.Output(fmt.Sprintf("cat %s", remotePath))
-	if err != nil {
-		return fmt.Errorf("read remote file %s: %w", remotePath, err)
-	}
-
-	// Write to local file
-	if err := os.WriteFile(localPath, fileContent, 0644); err != nil {
-		return fmt.Errorf("write local file %s: %w", localPath, err)
-	}
-
-	return nil
-}
-
-// runSSHCommand runs a command on the remote server and returns the output
-func runSSHCommand(client *ssh.Client, command string) (string, error) {
-	session, err := client.NewSession()
-	if err != nil {
-		return "", fmt.Errorf("create session: %w", err)
-	}
-	defer session.Close()
-
-	output, err := session.Output(command)
-	if err != nil {
-		return "", fmt.Errorf("run command '%s': %w", command, err)
-	}
-
-	return string(output), nil
-}
@@ -0,0 +1,178 @@
+package main
+
+// This file implements --local, which runs the driver script against mininet on this machine rather than over SSH.
+
+import (
+	omen "Omen"
+	"Omen/modules/1_spawn_topology/models"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// runLocalMininet executes the driver script against the topology directly (via sudo), then copies its results into the local results directory.
+// It is the local counterpart of runRemoteMininet: nothing is uploaded, and the results directory is laid out identically.
+func runLocalMininet(config *models.Config) error {
+	if err := checkDriverScript(config.DriverScript); err != nil {
+		return err
+	}
+
+	if config.MNClean {
+		if err := runLocal(config, "mn", "-c"); err != nil {
+			return fmt.Errorf("mininet cleanup failed: %w", err)
+		}
+	}
+	args := append([]string{"python3", config.DriverScript, config.TopoJSONFile}, driverArgs(config)...)
+	for attempt := uint(0); ; attempt++ {
+		err := runLocal(config, args...)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, ErrTransientMininet) && attempt < config.RunRetries {
+			infof("-> %v; cleaning up and retrying (%d/%d)\n", err, attempt+1, config.RunRetries)
+			if err := runLocal(config, "mn", "-c"); err != nil {
+				return fmt.Errorf("mininet cleanup failed: %w", err)
+			}
+			continue
+		}
+		if config.PauseOnError {
+			pauseForDebugging(config)
+		}
+		return fmt.Errorf("mininet execution failed: %w", err)
+	}
+
+	infoln("-> Copying test results to local directory")
+	if localDir, err := copyLocalResults(); err != nil {
+		fmt.Printf("Warning: Failed to copy results: %v\n", err)
+		// Don't return error here as the main operation succeeded
+	} else if localDir != "" {
+		fmt.Println(omen.ResultsDirPrefix + localDir)
+	}
+	return nil
+}
+
+// runLocal runs the given command under sudo, echoing its output (unless --quiet) and returning ErrTransientMininet if
+// it failed and its output indicated a transient mininet failure.
+// sudo authenticates on the terminal itself; if --interactive=false, it fails rather than prompting.
+func runLocal(config *models.Config, args ...string) error {
+	if !config.Interactive {
+		args = append([]string{"-n"}, args...)
+	}
+	infof("-> Executing: sudo %s\n", strings.Join(args, " "))
+	cmd := exec.Command("sudo", args...)
+
+	var out io.Writer = os.Stdout
+	if quiet && !config.UseCLI { // the CLI is unusable without its output
+		out = io.Discard
+	}
+	watcher := &transientWatcher{}
+	cmd.Stdout = io.MultiWriter(out, watcher)
+	cmd.Stderr = cmd.Stdout
+	if config.UseCLI {
+		cmd.Stdin = os.Stdin
+	}
+
+	// the exit status is known locally, so a command that succeeded ran to completion
+	err := cmd.Run()
+	return mininetRunError(err, watcher.transient, err == nil)
+}
+
+// transientWatcher is an io.Writer that records whether any line written to it matches transientPattern.
+type transientWatcher struct {
+	partial   []byte // the trailing, incomplete line of prior writes
+	transient bool
+}
+
+func (w *transientWatcher) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		line, rest, found := bytes.Cut(w.partial, []byte("\n"))
+		if !found {
+			break
+		}
+		if transientPattern.Match(line) {
+			w.transient = true
+		}
+		w.partial = rest
+	}
+	return len(p), nil
+}
+
+// copyLocalResults copies the latest results directory the driver script wrote into remoteResultsDir (which, under --local,
+// is on this machine) into the local results directory, as copyResultsFromVM does for remote runs.
+// Returns the local directory the results were copied into (empty if there were no results).
+func copyLocalResults() (string, error) {
+	entries, err := os.ReadDir(remoteResultsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		infoln("No test results found to copy")
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("find latest results directory: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	latest, ok := omen.LatestRunDirName(names)
+	if !ok {
+		infoln("No test results found to copy")
+		return "", nil
+	}
+	latestDir := filepath.Join(remoteResultsDir, latest)
+	infof("Found latest results directory: %s\n", latestDir)
+
+	localDir, err := makeLocalResultsDir(latest)
+	if err != nil {
+		return "", err
+	}
+	err = filepath.WalkDir(latestDir, func(pth string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		relPath, err := filepath.Rel(latestDir, pth)
+		if err != nil {
+			return fmt.Errorf("calculate relative path: %w", err)
+		}
+		localPath := filepath.Join(localDir, relPath)
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			return fmt.Errorf("create local directory: %w", err)
+		}
+		if err := copyFile(pth, localPath); err != nil {
+			return fmt.Errorf("copy file %s: %w", pth, err)
+		}
+		infof("Copied: %s\n", relPath)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("copy directory contents: %w", err)
+	}
+
+	infof("Successfully copied test results to %s\n", localDir)
+	return localDir, nil
+}
+
+// copyFile copies the contents of the file at src into a new file at dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
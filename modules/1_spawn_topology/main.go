@@ -24,24 +24,30 @@ Mininet
 Python (3.11+)
 Sudo (required to run mininet)
 
-- Remote vm must also have an ssh server available for connection and superuser permissions (to run mininet).
+- Remote vm must also have an ssh server available for connection (with the SFTP subsystem enabled, as OpenSSH's is by default) and superuser permissions (to run mininet).
 */
 package main
 
 import (
 	omen "Omen"
+	inputschema "Omen/modules/0_input"
 	"Omen/modules/1_spawn_topology/models"
+	"Omen/prompt"
+	"Omen/ssh"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/netip"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/fang"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
 )
 
 var appName string = "test_runner"
@@ -55,32 +61,78 @@ var (
 	defaultPythonScript = "mininet-script.py" // default python script filename
 )
 
+// driverScriptEnv names the environment variable that overrides the default local path of the driver script.
+// --driver-script takes precedence over it.
+const driverScriptEnv string = "OMEN_DRIVER_SCRIPT"
+
+// identityPassphraseEnv names the environment variable holding the passphrase of the --identity key, if it is encrypted.
+// If it is unset, the passphrase is prompted for (if --interactive).
+const identityPassphraseEnv string = "OMEN_IDENTITY_PASSPHRASE"
+
+// jumpPasswordEnv names the environment variable holding the password of the --jump host.
+// If it is unset, the password is prompted for (if --interactive).
+const jumpPasswordEnv string = "OMEN_JUMP_PASSWORD"
+
 // main application info.
 // Constructed from args and flags
 var (
 	config = models.Config{
 		TopoFile: defaultTopoFile,
 	}
-	inputTopo *models.Input
+	inputTopo   *models.Input
+	printConfig bool // print the resolved config as JSON and exit
+	quiet       bool // suppress informational output
 )
 
+// infoOut returns where informational output and warnings are written: stdout or, if --print-config, stderr
+// (so stdout holds only the JSON).
+func infoOut() io.Writer {
+	if printConfig {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// infof prints informational output, unless --quiet was given.
+// Errors, prompts, and final results should be printed directly instead.
+func infof(format string, a ...any) {
+	if !quiet {
+		fmt.Fprintf(infoOut(), format, a...)
+	}
+}
+
+// infoln is the Println form of infof.
+func infoln(a ...any) {
+	if !quiet {
+		fmt.Fprintln(infoOut(), a...)
+	}
+}
+
 // resolveConfig is responsible for finalizing and error-checking the global config singleton hierarchically.
 //
 // Hierarchical priority: command line flags > JSON file > hardcoded defaults > user input
 func resolveConfig() error {
+	if config.Local { // nothing to connect to; sudo authenticates on its own
+		infoln("Running against the local mininet (--local); skipping SSH configuration")
+		return nil
+	}
+
 	// Resolve username
 	if config.Username == "" {
 		if inputTopo.Username != "" {
 			config.Username = inputTopo.Username
-			fmt.Printf("Using username from JSON: %s\n", config.Username)
+			infof("Using username from JSON: %s\n", config.Username)
 		} else if defaultUsername != "" {
 			config.Username = defaultUsername
-			fmt.Printf("Using hardcoded username: %s\n", config.Username)
+			infof("Using hardcoded username: %s\n", config.Username)
 		} else if config.Interactive {
-			config.Username = getInput("Enter username: ")
+			var err error
+			if config.Username, err = getInput("Enter username: "); err != nil {
+				return err
+			}
 		}
 	} else {
-		fmt.Printf("Using username from --remote flag: %s\n", config.Username)
+		infof("Using username from --remote flag: %s\n", config.Username)
 	}
 
 	if config.Username == "" {
@@ -88,132 +140,218 @@ func resolveConfig() error {
 	}
 
 	// Resolve host
-	config.Host = func() netip.AddrPort {
-		// if it was set by cli, we are done
-		if config.Host.IsValid() {
-			fmt.Printf("Using host from --remote flag: %v\n", config.Host)
-			return config.Host
-		}
-
+	if config.Host.IsValid() { // if it was set by cli, we are done
+		infof("Using host from --remote flag: %v\n", config.Host)
+	} else {
 		// Pull VM address from input JSON
-		// Check if default port exists
-		if inputTopo.AP != "" && !strings.Contains(inputTopo.AP, ":") {
-			fmt.Printf("No port detected -> Using default port 22\n")
-			inputTopo.AP = inputTopo.AP + ":22"
-		}
-		if ap, err := netip.ParseAddrPort(inputTopo.AP); err == nil {
-			fmt.Printf("Using host from JSON: %v\n", ap)
-			return ap
-		}
-
-		// Pull hosts from input JSON
-		if ap, err := netip.ParseAddrPort(defaultHost); err == nil {
-			fmt.Printf("Using hardcoded host: %v\n", ap)
-			return ap
-		}
-
-		if config.Interactive {
-			// pull from stdin
-			var ap netip.AddrPort
-			var err error
-			for ap, err = netip.ParseAddrPort(getInput("Enter a valid target of the form '<host>:<port>':")); err != nil; {
+		if addr, defaulted, err := models.ParseAddrPort(inputTopo.Address); err == nil {
+			if defaulted {
+				infof("No port detected -> Using default port %d\n", models.DefaultSSHPort)
+			}
+			infof("Using host from JSON: %v\n", addr)
+			config.Host = addr
+		} else if addr, _, err := models.ParseAddrPort(defaultHost); err == nil { // Pull hosts from hardcoded default
+			infof("Using hardcoded host: %v\n", addr)
+			config.Host = addr
+		} else if config.Interactive {
+			// pull from stdin until a valid target is given
+			for !config.Host.IsValid() {
+				input, err := getInput("Enter a valid target of the form '<host>[:<port>]': ")
+				if err != nil {
+					return err
+				}
+				var defaulted bool
+				if config.Host, defaulted, err = models.ParseAddrPort(input); err != nil {
+					fmt.Println(err)
+				} else if defaulted {
+					infof("No port detected -> Using default port %d\n", models.DefaultSSHPort)
+				}
 			}
-			return ap
 		}
-		return netip.AddrPort{}
-	}()
+	}
 	if !config.Host.IsValid() {
 		return errors.New("a valid host/target must be supplied")
 	}
 
+	// Resolve the private key, if one was given
+	if config.PrivateKeyPath != "" {
+		if err := resolvePrivateKey(); err != nil {
+			return err
+		}
+	}
+
 	// Resolve password
-	if config.Password == "" {
+	// With a private key, the password is only sent to sudo, so it may be left empty (if sudo is passwordless),
+	// and is not needed at all for --dry-run, which runs nothing under sudo
+	if config.Password == "" && !(config.DryRun && config.PrivateKeyPath != "") {
 		if inputTopo.Password != "" {
 			config.Password = inputTopo.Password
-			fmt.Println("Using password from JSON: [hidden]")
+			infoln("Using password from JSON: [hidden]")
 		} else if defaultPassword != "" {
 			config.Password = defaultPassword
-			fmt.Println("Using hardcoded password: [hidden]")
+			infoln("Using hardcoded password: [hidden]")
 		} else if config.Interactive {
-			config.Password = getInput("Enter password (SSH/sudo): ")
+			label := "Enter password (SSH/sudo): "
+			if config.PrivateKeyPath != "" {
+				label = "Enter sudo password (leave empty if sudo is passwordless): "
+			}
+			var err error
+			if config.Password, err = prompt.PromptSecret(label); err != nil {
+				return err
+			}
 		}
 	}
 
 	// Validate required fields
-	if config.Username == "" || !config.Host.IsValid() || config.Password == "" {
-		return fmt.Errorf("username, host, and password are required")
+	if config.Username == "" || !config.Host.IsValid() || (config.Password == "" && config.PrivateKeyPath == "") {
+		return fmt.Errorf("username, host, and password (or --identity) are required")
+	}
+
+	// Resolve the jump host's password; with --identity, the key authenticates the jump host unless a password is set
+	if config.Jump != nil && config.Jump.Password == "" {
+		if v := os.Getenv(jumpPasswordEnv); v != "" {
+			config.Jump.Password = v
+			infof("Using jump host password from $%s: [hidden]\n", jumpPasswordEnv)
+		} else if config.PrivateKeyPath != "" {
+			infof("Using private key for jump host %v\n", config.Jump)
+		} else if config.Interactive {
+			var err error
+			if config.Jump.Password, err = prompt.PromptSecret("Enter password for jump host " + config.Jump.String() + ": "); err != nil {
+				return err
+			}
+		}
+		if config.Jump.Password == "" && config.PrivateKeyPath == "" {
+			return fmt.Errorf("a password (or --identity) for jump host %v is required (set $%s)", config.Jump, jumpPasswordEnv)
+		}
 	}
 
 	return nil
 }
 
+// resolvePrivateKey checks that the --identity key can be loaded, resolving its passphrase (if it is encrypted and none was given)
+// from $identityPassphraseEnv or, if --interactive, a prompt.
+func resolvePrivateKey() error {
+	if config.PrivateKeyPassphrase == "" {
+		if v := os.Getenv(identityPassphraseEnv); v != "" {
+			config.PrivateKeyPassphrase = v
+			infof("Using private key passphrase from $%s: [hidden]\n", identityPassphraseEnv)
+		}
+	}
+	_, err := ssh.LoadPrivateKey(config.PrivateKeyPath, config.PrivateKeyPassphrase)
+	if errors.Is(err, ssh.ErrPassphraseMissing) && config.Interactive {
+		if config.PrivateKeyPassphrase, err = prompt.PromptSecret("Enter passphrase for key " + config.PrivateKeyPath + ": "); err != nil {
+			return err
+		}
+		_, err = ssh.LoadPrivateKey(config.PrivateKeyPath, config.PrivateKeyPassphrase)
+	}
+	if errors.Is(err, ssh.ErrPassphraseMissing) {
+		return fmt.Errorf("%w (set $%s)", err, identityPassphraseEnv)
+	} else if err != nil {
+		return fmt.Errorf("--identity: %w", err)
+	}
+	infof("Using private key: %s\n", config.PrivateKeyPath)
+	return nil
+}
+
 func main() {
 	// define flags
 	fs := pflag.FlagSet{}
 	fs.Bool("help", false, "Tada!")
-	fs.String("remote", "", "remote target to run on, e.g. username@192.168.64.5")
+	fs.String("remote", "", "remote target to run on, of the form <user>@<ip>[:<port>] (ex: username@192.168.64.5 or username@[2001:db8::1]:22). Port 22 is assumed if none is given")
 	fs.BoolVar(&config.UseCLI, "cli", false, "enter Mininet CLI instead of running pingall. Do not use with interactivity is disabled.")
+	driverScript := defaultPythonScript
+	if v := strings.TrimSpace(os.Getenv(driverScriptEnv)); v != "" {
+		driverScript = v
+	}
+	fs.StringVar(&config.DriverScript, "driver-script", driverScript, "local path of the driver script to upload. "+
+		"Defaults to $"+driverScriptEnv+", if set, or "+defaultPythonScript+" in the current directory")
 	fs.StringVar(&config.RemotePathPython, "remote-path-python", "/tmp/"+defaultPythonScript, "remote path for the generated Python file")
 	fs.StringVar(&config.RemotePathJSON, "remote-path-json", "/tmp/"+defaultTopoFile, "remote path for the generated JSON file")
 	fs.BoolVar(&config.Interactive, "interactive", true, "enables prompting for missing information."+
 		"If false, this module will fail out on missing information rather than prompting for it.")
+	fs.BoolVarP(&config.AssumeYes, "assume-yes", "y", false, "answer every confirmation (ex: overwriting a file) with yes rather than prompting, and do not pause (ex: --pause-on-error). "+
+		"Unlike --interactive=false, which fails rather than prompting, this proceeds. Prompts for missing information are unaffected")
+	fs.BoolVar(&config.PauseOnError, "pause-on-error", false, "if mininet fails, print the remote connection details and wait for enter before disconnecting."+
+		" Leaves the remote state intact for debugging.")
+	fs.BoolVar(&config.MNClean, "mn-clean", false, "run sudo mn -c on the remote to clear state left by prior runs before running the topology")
+	fs.UintVar(&config.RunRetries, "run-retries", 0, "number of times to run sudo mn -c and retry if mininet fails with a transient error (ex: RTNETLINK or resource busy)")
+	fs.DurationVar(&config.InactivityTimeout, "inactivity-timeout", 0, "fail the run if the remote session outputs nothing for this long (ex: 5m), reporting the last output seen. "+
+		"Ignored with --cli. 0 disables the timeout")
+	fs.DurationVar(&config.SessionTimeout, "session-timeout", 0, "fail the run if the remote session has not completed within this long (ex: 5m), after asking the remote shell to exit. "+
+		"Ignored with --cli. 0 disables the timeout")
+	fs.StringVar(&config.OutputLog, "output-log", "", "append every line the remote session outputs (password redacted) to this local file, timestamped per line. "+
+		"Lines are written as they arrive, so the log is usable even if this process is killed")
+	fs.StringVar(&config.SudoPromptPattern, "sudo-prompt-pattern", "", "regexp matching the remote's sudo password prompt (ex: 'Passwort für'), for non-English locales or a custom passprompt. "+
+		"Replaces the built-in English heuristics; empty keeps them")
+	fs.BoolVar(&config.ReconnectOnDrop, "reconnect-on-drop", false, "if the connection drops (or goes inactive) mid-run, reconnect and collect the results if the remote run completed regardless. "+
+		"Completion is judged by the marker the driver script writes into its results directory")
+	fs.BoolVar(&config.Local, "local", false, "run the driver script against mininet on this machine (via sudo) rather than connecting over SSH. "+
+		"Nothing is uploaded, and the SSH username, host, and password are neither resolved nor required. Results are copied into the same local directory")
+	fs.String("jump", "", "bastion to tunnel the connection to the remote through, of the form <user>@<host>[:<port>] (ex: me@bastion.example.edu), as ssh's ProxyJump does. "+
+		"It is authenticated with the --identity key if one is given (and $"+jumpPasswordEnv+" is unset); otherwise, its password is read from $"+jumpPasswordEnv+" or prompted for")
+	home, _ := os.UserHomeDir()
+	fs.StringVar(&config.KnownHostsFile, "known-hosts", filepath.Join(home, ".ssh", "known_hosts"), "known_hosts file to verify the keys of the remote (and --jump host) against. Created if it does not exist. "+
+		"If --interactive, the keys of unknown hosts are shown and, if accepted, appended to it; otherwise, unknown hosts are rejected")
+	fs.StringVarP(&config.PrivateKeyPath, "identity", "i", "", "private key to authenticate with (ex: ~/.ssh/id_ed25519) in place of the password, as ssh -i does. "+
+		"The password is still sent to sudo. If the key is encrypted, its passphrase is read from $"+identityPassphraseEnv+" or prompted for")
+	fs.UintVar(&config.ConnectRetries, "connect-retries", 3, "number of times to retry connecting if the connection is refused, reset, or times out (ex: as a freshly booted VM's sshd is not yet listening). "+
+		"Authentication and host key failures are never retried")
+	fs.DurationVar(&config.ConnectRetryDelay, "connect-retry-delay", 2*time.Second, "delay before the first connection retry; doubled before each subsequent one")
+	fs.BoolVar(&config.InsecureHostKey, "insecure-host-key", false, "accept any host key without verifying it against --known-hosts. "+
+		"Leaves the connection (and the sudo password sent over it) open to interception; only use on trusted lab networks")
+	fs.UintVar(&config.Repetitions, "repetitions", 1, "number of times the driver script runs the pingall matrix each timeframe. "+
+		"Coalesce Output aggregates repeated pings into ping_stats.csv")
+	fs.StringArrayVar(&config.MNArgs, "mn-arg", nil, "extra argument to pass to the driver script (ex: --mn-arg=--seed=42). May be repeated; each value is passed as a single, quoted argument")
+	fs.BoolVar(&config.DryRun, "dry-run", false, "connect and upload the driver script and topology, then print the command that would run them and disconnect without running mininet. "+
+		"Checks the connection, credentials, and uploads without needing sudo")
+	fs.BoolVarP(&quiet, "quiet", "q", false, "suppress informational output (including the remote session's unless --cli), printing only errors and the results directory")
+	fs.BoolVar(&printConfig, "print-config", false, "print the resolved configuration as JSON (password redacted) and exit without connecting. "+
+		"Informational output and warnings are written to stderr, so stdout holds only the JSON")
 	fs.MarkHidden("cli")
 
 	// generate command "tree"
 	root := &cobra.Command{
-		Use:   appName + " <topo>.json",
+		Use:   appName + " <topo>.(json|yaml)",
 		Short: appName + " drives the testing and remote connection functionality of Omen",
-		Long: appName + " creates and runs Mininet topologies from JSON files on remote VMs." +
+		Long: appName + " creates and runs Mininet topologies from JSON (or YAML) files on remote VMs. " +
 			"It handles SSH connections, uploads topology scripts, manages Mininet sessions, and collects raw output." +
 			"If --interactive, " + appName + " will prompt for required inputs not supplied in the topology JSON.",
 		Example: appName + " input.json\n" +
-			appName + " --remote=wifi@127.0.0.1 --interactive=false input.json",
+			appName + " --remote=wifi@127.0.0.1 --interactive=false input.json\n" +
+			appName + " input.yaml\n" +
+			appName + " --print-config --interactive=false input.json\n" +
+			appName + " --dry-run input.json",
 		Args: cobra.ExactArgs(1),
 
-		PreRunE: func(cmd *cobra.Command, args []string) error {
-			// Sets SSH information if --remote was specified.
-			remote, err := cmd.Flags().GetString("remote")
-			if err != nil {
-				return err
-			}
-
-			if remote = strings.TrimSpace(remote); remote != "" {
-				parts := strings.Split(remote, "@")
-				if len(parts) != 2 {
-					return fmt.Errorf("invalid remote format, expected username@host")
-				}
-				config.Username = parts[0]
-				config.Host, _ = netip.ParseAddrPort(parts[1]) // throw away error; validity is checked later
-			}
-
-			{ // slurp topology
-				if args[0] = strings.TrimSpace(args[0]); args[0] != "" {
-					config.TopoFile = args[0]
-				}
-				fmt.Printf("Loading topology from: %s\n", config.TopoFile)
-				data, err := os.ReadFile(config.TopoFile)
-				if err != nil {
-					return fmt.Errorf("read topo file: %w", err)
-				}
-
-				if err := json.Unmarshal(data, &inputTopo); err != nil {
-					return fmt.Errorf("parse topology JSON: %w", err)
-				}
-			}
-
-			// validate config set from flags
-			return resolveConfig()
+		// guard the readers themselves, so nothing can block on stdin when non-interactive
+		PersistentPreRun: func(*cobra.Command, []string) {
+			prompt.SetInteractive(config.Interactive)
+			prompt.SetAssumeYes(config.AssumeYes)
 		},
-		RunE: run,
+		PreRunE: loadConfig,
+		RunE:    run,
 	}
 
 	// attach flags
-	root.Flags().AddFlagSet(&fs)
+	// --remote, --jump, --identity, and --interactive are required to resolve the config and, like --assume-yes, govern the prompts of every subcommand,
+	// so they are shared with subcommands (as are the host key and retry flags, which every connection needs)
+	shared := map[string]bool{"remote": true, "jump": true, "identity": true, "interactive": true, "assume-yes": true,
+		"known-hosts": true, "insecure-host-key": true, "connect-retries": true, "connect-retry-delay": true}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if shared[f.Name] {
+			root.PersistentFlags().AddFlag(f)
+		} else {
+			root.Flags().AddFlag(f)
+		}
+	})
+	root.AddCommand(newTestConnectionCommand(), newSampleCommand(), newSanitizeCommand())
+	omen.AttachJSONVersion(root)
 
 	if err := fang.Execute(context.Background(),
 		root,
 		fang.WithoutCompletions(),
 		fang.WithVersion(omen.Version),
+		fang.WithCommit(omen.Commit),
 		fang.WithErrorHandler(omen.FangErrorHandler),
 	); err != nil {
 		os.Exit(1)
@@ -221,14 +359,170 @@ func main() {
 
 }
 
+// loadConfig slurps the topology file given in args and resolves the global config from it and the flags on cmd.
+func loadConfig(cmd *cobra.Command, args []string) error {
+	// Sets SSH information if --remote was specified.
+	remote, err := cmd.Flags().GetString("remote")
+	if err != nil {
+		return err
+	}
+
+	if remote = strings.TrimSpace(remote); remote != "" {
+		var defaulted bool
+		if config.Username, config.Host, defaulted, err = models.ParseRemote(remote); err != nil {
+			return err
+		} else if defaulted {
+			infof("No port given in --remote -> Using default port %d\n", models.DefaultSSHPort)
+		}
+	}
+
+	jump, err := cmd.Flags().GetString("jump")
+	if err != nil {
+		return err
+	}
+	if jump = strings.TrimSpace(jump); jump != "" {
+		if config.Jump, err = models.ParseJumpHost(jump); err != nil {
+			return err
+		}
+	}
+
+	if config.Local {
+		if remote != "" {
+			return errors.New("--remote cannot be combined with --local")
+		} else if config.Jump != nil {
+			return errors.New("--jump cannot be combined with --local")
+		} else if config.ReconnectOnDrop {
+			return errors.New("--reconnect-on-drop cannot be combined with --local, which has no connection to drop")
+		} else if config.PrivateKeyPath != "" {
+			return errors.New("--identity cannot be combined with --local")
+		} else if config.OutputLog != "" {
+			return errors.New("--output-log cannot be combined with --local, which has no remote session to log")
+		} else if config.DryRun {
+			return errors.New("--dry-run cannot be combined with --local, which has nothing to upload")
+		}
+	}
+
+	if _, err := sudoPromptPattern(&config); err != nil {
+		return err
+	}
+	if err := validateMNArgs(config.MNArgs); err != nil {
+		return err
+	}
+	if config.Repetitions == 0 {
+		return errors.New("--repetitions must be at least 1")
+	}
+
+	var converted []byte // JSON form of a YAML topology, written out once the config is resolved
+	{ // slurp topology
+		if args[0] = strings.TrimSpace(args[0]); args[0] != "" {
+			config.TopoFile = args[0]
+		}
+		infof("Loading topology from: %s\n", config.TopoFile)
+		data, err := os.ReadFile(config.TopoFile)
+		if err != nil {
+			return fmt.Errorf("read topo file: %w", err)
+		}
+
+		// YAML topologies are converted to JSON so the same struct tags apply (and the driver script receives JSON).
+		// The converted form is validated in memory and only written out once every check passes, as it may hold credentials.
+		config.TopoJSONFile = config.TopoFile
+		if isYAMLPath(config.TopoFile) {
+			if data, err = yaml.YAMLToJSON(data); err != nil {
+				return fmt.Errorf("parse topology YAML: %w", err)
+			}
+			converted = data
+		}
+
+		// check against the schema the input validator uses, so inputs cannot pass one and fail the other
+		if err := inputschema.Validate(data); err != nil {
+			return fmt.Errorf("invalid topology: %w", err)
+		}
+		if err := json.Unmarshal(data, &inputTopo); err != nil {
+			return fmt.Errorf("parse topology JSON: %w", err)
+		}
+		warnings, err := inputTopo.Validate()
+		if err != nil {
+			return fmt.Errorf("invalid topology:\n%w", err)
+		}
+		for _, w := range warnings {
+			fmt.Fprintf(infoOut(), "Warning: %s\n", w)
+		}
+	}
+
+	// validate config set from flags
+	if err := resolveConfig(); err != nil {
+		return err
+	}
+	if converted != nil {
+		if config.TopoJSONFile, err = writeTempJSON(converted); err != nil {
+			return fmt.Errorf("write converted topology: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeTempJSON writes data to a new temporary file, returning its path.
+func writeTempJSON(data []byte) (string, error) {
+	f, err := os.CreateTemp("", "omen-topo-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// printResolvedConfig writes the resolved config to w as indented JSON, with the password redacted.
+func printResolvedConfig(w io.Writer) error {
+	redacted := config
+	if redacted.Password != "" {
+		redacted.Password = "[hidden]"
+	}
+	if redacted.PrivateKeyPassphrase != "" {
+		redacted.PrivateKeyPassphrase = "[hidden]"
+	}
+	if redacted.Jump != nil && redacted.Jump.Password != "" {
+		jump := *redacted.Jump
+		jump.Password = "[hidden]"
+		redacted.Jump = &jump
+	}
+	out, err := json.MarshalIndent(redacted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
 // run is the primary driver application.
 // Expects the topology and all configuration to be valid.
 func run(cmd *cobra.Command, args []string) error {
+	if config.TopoJSONFile != config.TopoFile { // clean up the topology converted from YAML
+		defer os.Remove(config.TopoJSONFile)
+	}
+	if printConfig {
+		return printResolvedConfig(cmd.OutOrStdout())
+	}
+
 	// Display final configuration
-	fmt.Printf("\n"+`Final Configuration:
-	Host               : `+config.Host.String()+`
+	host := config.Host.String()
+	identity := "none (password authentication)"
+	if config.PrivateKeyPath != "" {
+		identity = config.PrivateKeyPath
+	}
+	if config.Local {
+		host = "local (--local)"
+	} else if config.Jump != nil {
+		host += " (via " + config.Jump.String() + ")"
+	}
+	infof("\n"+`Final Configuration:
+	Host               : `+host+`
 	Username           : `+config.Username+`
 	Password           : [hidden]
+	Identity           : `+identity+`
 	Topology File      : `+config.TopoFile+`
 	Py Script          : %s
 	Mode               : %s
@@ -239,7 +533,7 @@ func run(cmd *cobra.Command, args []string) error {
 	Switches           : %v
 	Aps                : %v
 	Links              : %v`+"\n",
-		defaultPythonScript,
+		config.DriverScript,
 		map[bool]string{true: "Interactive CLI", false: "Automated pingall"}[config.UseCLI],
 		config.RemotePathPython,
 		config.RemotePathJSON,
@@ -249,11 +543,15 @@ func run(cmd *cobra.Command, args []string) error {
 		inputTopo.Topo.Aps,
 		inputTopo.Topo.Links)
 
-	// Execute the remote Mininet session
-	if err := runRemoteMininet(&config, defaultPythonScript); err != nil {
+	// Execute the Mininet session
+	if config.Local {
+		if err := runLocalMininet(&config); err != nil {
+			return fmt.Errorf("ERROR: run local mininet: %w", err)
+		}
+	} else if err := runRemoteMininet(&config); err != nil {
 		return fmt.Errorf("ERROR: run remote mininet: %w", err)
 	}
 
-	fmt.Println("Program completed successfully!")
+	infoln("Program completed successfully!")
 	return nil
 }
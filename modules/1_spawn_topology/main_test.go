@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Omen/modules/1_spawn_topology/models"
+
+	"github.com/spf13/cobra"
+)
+
+// TestPrintConfigIsJSON ensures nothing but the JSON is written to stdout under --print-config, including the informational output
+// (ex: "Loading topology from:") and warnings of loading the config.
+func TestPrintConfigIsJSON(t *testing.T) {
+	oldConfig, oldPrintConfig, oldStdout := config, printConfig, os.Stdout
+	t.Cleanup(func() { config, printConfig, os.Stdout = oldConfig, oldPrintConfig, oldStdout })
+	config = models.Config{Password: "secret", Repetitions: 1}
+	printConfig = true
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	stdout := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		stdout <- out
+	}()
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("remote", "wifi@192.168.64.5", "")
+	cmd.Flags().String("jump", "", "")
+	if err := loadConfig(cmd, []string{"input-topo.json"}); err != nil {
+		t.Fatalf("loadConfig() = %v", err)
+	}
+	if err := printResolvedConfig(os.Stdout); err != nil {
+		t.Fatalf("printResolvedConfig() = %v", err)
+	}
+	w.Close()
+	out := <-stdout
+
+	var got models.Config
+	dec := json.NewDecoder(bytes.NewReader(out))
+	if err := dec.Decode(&got); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, out)
+	} else if dec.More() {
+		t.Fatalf("stdout holds more than the JSON:\n%s", out)
+	}
+	if got.Username != "wifi" || got.Password != "[hidden]" {
+		t.Errorf("printed config has username %q and password %q, want %q and %q", got.Username, got.Password, "wifi", "[hidden]")
+	}
+}
+
+// TestLoadConfigYAMLTempFile ensures the JSON form of a YAML topology (which may hold credentials) is only written once the config
+// resolves, so failing to load it leaves nothing behind in $TMPDIR.
+func TestLoadConfigYAMLTempFile(t *testing.T) {
+	oldConfig, oldStdout := config, os.Stdout
+	t.Cleanup(func() { config, os.Stdout = oldConfig, oldStdout })
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Stdout = devNull
+
+	data, err := os.ReadFile("input-topo.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	topo := filepath.Join(t.TempDir(), "topo.yaml") // JSON is valid YAML
+	if err := os.WriteFile(topo, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	for _, remote := range []string{"", "wifi@192.168.64.5"} { // without a username, the config cannot resolve
+		config = models.Config{Password: "secret", Repetitions: 1}
+		cmd := &cobra.Command{}
+		cmd.Flags().String("remote", remote, "")
+		cmd.Flags().String("jump", "", "")
+		err := loadConfig(cmd, []string{topo})
+
+		written, _ := filepath.Glob(filepath.Join(tmp, "omen-topo-*.json"))
+		if remote == "" {
+			if err == nil {
+				t.Fatal("loadConfig() without a username succeeded")
+			} else if len(written) != 0 {
+				t.Errorf("loadConfig() failed (%v) but left %v behind", err, written)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("loadConfig() = %v", err)
+		} else if len(written) != 1 || config.TopoJSONFile != written[0] {
+			t.Errorf("loadConfig() wrote %v, want only TopoJSONFile (%s)", written, config.TopoJSONFile)
+		}
+	}
+}
@@ -29,14 +29,19 @@ Expected JSON:
 
 import sys, json
 import os
+import subprocess
 import time
-from datetime import datetime
+from datetime import datetime, timezone
 from mininet.log import setLogLevel, info, error
 from mn_wifi.net import Mininet_wifi
 from mn_wifi.cli import CLI
 from mn_wifi.link import wmediumd
 from mn_wifi.wmediumdConnector import interference
 
+# Version of the raw timeframeX.txt format, declared on the first line of each file ("[format] N").
+# The output processing module selects its parsing patterns by it, so bump it (and add the version there) whenever the tags change.
+RAW_FORMAT_VERSION = 1
+
 def make_results_dir():
     """
     Create a new results directory under /tmp/test_results named by the current timestamp.
@@ -125,9 +130,26 @@ def build_from_spec(spec):
 
     return net, sta_objs, ap_objs
 
-def run_pingall_full(all_nodes, count=1, test_name="pingall_full"):
+def format_position(pos):
+    """
+    Format a node position as the bracketed list raw format version 1 requires (e.g., [70.0, 10.0, 0.0]).
+    Positions may be lists (as set by mininet-wifi) or "x,y,z" strings (as given in the JSON); others are returned as-is.
     """
-    Run a full pairwise ping matrix test between all nodes.
+    if isinstance(pos, str):
+        try:
+            pos = [float(c) for c in pos.strip("[] ").split(",")]
+        except ValueError:
+            return pos
+    try:
+        return "[{}]".format(", ".join(str(float(c)) for c in pos))
+    except (TypeError, ValueError):
+        return str(pos)
+
+def run_pingall_full(all_nodes, count=1, test_name="pingall_full", repetitions=1):
+    """
+    Run a full pairwise ping matrix test between all nodes, `repetitions` times.
+    Each repetition is its own [pingall_full] section, so the output processor
+    can check (and aggregate) each matrix independently.
     Returns the formatted output string with CSV-style results.
     """
     msg = ""
@@ -136,12 +158,26 @@ def run_pingall_full(all_nodes, count=1, test_name="pingall_full"):
     info(f"*** Node Movement output: Timeframe {test_name} ***\n")
     for node in all_nodes:
         # Get the position using the position attribute or params dict
-        current_pos = getattr(node, 'position', node.params.get('position', 'unknown'))
+        current_pos = format_position(getattr(node, 'position', node.params.get('position', 'unknown')))
         info(f"Node {node.name} position before pingall_full: {current_pos}\n")
         msg += f"\n[node movements] {test_name}: move {node.name}: moving {node.name} -> {current_pos}\n"
         msg += f"Moved {node.name} to {current_pos}\n"
     
-    msg += f"\n[pingall_full] {test_name}: pairwise matrix (-c {count})\n"
+    lines = [msg]
+    for rep in range(1, repetitions + 1):
+        lines.append(run_pingall_matrix(all_nodes, count, test_name, rep, repetitions))
+    return "".join(lines)
+
+def run_pingall_matrix(all_nodes, count, test_name, rep, repetitions):
+    """
+    Run a single repetition of the pairwise ping matrix.
+    Returns the [pingall_full] section, with its CSV-style results.
+    """
+    # the node count lets the output processor tell a truncated matrix from failed links
+    msg = f"\n[pingall_full] {test_name}: pairwise matrix (-c {count}) across {len(all_nodes)} nodes"
+    if repetitions > 1:
+        msg += f" (repetition {rep}/{repetitions})"
+    msg += "\n"
     info(msg)
     header = "src,dst,tx,rx,loss_pct,avg_rtt_ms\n"
     lines = [msg, header]
@@ -218,12 +254,63 @@ def run_iw_stations(sta_objs, ap_objs, cmd, test_name="iw_stations"):
     lines.append("=" * 60 + "\n")
     return "".join(lines)
 
-def run_tests(sta_objs, ap_objs, spec, tests, results_dir):
+def get_associations(sta_objs):
+    """
+    Map each station's name to the name of the AP it is currently associated with
+    (None if the station is not associated).
+    """
+    assoc = {}
+    for sta in sta_objs.values():
+        ap_intf = sta.wintfs[0].associatedTo if sta.wintfs else None
+        assoc[sta.name] = ap_intf.node.name if ap_intf else None
+    return assoc
+
+def run_associations(prev, cur, test_name="associations"):
+    """
+    Compare two association snapshots (see get_associations).
+    Returns the formatted output string with one line per association/disassociation event.
+    """
+    msg = f"\n[associations] {test_name}: association events\n"
+    info(msg)
+    lines = [msg]
+    for name, ap in cur.items():
+        old = prev.get(name)
+        if old == ap:
+            continue
+        if old:
+            lines.append(f"{name} disassociated from {old}\n")
+        if ap:
+            lines.append(f"{name} associated with {ap}\n")
+    return "".join(lines)
+
+def run_resources(all_nodes, test_name="resources"):
+    """
+    Sample the CPU and memory usage of each node's shell process.
+    Returns the formatted output string with CSV-style results. Unavailable values are "?".
+    """
+    msg = f"\n[resources] {test_name}: per-node resource usage\n"
+    info(msg)
+    lines = [msg, "node,pid,cpu_pct,rss_kb\n"]
+    for node in all_nodes:
+        pid = getattr(node, "pid", None)
+        cpu = rss = "?"
+        if pid:
+            try:
+                fields = subprocess.run(["ps", "-o", "%cpu=,rss=", "-p", str(pid)],
+                                        capture_output=True, text=True).stdout.split()
+                if len(fields) == 2:
+                    cpu, rss = fields
+            except Exception:
+                pass
+        lines.append(f"{node.name},{pid or '?'},{cpu},{rss}\n")
+    return "".join(lines)
+
+def run_tests(sta_objs, ap_objs, spec, tests, results_dir, repetitions=1):
     """
     Run all tests defined in 'tests' and save results by timeframe.
 
     Supports ping tests and node movements. After each timeframe, runs
-    `pingall_full` and `iw` checks on all nodes. Each timeframe's output 
+    `pingall_full` (`repetitions` times), resource, association, and `iw` checks on all nodes. Each timeframe's output 
     is written to `timeframeX.txt` in `results_dir`.
     """
 
@@ -242,10 +329,14 @@ def run_tests(sta_objs, ap_objs, spec, tests, results_dir):
             tests_by_timeframe.append([])
         tests_by_timeframe[cur_timeframe].append(t)
 
+    # stations start unassociated, so the first timeframe reports the initial associations
+    associations = {}
+
     for timeframe, sub_tests in enumerate(tests_by_timeframe):
         outfile = os.path.join(results_dir, f"timeframe{timeframe}.txt")
         info(f"Tests in timeframe{timeframe}:\n")
-        out = ""
+        out = "[format] {}\n".format(RAW_FORMAT_VERSION)
+        settle_ms = 0 # longest settle time requested by this timeframe's movements
 
         for sub_t in sub_tests:
             ttype = sub_t["type"]
@@ -268,16 +359,33 @@ def run_tests(sta_objs, ap_objs, spec, tests, results_dir):
                 node = sta_objs[sub_t["node"]]
                 pos = sub_t["position"]
                 node.setPosition(pos)
+                settle_ms = max(settle_ms, int(sub_t.get("settle_ms", 0)))
                 
             else:
                 msg = f"\n[skip] unsupported test type: {ttype}\n"
                 info(msg)
                 out += msg
+        # Let the moved nodes settle (ex: finish re-associating) before measuring
+        if settle_ms > 0:
+            info(f"*** Waiting {settle_ms}ms for movements to settle\n")
+            time.sleep(settle_ms / 1000)
+
+        # Record the wall-clock time the timeframe is measured at, for time-series visualizations
+        out += "\n[timestamp] {}: {}\n".format(timeframe, datetime.now(timezone.utc).strftime("%Y-%m-%dT%H:%M:%SZ"))
+
         # Run pinall_full after all tests in one timeframe have finished
         info("*** Running pingall_full after all the node movements within one timeframe\n")
-        pingall_out = run_pingall_full(all_nodes, count=1, test_name=timeframe)
+        pingall_out = run_pingall_full(all_nodes, count=1, test_name=timeframe, repetitions=repetitions)
         out += "\n" + pingall_out
 
+        # Record the resource usage of each node, to correlate with connectivity
+        out += run_resources(all_nodes, test_name=timeframe)
+
+        # Record association changes since the last timeframe
+        cur_associations = get_associations(sta_objs)
+        out += run_associations(associations, cur_associations, test_name=timeframe)
+        associations = cur_associations
+
         # Run iw on all stations and access points after all tests in one timeframe have finished
         out += run_iw_stations(sta_objs, ap_objs, "iw dev {interface} link", "check_all_links")
 
@@ -286,8 +394,31 @@ def run_tests(sta_objs, ap_objs, spec, tests, results_dir):
             f.write(out)
     info("\n*** All tests are complete\n")
 
+def parse_repetitions(args):
+    """
+    Returns the number of pingall repetitions per timeframe given by a
+    `--repetitions=K` driver argument (the last, if repeated), or 1 if none is.
+    Other arguments are ignored.
+    """
+    repetitions = 1
+    for arg in args:
+        if arg.startswith("--repetitions="):
+            try:
+                repetitions = int(arg.split("=", 1)[1])
+            except ValueError:
+                repetitions = 0
+            if repetitions < 1:
+                error(f"*** Invalid {arg}: must be a positive integer\n")
+                sys.exit(1)
+    return repetitions
+
 def main():
 
+    # any arguments after the topology are passed through from the test runner's --repetitions and --mn-arg
+    if len(sys.argv) > 2:
+        info(f"*** Driver arguments: {sys.argv[2:]}\n")
+    repetitions = parse_repetitions(sys.argv[2:])
+
     with open(sys.argv[1], "r") as f:
         raw = json.load(f)
 
@@ -297,7 +428,9 @@ def main():
     net, sta_objs, ap_objs = build_from_spec(spec)
 
     results_dir = make_results_dir()
-    run_tests(sta_objs, ap_objs, spec, tests, results_dir)
+    run_tests(sta_objs, ap_objs, spec, tests, results_dir, repetitions)
+    # lets the test runner tell a completed run from one cut short (ex: by a dropped connection)
+    open(os.path.join(results_dir, ".complete"), "w").close()
 
     # info("*** CLI\n")
     # CLI(net)
@@ -1,206 +1,417 @@
 package main
 
 import (
+	omen "Omen"
 	"Omen/modules/1_spawn_topology/models"
-	"bufio"
+	"Omen/prompt"
+	"Omen/ssh"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
+)
+
+// connectTimeout bounds how long establishing the SSH connection may take.
+const connectTimeout = 30 * time.Second
 
-	"golang.org/x/crypto/ssh"
+// Reconnection attempts made by --reconnect-on-drop.
+const (
+	reconnectAttempts = 3
+	reconnectDelay    = 10 * time.Second
 )
 
-func runRemoteMininet(config *models.Config, defaultPythonScript string) error {
+// ErrRunIncomplete is returned by reconnectForResults when the remote run did not complete before the connection was lost.
+var ErrRunIncomplete = errors.New("remote run did not complete")
+
+// ErrSudoRejected is returned by runMininet when sudo prompted for the password again after it was sent (ex: as it was wrong).
+var ErrSudoRejected = errors.New("sudo rejected the password")
+
+// ErrTransientMininet is returned by runMininet when mininet failed in a way that is typically fixed by `mn -c` (ex: stale namespaces or interfaces).
+var ErrTransientMininet = errors.New("mininet hit a transient error")
+
+// transientPattern matches the mininet output lines that indicate an ErrTransientMininet.
+// mininet also prints these harmlessly (ex: "RTNETLINK answers: File exists"), so a match alone does not make a run fail; see mininetRunError.
+var transientPattern = regexp.MustCompile(`(?i)RTNETLINK answers|resource busy`)
+
+// mininetRunError classifies the outcome of a mininet run, given the error it ended with (if any), whether its output matched
+// transientPattern, and whether it ran to completion (ex: printed "*** Done").
+// A transient match is only an ErrTransientMininet if the run also failed or did not complete.
+func mininetRunError(err error, transient, done bool) error {
+	if !transient {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("%w: %w", ErrTransientMininet, err)
+	} else if !done {
+		return ErrTransientMininet
+	}
+	return nil
+}
+
+func runRemoteMininet(config *models.Config) error {
 	// 1) Validate that the local file exists
-	if _, err := os.Stat(defaultPythonScript); os.IsNotExist(err) {
-		return fmt.Errorf("local Python file does not exist: %s", defaultPythonScript)
+	if err := checkDriverScript(config.DriverScript); err != nil {
+		return err
 	}
 
 	// 2) Establish SSH connection
-	sshConfig := &ssh.ClientConfig{
-		User: config.Username,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(config.Password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         30 * time.Second,
+	if config.Jump != nil {
+		infof("-> Connecting to %s@%s via %v\n", config.Username, config.Host, config.Jump)
+	} else {
+		infof("-> Connecting to %s@%s\n", config.Username, config.Host)
 	}
-
-	fmt.Printf("-> Connecting to %s@%s\n", config.Username, config.Host)
-	client, err := ssh.Dial("tcp", config.Host.String(), sshConfig)
+	client, err := connect(config)
 	if err != nil {
 		return fmt.Errorf("SSH connection failed: %w", err)
 	}
-	defer client.Close()
+	// client is replaced if we reconnect, so close whichever is current
+	defer func() { client.Close() }()
+	if quiet && !config.UseCLI { // the CLI is unusable without the session's output
+		client.Output = io.Discard
+	}
+	if config.OutputLog != "" {
+		// written per line, unbuffered, so the log survives this process being killed
+		f, err := os.OpenFile(config.OutputLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("open output log: %w", err)
+		}
+		defer f.Close()
+		client.Log = f
+		infof("-> Logging the remote session's output to %s\n", config.OutputLog)
+	}
+	if !config.UseCLI { // the user may sit idle in the CLI
+		client.InactivityTimeout = config.InactivityTimeout
+		client.SessionTimeout = config.SessionTimeout
+	}
 
 	// 3) Upload Python file via SFTP-like functionality
-	fmt.Printf("-> Uploading topology script {%s} to {%s}\n", defaultPythonScript, config.RemotePathPython)
-	if err := uploadFile(client, defaultPythonScript, config.RemotePathPython); err != nil {
+	infof("-> Uploading topology script {%s} to {%s}\n", config.DriverScript, config.RemotePathPython)
+	if err := client.Upload(config.DriverScript, config.RemotePathPython); err != nil {
 		return fmt.Errorf("file upload failed: %w", err)
 	}
 
 	// 4) Upload Topo JSON file via SFTP-like functionality
-	fmt.Printf("-> Uploading topology JSON {%s} to {%s}\n", config.TopoFile, config.RemotePathJSON)
-	if err := uploadFile(client, config.TopoFile, config.RemotePathJSON); err != nil {
+	infof("-> Uploading topology JSON {%s} to {%s}\n", config.TopoJSONFile, config.RemotePathJSON)
+	if err := client.Upload(config.TopoJSONFile, config.RemotePathJSON); err != nil {
 		return fmt.Errorf("file upload failed: %w", err)
 	}
 
-	// 5) Run Mininet command
-	if err := runMininet(client, config); err != nil {
+	if config.DryRun {
+		fmt.Println("-> Dry run (--dry-run); not executing: " + genCommand(config.UseCLI))
+		return nil
+	}
+
+	// 5) Run Mininet command, cleaning up and retrying on transient failures
+	if config.MNClean {
+		if err := cleanMininet(client, config); err != nil {
+			return fmt.Errorf("mininet cleanup failed: %w", err)
+		}
+	}
+	var priorResultsDir string // the newest results directory prior to this run
+	if config.ReconnectOnDrop {
+		if priorResultsDir, err = findLatestResultsDir(client); err != nil {
+			return err
+		}
+	}
+	for attempt := uint(0); ; attempt++ {
+		err := runMininet(client, config)
+		if err == nil {
+			break
+		}
+		if config.ReconnectOnDrop && (errors.Is(err, ssh.ErrConnectionLost) || errors.Is(err, ssh.ErrInactive)) {
+			infof("-> %v; reconnecting to check whether the run completed\n", err)
+			newClient, rErr := reconnectForResults(config, priorResultsDir)
+			if rErr != nil {
+				return fmt.Errorf("mininet execution failed: %w (%w)", err, rErr)
+			}
+			client.Close()
+			client = newClient
+			infoln("-> The remote run completed before the connection was lost; collecting its results")
+			break
+		}
+		if errors.Is(err, ErrTransientMininet) && attempt < config.RunRetries {
+			infof("-> %v; cleaning up and retrying (%d/%d)\n", err, attempt+1, config.RunRetries)
+			if err := cleanMininet(client, config); err != nil {
+				return fmt.Errorf("mininet cleanup failed: %w", err)
+			}
+			continue
+		}
+		if config.PauseOnError {
+			pauseForDebugging(config)
+		}
 		return fmt.Errorf("mininet execution failed: %w", err)
 	}
 
 	// 6) Copy test results from VM to local directory
-	fmt.Println("-> Copying test results from VM to local directory")
-	if err := copyResultsFromVM(client); err != nil {
+	infoln("-> Copying test results from VM to local directory")
+	if localDir, err := copyResultsFromVM(client); err != nil {
 		fmt.Printf("Warning: Failed to copy results: %v\n", err)
 		// Don't return error here as the main operation succeeded
+	} else if localDir != "" {
+		fmt.Println(omen.ResultsDirPrefix + localDir)
 	}
 
 	return nil
 }
 
-func runMininet(client *ssh.Client, config *models.Config) error {
-	session, err := client.NewSession()
+// connect establishes the SSH connection to config.Host, tunneled through config.Jump if one is given.
+// Retryable failures (see ssh.Retryable) are retried up to config.ConnectRetries times, backing off exponentially from config.ConnectRetryDelay.
+// If every attempt fails, the error of each is returned (joined).
+func connect(config *models.Config) (*ssh.Client, error) {
+	var errs []error
+	delay := config.ConnectRetryDelay
+	for attempt := uint(0); ; attempt++ {
+		client, err := dial(config)
+		if err == nil {
+			return client, nil
+		}
+		errs = append(errs, fmt.Errorf("attempt %d: %w", attempt+1, err))
+		if attempt >= config.ConnectRetries || !ssh.Retryable(err) {
+			return nil, errors.Join(errs...)
+		}
+		infof("-> Connection failed (%v); retrying in %v (%d/%d)\n", err, delay, attempt+1, config.ConnectRetries)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// dial makes a single attempt at establishing the SSH connection to config.Host, tunneled through config.Jump if one is given.
+// Each connection (to the jump host and to config.Host) is bounded by connectTimeout.
+func dial(config *models.Config) (*ssh.Client, error) {
+	hostKeys, err := hostKeyCallback(config)
+	if err != nil {
+		return nil, err
+	}
+	creds := ssh.Credentials{Password: config.Password}
+	if config.PrivateKeyPath != "" {
+		if creds.Key, err = ssh.LoadPrivateKey(config.PrivateKeyPath, config.PrivateKeyPassphrase); err != nil {
+			return nil, err
+		}
+	}
+	if config.Jump == nil {
+		return ssh.Connect(config.Host.String(), config.Username, creds, hostKeys, connectTimeout)
+	}
+	// as ssh -i does for ProxyJump hops, the jump host is authenticated with the --identity key unless a jump password was given
+	jumpCreds := ssh.Credentials{Password: config.Jump.Password}
+	if jumpCreds.Password == "" {
+		jumpCreds.Key = creds.Key
+	}
+	jump, err := ssh.Connect(config.Jump.Addr, config.Jump.Username, jumpCreds, hostKeys, connectTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("connect to jump host %v: %w", config.Jump, err)
+	}
+	client, err := ssh.ConnectVia(jump, config.Host.String(), config.Username, creds, hostKeys, connectTimeout)
 	if err != nil {
-		return fmt.Errorf("create session: %w", err)
+		jump.Close()
+		return nil, err
 	}
-	defer session.Close()
+	return client, nil
+}
 
-	// Request a pseudo terminal for interactive session
-	if err := session.RequestPty("xterm", 120, 40, ssh.TerminalModes{}); err != nil {
-		return fmt.Errorf("request pty: %w", err)
+// hostKeyCallback returns the callback verifying host keys against config.KnownHostsFile (or accepting any, if --insecure-host-key).
+// If --interactive, the user is asked whether to trust the keys of unknown hosts; otherwise, unknown hosts are rejected.
+func hostKeyCallback(config *models.Config) (ssh.HostKeyCallback, error) {
+	if config.InsecureHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	var accept ssh.HostKeyAcceptor
+	if config.Interactive {
+		accept = confirmHostKey
 	}
+	return ssh.KnownHosts(config.KnownHostsFile, accept)
+}
 
-	// Create pipes
-	stdin, err := session.StdinPipe()
+// confirmHostKey asks the user whether to trust the key of an unknown host, as ssh does.
+// Unlike other confirmations, it is never assumed by --assume-yes: accepting a key blindly would defeat verifying it.
+func confirmHostKey(hostname, fingerprint string) (bool, error) {
+	fmt.Printf("The authenticity of host %s can't be established.\n%s\n", hostname, fingerprint)
+	answer, err := prompt.Prompt("Are you sure you want to continue connecting (and add it to known_hosts)? [y/N] ")
 	if err != nil {
-		return fmt.Errorf("create stdin pipe: %w", err)
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
 	}
+	return false, nil
+}
+
+// checkDriverScript confirms the driver script at pth exists and is a file.
+func checkDriverScript(pth string) error {
+	if inf, err := os.Stat(pth); os.IsNotExist(err) {
+		return fmt.Errorf("local Python file does not exist: %s (set it with --driver-script or $%s)", pth, driverScriptEnv)
+	} else if err != nil {
+		return fmt.Errorf("stat driver script: %w", err)
+	} else if inf.IsDir() {
+		return fmt.Errorf("driver script %s is a directory", pth)
+	}
+	return nil
+}
 
-	stdout, err := session.StdoutPipe()
+// reconnectForResults re-establishes the SSH connection after it was lost mid-run, returning the new client if the run completed regardless.
+// The remote results directory is the source of truth: the run completed only if a results directory newer than priorDir exists
+// and holds the driver script's resultsCompleteMarker.
+// Returns an error wrapping ErrRunIncomplete if not.
+//
+// NOTE: the driver script dies with the session's pty, so only runs that finished before the drop (ex: while the session was winding down) are salvaged.
+func reconnectForResults(config *models.Config, priorDir string) (*ssh.Client, error) {
+	var (
+		client *ssh.Client
+		err    error
+	)
+	for attempt := 1; attempt <= reconnectAttempts; attempt++ {
+		infof("-> Reconnecting to %s@%s in %v (%d/%d)\n", config.Username, config.Host, reconnectDelay, attempt, reconnectAttempts)
+		time.Sleep(reconnectDelay)
+		if client, err = connect(config); err == nil {
+			break
+		}
+		infof("-> Reconnect failed: %v\n", err)
+	}
 	if err != nil {
-		return fmt.Errorf("create stdout pipe: %w", err)
+		return nil, fmt.Errorf("reconnect: %w", err)
 	}
 
-	stderr, err := session.StderrPipe()
+	latest, err := findLatestResultsDir(client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	} else if latest == "" || latest == priorDir {
+		client.Close()
+		return nil, fmt.Errorf("%w: no results directory was created", ErrRunIncomplete)
+	}
+	marker := filepath.Join(latest, resultsCompleteMarker)
+	out, err := client.Run(fmt.Sprintf("[ -f %s ] && echo complete || true", shellQuote(marker)))
 	if err != nil {
-		return fmt.Errorf("create stderr pipe: %w", err)
+		client.Close()
+		return nil, fmt.Errorf("check for %s: %w", marker, err)
+	} else if strings.TrimSpace(out) != "complete" {
+		client.Close()
+		return nil, fmt.Errorf("%w: %s was not marked complete", ErrRunIncomplete, latest)
 	}
+	return client, nil
+}
 
+// pauseForDebugging prints the details needed to inspect the remote host (or, if --local, this one) and blocks until the user presses enter.
+// The SSH connection is held open (and nothing is cleaned up) in the meantime.
+func pauseForDebugging(config *models.Config) {
+	if config.Local {
+		fmt.Printf("\n-> Mininet failed; pausing so the local state can be inspected.\n"+
+			"\tPython script  : %s\n"+
+			"\tTopology JSON  : %s\n"+
+			"\tRaw results    : %s\n",
+			config.DriverScript, config.TopoJSONFile, remoteResultsDir)
+	} else {
+		var jumpArg string
+		if config.Jump != nil {
+			jumpArg = "-J " + config.Jump.String() + " "
+		}
+		fmt.Printf("\n-> Mininet failed; pausing so the remote state can be inspected.\n"+
+			"\tConnect with   : ssh %s-p %d %s@%s\n"+
+			"\tPython script  : %s\n"+
+			"\tTopology JSON  : %s\n"+
+			"\tRaw results    : %s\n",
+			jumpArg, config.Host.Port(), config.Username, config.Host.Addr(),
+			config.RemotePathPython, config.RemotePathJSON, remoteResultsDir)
+	}
+	if prompt.AssumingYes() {
+		fmt.Println("Not pausing: --assume-yes was given")
+		return
+	}
+	if _, err := prompt.Prompt("Press enter to disconnect and continue..."); err != nil {
+		fmt.Printf("Not pausing: %v\n", err)
+	}
+}
+
+// runMininet executes the uploaded driver script in an interactive shell on the remote host.
+// The sudo prompt is answered with the configured password and the shell is exited once the script completes.
+func runMininet(client *ssh.Client, config *models.Config) error {
 	// Build Mininet command
 	// TODO: Add --cli flag in python script to enable cli mode if requested
 	// Current: Execute Python script that we just uploaded
 	var mnCommand string = genCommand(config.UseCLI)
 
-	fmt.Printf("-> Executing: %s\n", mnCommand)
-
-	// Start shell session
-	if err := session.Shell(); err != nil {
-		return fmt.Errorf("start shell: %w", err)
+	if config.UseCLI {
+		infof("-> Executing Python script: (cli flag enable)\n")
+	} else {
+		infof("-> Executing Python script: (cli flag disable)\n")
 	}
+	infof("-> Executing: %s\n", mnCommand)
 
-	// Handle output and input in goroutines
-	outputsDone := make(chan bool)
-
-	// Output handling goroutine
-	go func() {
-		defer func() { close(outputsDone) }()
-
-		reader := io.MultiReader(stdout, stderr)
-		scanner := bufio.NewScanner(reader)
-
-		sudoPasswordSent := false
+	sudoPattern, err := sudoPromptPattern(config)
+	if err != nil {
+		return err
+	}
+	var transient, rejected, done bool
+	sudo := ssh.SudoPrompt(config.Password, sudoPattern)
+	handlers := []ssh.PromptHandler{func(line string) (string, bool) {
+		response, exit := sudo(line)
+		rejected = rejected || exit
+		return response, exit
+	}, func(line string) (string, bool) {
+		transient = transient || transientPattern.MatchString(line)
+		return "", false
+	}}
+	if config.UseCLI {
+		// For CLI mode, let the user interact directly and detect when they exit Mininet
+		client.Input = os.Stdin
+		client.ForwardInterrupts = true // Ctrl+C should interrupt the remote command, not abandon the session
 		mininetStarted := false
-
-		for scanner.Scan() {
-			line := scanner.Text()
-			if !strings.Contains(line, config.Password) { // forbit password output on terminal
-				fmt.Println(line)
+		handlers = append(handlers, func(line string) (string, bool) {
+			if strings.Contains(line, "mininet>") && !mininetStarted {
+				mininetStarted = true
+				infoln("\n[DEBUG] Mininet CLI started. Type commands or 'exit' to quit.")
 			}
-
-			// Detect sudo password prompt and auto-respond
-			lowerLine := strings.ToLower(line)
-			if !sudoPasswordSent && ((strings.Contains(lowerLine, "password") && strings.Contains(lowerLine, "sudo")) ||
-				strings.Contains(line, "[sudo]") ||
-				strings.Contains(lowerLine, "password for") ||
-				(strings.HasSuffix(strings.TrimSpace(line), ":") && strings.Contains(lowerLine, "password"))) {
-				fmt.Println("\n[DEBUG] Detected sudo password prompt, sending password...")
-				time.Sleep(300 * time.Millisecond)
-				stdin.Write([]byte(config.Password + "\n"))
-				sudoPasswordSent = true
+			if mininetStarted && (strings.Contains(line, "*** Stopping") ||
+				strings.Contains(line, "completed in") && strings.Contains(line, "seconds")) {
+				infoln("\n[DEBUG] Mininet session ended, logging out...")
+				done = true
+				return "", true
 			}
-
-			// For CLI mode, detect when Mininet starts and handle exit
-			if config.UseCLI {
-				if strings.Contains(line, "mininet>") && !mininetStarted {
-					mininetStarted = true
-					fmt.Println("\n[DEBUG] Mininet CLI started. Type commands or 'exit' to quit.")
-					// In CLI mode, let user interact directly
-				}
-
-				// Detect when user exits Mininet in CLI mode
-				if mininetStarted && (strings.Contains(line, "*** Stopping") ||
-					strings.Contains(line, "completed in") && strings.Contains(line, "seconds")) {
-					fmt.Println("\n[DEBUG] Mininet session ended, logging out...")
-					time.Sleep(500 * time.Millisecond)
-					stdin.Write([]byte("exit\n"))
-					time.Sleep(500 * time.Millisecond)
-					break
-				}
-			} else {
-				// For automated mode, detect completion
-				if strings.Contains(line, "*** Done") {
-					fmt.Println("\n[DEBUG] Pingall test completed, ending session...")
-					time.Sleep(500 * time.Millisecond)
-					stdin.Write([]byte("exit\n"))
-					time.Sleep(500 * time.Millisecond)
-					break
-				}
+			return "", false
+		})
+	} else {
+		// For automated mode, detect completion
+		handlers = append(handlers, func(line string) (string, bool) {
+			if strings.Contains(line, "*** Done") {
+				infoln("\n[DEBUG] Pingall test completed, ending session...")
+				done = true
+				return "", true
 			}
-		}
-	}()
-
-	// Send the Mininet command
-	time.Sleep(500 * time.Millisecond)               // Wait for shell to be ready
-	_, err = stdin.Write([]byte(mnCommand + "\n\n")) // Double newline to trigger sudo prompt
-	if err != nil {
-		return fmt.Errorf("send command: %w", err)
+			return "", false
+		})
 	}
 
-	// For CLI mode, also handle direct user input
-	if config.UseCLI {
-		go func() {
-			// Forward user input to remote session
-			userInput := bufio.NewScanner(os.Stdin)
-			for userInput.Scan() {
-				line := userInput.Text()
-				stdin.Write([]byte(line + "\n"))
-				if line == "exit" {
-					break
-				}
-			}
-		}()
+	// exit the shell if the driver script fails, so a failed run ends the session rather than waiting on the prompt
+	err = client.RunInteractive(mnCommand+" || exit $?", handlers)
+	if err == nil && rejected {
+		return ErrSudoRejected
 	}
+	return mininetRunError(err, transient, done)
+}
 
-	// Wait for session completion or timeout
-	sessionDone := make(chan error)
-	go func() {
-		sessionDone <- session.Wait()
-	}()
-
-	err = <-sessionDone
-	if err != nil && err.Error() != "Process exited with status 130" { // 130 is normal for Ctrl+C
-		return fmt.Errorf("session error: %w", err)
+// cleanMininet runs `sudo mn -c` on the remote host, clearing the namespaces, interfaces, and processes left behind by prior runs.
+func cleanMininet(client *ssh.Client, config *models.Config) error {
+	infoln("-> Executing: sudo mn -c")
+	sudoPattern, err := sudoPromptPattern(config)
+	if err != nil {
+		return err
 	}
+	return client.RunInteractive("sudo mn -c", []ssh.PromptHandler{ssh.SudoPrompt(config.Password, sudoPattern), func(line string) (string, bool) {
+		return "", strings.Contains(line, "Cleanup complete")
+	}})
+}
 
-	// Give additional time to output processing
-	select {
-	case <-outputsDone:
-	case <-time.After(5 * time.Second):
+// sudoPromptPattern compiles config.SudoPromptPattern, returning nil (to use the built-in heuristics) if it is unset.
+func sudoPromptPattern(config *models.Config) (*regexp.Regexp, error) {
+	if config.SudoPromptPattern == "" {
+		return nil, nil
 	}
-
-	return nil
+	pattern, err := regexp.Compile(config.SudoPromptPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --sudo-prompt-pattern: %w", err)
+	}
+	return pattern, nil
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMininetRunError(t *testing.T) {
+	failed := errors.New("exit status 1")
+	tests := []struct {
+		name          string
+		err           error
+		transient     bool
+		done          bool
+		wantErr       error // nil if the run should succeed
+		wantTransient bool
+	}{
+		{"clean run", nil, false, true, nil, false},
+		{"harmless RTNETLINK on a completed run", nil, true, true, nil, false},
+		{"transient match without completion", nil, true, false, ErrTransientMininet, true},
+		{"failure with transient match", failed, true, false, failed, true},
+		{"failure with transient match after completion", failed, true, true, failed, true},
+		{"failure without transient match", failed, false, false, failed, false},
+		{"no completion without transient match", nil, false, false, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mininetRunError(tt.err, tt.transient, tt.done)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("mininetRunError() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("mininetRunError() = %v, want it to wrap %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, ErrTransientMininet); got != tt.wantTransient {
+				t.Errorf("mininetRunError() = %v, transient = %v, want %v", err, got, tt.wantTransient)
+			}
+		})
+	}
+}
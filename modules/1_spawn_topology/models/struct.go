@@ -9,6 +9,10 @@ Input
 │   ├── name (string)
 │   └── duration_s (int)
 ├── topo
+│   ├── nets
+│   │   ├── noise_th (int)
+│   │   ├── propagation_model
+│   │   └── net_size (float64, optional)
 │   ├── host []
 │   │   ├── id (string)
 │   │   ├── tx_dbm (int, optional)
@@ -37,7 +41,8 @@ Input
 │   ├── count (int, optional)
 │   ├── deadline_s (int, optional)
 │   ├── duration_s (int, optional)
-│   └── rate_mbps (int, optional)
+│   ├── rate_mbps (int, optional)
+│   └── settle_ms (int, optional)
 ├── username (string, optional)
 ├── password (string, optional)
 └── address (string, optional)
@@ -45,7 +50,13 @@ Input
 */
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"net/netip"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Main input structure that matches your new JSON format
@@ -57,7 +68,7 @@ type Input struct {
 	// Optional connection info in JSON
 	Username string `json:"username,omitempty"`
 	Password string `json:"password,omitempty"`
-	AP       string `json:"address,omitempty"`
+	Address  string `json:"address,omitempty"` // <host>[:<port>] of the mininet host
 }
 
 // Meta information about the configuration
@@ -79,6 +90,9 @@ type Topo struct {
 type Nets struct {
 	NoiseThreashold  int       `json:"noise_th"`
 	PropagationModel Propmodel `json:"propagation_model"`
+	// NetSize, if set, is the edge length (in meters) of the area nodes are expected to be placed within.
+	// Positions with any coordinate outside of [0, NetSize] are warned about.
+	NetSize float64 `json:"net_size,omitempty"`
 }
 
 type Propmodel struct {
@@ -126,19 +140,237 @@ type Test struct {
 	DeadlineS int    `json:"deadline_s,omitempty"`
 	DurationS int    `json:"duration_s,omitempty"`
 	RateMbps  int    `json:"rate_mbps,omitempty"`
-	MoveNode  string `json:"node,omitempty"`     // MoveNode is the ID of the node to move (for "node movements" test type)
-	Position  string `json:"position,omitempty"` // Position is a string representing coordinates, e.g., "x,y,z"
-	CMD       string `json:"cmd,omitempty"`      // CMD is the command to run (for "iw" test type)
+	MoveNode  string `json:"node,omitempty"`      // MoveNode is the ID of the node to move (for "node movements" test type)
+	Position  string `json:"position,omitempty"`  // Position is a string representing coordinates, e.g., "x,y,z"
+	SettleMs  int    `json:"settle_ms,omitempty"` // SettleMs is how long to wait after moving before measuring (for "node movements" test type)
+	CMD       string `json:"cmd,omitempty"`       // CMD is the command to run (for "iw" test type)
+}
+
+// Test.Type of each kind of test.
+const (
+	MovementTestType = "node movements" // moves a node
+	PingTestType     = "ping"           // pings Dst from Src
+	IWTestType       = "iw"             // runs CMD
+)
+
+// Validate checks the fields of in that the driver script depends on.
+// Every violation is reported (joined), each naming (by index) the test or link it was found in.
+// Suspicious, but runnable, values (ex: nodes placed outside of topo.nets.net_size) are returned as warnings.
+func (in Input) Validate() (warnings []string, _ error) {
+	var errs []error
+	for i, t := range in.Tests {
+		errs = append(errs, t.validate(fmt.Sprintf("test %d (%q)", i, t.Name))) // names need not be unique, or given
+	}
+	for i, l := range in.Topo.Links {
+		errs = append(errs, l.validate(i))
+	}
+	return in.Topo.outOfBounds(in.Tests), errors.Join(errs...)
+}
+
+// outOfBounds returns a warning for each AP, station, or movement test positioned outside of the net's bounds.
+// Hosts and switches are wired, so their positions do not affect reach and are not checked (as the input validator does not).
+// Returns nothing if the net is unbounded (NetSize is unset).
+func (topo Topo) outOfBounds(tests []Test) (warnings []string) {
+	size := topo.Nets.NetSize
+	if size <= 0 {
+		return nil
+	}
+	check := func(what, position string) {
+		coords, ok := parsePosition(position)
+		if !ok {
+			return // malformed positions are rejected by the schema
+		}
+		for _, c := range coords {
+			if c < 0 || c > size {
+				warnings = append(warnings, fmt.Sprintf("%s is positioned at (%s), outside of the %vm net_size; it is likely out of range of every other node", what, position, size))
+				return
+			}
+		}
+	}
+	for _, nodes := range [][]Node{topo.Aps, topo.Stations} {
+		for _, n := range nodes {
+			if n.Position != "" {
+				check("node "+n.ID, n.Position)
+			}
+		}
+	}
+	for _, t := range tests {
+		if t.Type == MovementTestType {
+			check(fmt.Sprintf("test %q moves %s and", t.Name, t.MoveNode), t.Position)
+		}
+	}
+	return warnings
+}
+
+// parsePosition parses a position of the form "x,y,z".
+func parsePosition(position string) (coords [3]float64, ok bool) {
+	parts := strings.Split(position, ",")
+	if len(parts) != len(coords) {
+		return coords, false
+	}
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return coords, false
+		}
+		coords[i] = v
+	}
+	return coords, true
+}
+
+// Validate checks the fields of t that the driver script depends on.
+// Every violation is reported (joined).
+func (t Test) Validate() error {
+	return t.validate(fmt.Sprintf("test %q", t.Name))
+}
+
+// validate is Validate, naming t as what in each violation.
+func (t Test) validate(what string) error {
+	var errs []error
+	violation := func(format string, a ...any) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]any{what}, a...)...))
+	}
+	if t.SettleMs < 0 {
+		violation("settle_ms must be non-negative (given %d)", t.SettleMs)
+	}
+	if t.Count < 0 {
+		violation("count must be non-negative (given %d)", t.Count)
+	}
+	if t.DeadlineS < 0 {
+		violation("deadline_s must be non-negative (given %d)", t.DeadlineS)
+	}
+	if t.Type == MovementTestType {
+		if t.MoveNode == "" || t.Position == "" {
+			violation("movements must specify a node and position")
+		}
+	} else if t.SettleMs != 0 {
+		violation("settle_ms is only supported by %q tests", MovementTestType)
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks the constraints of l, the i'th link of the topology.
+// Every violation is reported (joined). Zero-valued constraints are unset, and so always valid.
+func (l Link) validate(i int) error {
+	var errs []error
+	violation := func(format string, a ...any) {
+		errs = append(errs, fmt.Errorf("link %d (%s-%s): constraints."+format, append([]any{i, l.NodeIDA, l.NodeIDB}, a...)...))
+	}
+	c := l.Constraints
+	if c.LossPkt < 0 || c.LossPkt > 1 {
+		violation("loss_pkt must be within [0, 1] (given %v)", c.LossPkt)
+	}
+	if c.ThroughputMbps < 0 {
+		violation("throughput_mbps must be non-negative (given %d)", c.ThroughputMbps)
+	}
+	if c.MTU < 0 {
+		violation("mtu must be positive (given %d)", c.MTU)
+	}
+	if c.DelayMS < 0 {
+		violation("delay_ms must be non-negative (given %d)", c.DelayMS)
+	}
+	return errors.Join(errs...)
 }
 
 // Input Config from user to setup ssh connection to VM
 type Config struct {
-	Host             netip.AddrPort
-	Username         string
-	Password         string
-	TopoFile         string
-	UseCLI           bool
-	RemotePathPython string
-	RemotePathJSON   string
-	Interactive      bool
+	Host              netip.AddrPort `json:"host"`
+	Username          string         `json:"username"`
+	Password          string         `json:"password"`
+	TopoFile          string         `json:"topo_file"`
+	TopoJSONFile      string         `json:"topo_json_file"` // JSON form of TopoFile, as uploaded to the remote; differs from TopoFile if the topology was given as YAML
+	UseCLI            bool           `json:"use_cli"`
+	RemotePathPython  string         `json:"remote_path_python"`
+	RemotePathJSON    string         `json:"remote_path_json"`
+	Interactive       bool           `json:"interactive"`
+	AssumeYes         bool           `json:"assume_yes"`         // answer every confirmation with yes rather than prompting
+	PauseOnError      bool           `json:"pause_on_error"`     // wait for the user before tearing down the session if mininet fails
+	MNClean           bool           `json:"mn_clean"`           // run `mn -c` on the remote before running the topology
+	RunRetries        uint           `json:"run_retries"`        // times to clean up and rerun mininet after a transient failure
+	MNArgs            []string       `json:"mn_args"`            // extra arguments appended to the driver script invocation
+	Repetitions       uint           `json:"repetitions"`        // pingall matrices the driver script measures per timeframe
+	DriverScript      string         `json:"driver_script"`      // local path of the driver script to upload
+	InactivityTimeout time.Duration  `json:"inactivity_timeout"` // fail if the remote session outputs nothing for this long; 0 disables
+	SessionTimeout    time.Duration  `json:"session_timeout"`    // fail if the remote session has not completed within this long; 0 disables
+	OutputLog         string         `json:"output_log"`         // if set, the remote session's output is appended to this local file, timestamped per line
+	ReconnectOnDrop   bool           `json:"reconnect_on_drop"`  // if the connection drops mid-run, reconnect and collect the results if the run completed regardless
+	Local             bool           `json:"local"`              // run the driver script against mininet on this machine (via sudo) rather than over SSH
+	Jump              *JumpHost      `json:"jump,omitempty"`     // bastion the connection to Host is tunneled through; nil connects directly
+	KnownHostsFile    string         `json:"known_hosts_file"`   // known_hosts file the keys of Host (and Jump) are verified against
+	InsecureHostKey   bool           `json:"insecure_host_key"`  // accept any host key rather than verifying it against KnownHostsFile
+	// PrivateKeyPath, if set, is the private key the connection to Host authenticates with in place of Password.
+	// Password is still sent to sudo on the remote.
+	PrivateKeyPath       string `json:"private_key_path"`
+	PrivateKeyPassphrase string `json:"private_key_passphrase"` // decrypts PrivateKeyPath, if it is encrypted
+	// SudoPromptPattern, if set, is the regexp identifying the remote's sudo password prompts in place of the built-in (English) heuristics.
+	// An empty pattern keeps the heuristics.
+	SudoPromptPattern string        `json:"sudo_prompt_pattern"`
+	ConnectRetries    uint          `json:"connect_retries"`     // times to retry a connection that failed retryably (ex: was refused)
+	ConnectRetryDelay time.Duration `json:"connect_retry_delay"` // delay before the first retry; doubled before each subsequent one
+	DryRun            bool          `json:"dry_run"`             // connect and upload, but print the driver command rather than running mininet
+}
+
+// DefaultSSHPort is the port assumed of addresses that do not give one.
+const DefaultSSHPort uint16 = 22
+
+// ParseAddrPort parses an address of the form <ip>[:<port>], assuming DefaultSSHPort if no port is given (reported by defaulted).
+// IPv6 addresses must be bracketed if a port is given (ex: [2001:db8::1]:22), as in a URL, and may be either way if not.
+func ParseAddrPort(s string) (_ netip.AddrPort, defaulted bool, _ error) {
+	s = strings.TrimSpace(s)
+	if ap, err := netip.ParseAddrPort(s); err == nil {
+		return ap, false, nil
+	}
+	host := s
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.AddrPort{}, false, fmt.Errorf("invalid address %q, expected <ip>[:<port>] (ex: 192.168.64.5:22 or [2001:db8::1]:22)", s)
+	}
+	return netip.AddrPortFrom(addr, DefaultSSHPort), true, nil
+}
+
+// ParseRemote parses a remote of the form <user>@<ip>[:<port>] (see ParseAddrPort).
+func ParseRemote(s string) (username string, host netip.AddrPort, defaulted bool, _ error) {
+	user, addr, found := strings.Cut(strings.TrimSpace(s), "@")
+	if !found || user == "" || strings.Contains(addr, "@") {
+		return "", netip.AddrPort{}, false, fmt.Errorf("invalid remote %q, expected <user>@<ip>[:<port>]", s)
+	}
+	host, defaulted, err := ParseAddrPort(addr)
+	if err != nil {
+		return "", netip.AddrPort{}, false, fmt.Errorf("invalid remote %q: %w", s, err)
+	}
+	return user, host, defaulted, nil
+}
+
+// JumpHost is a bastion the connection to the mininet host is tunneled through, as ssh's ProxyJump does.
+type JumpHost struct {
+	Username string `json:"username"`
+	Addr     string `json:"addr"` // <host>:<port>; unlike Config.Host, host may be a name (ex: bastion.example.edu)
+	Password string `json:"password"`
+}
+
+// ParseJumpHost parses a jump host of the form <user>@<host>[:<port>], assuming port 22 if none is given.
+// The password is left empty.
+func ParseJumpHost(s string) (*JumpHost, error) {
+	user, addr, found := strings.Cut(strings.TrimSpace(s), "@")
+	if !found || user == "" || addr == "" {
+		return nil, fmt.Errorf("invalid jump host %q, expected <user>@<host>[:<port>]", s)
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(strings.Trim(addr, "[]"), "22")
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return nil, fmt.Errorf("invalid jump host %q, expected <user>@<host>[:<port>]", s)
+	} else if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return nil, fmt.Errorf("invalid jump host %q: port must be within [1, 65535]", s)
+	}
+	return &JumpHost{Username: user, Addr: addr}, nil
+}
+
+// String returns the jump host in the form ParseJumpHost accepts.
+func (j JumpHost) String() string {
+	return j.Username + "@" + j.Addr
 }
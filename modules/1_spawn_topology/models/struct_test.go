@@ -0,0 +1,220 @@
+package models
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestParseAddrPort(t *testing.T) {
+	tests := []struct {
+		name          string
+		s             string
+		want          netip.AddrPort
+		wantDefaulted bool
+		wantErr       bool
+	}{
+		{"bracketed IPv6 with port", "[2001:db8::1]:22", netip.MustParseAddrPort("[2001:db8::1]:22"), false, false},
+		{"bracketed IPv6 with non-default port", "[2001:db8::1]:2222", netip.MustParseAddrPort("[2001:db8::1]:2222"), false, false},
+		{"bracketed IPv6 without port", "[2001:db8::1]", netip.MustParseAddrPort("[2001:db8::1]:22"), true, false},
+		{"bare IPv6", "2001:db8::1", netip.MustParseAddrPort("[2001:db8::1]:22"), true, false},
+		{"IPv6 loopback", "[::1]", netip.MustParseAddrPort("[::1]:22"), true, false},
+		{"IPv4 with port", "192.168.64.5:2222", netip.MustParseAddrPort("192.168.64.5:2222"), false, false},
+		{"IPv4 without port", "192.168.64.5", netip.MustParseAddrPort("192.168.64.5:22"), true, false},
+		{"surrounding whitespace", " 192.168.64.5 ", netip.MustParseAddrPort("192.168.64.5:22"), true, false},
+		{"empty", "", netip.AddrPort{}, false, true},
+		{"hostname", "mininet.local:22", netip.AddrPort{}, false, true},
+		{"unbracketed IPv6 with port", "2001:db8::1:22:", netip.AddrPort{}, false, true},
+		{"port out of range", "192.168.64.5:65536", netip.AddrPort{}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, defaulted, err := ParseAddrPort(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAddrPort(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			} else if got != tt.want || defaulted != tt.wantDefaulted {
+				t.Errorf("ParseAddrPort(%q) = %v (defaulted: %v), want %v (defaulted: %v)", tt.s, got, defaulted, tt.want, tt.wantDefaulted)
+			}
+		})
+	}
+}
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		wantUser string
+		wantHost netip.AddrPort
+		wantErr  bool
+	}{
+		{"bracketed IPv6 with port", "wifi@[2001:db8::1]:22", "wifi", netip.MustParseAddrPort("[2001:db8::1]:22"), false},
+		{"bracketed IPv6 without port", "wifi@[2001:db8::1]", "wifi", netip.MustParseAddrPort("[2001:db8::1]:22"), false},
+		{"IPv4 with port", "wifi@127.0.0.1:2222", "wifi", netip.MustParseAddrPort("127.0.0.1:2222"), false},
+		{"IPv4 without port", "wifi@127.0.0.1", "wifi", netip.MustParseAddrPort("127.0.0.1:22"), false},
+		{"no user", "127.0.0.1:22", "", netip.AddrPort{}, true},
+		{"empty user", "@127.0.0.1:22", "", netip.AddrPort{}, true},
+		{"multiple @", "wifi@lab@127.0.0.1", "", netip.AddrPort{}, true},
+		{"invalid host", "wifi@[2001:db8::1", "", netip.AddrPort{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, host, _, err := ParseRemote(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRemote(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			} else if user != tt.wantUser || host != tt.wantHost {
+				t.Errorf("ParseRemote(%q) = (%q, %v), want (%q, %v)", tt.s, user, host, tt.wantUser, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	tests := []struct {
+		position string
+		want     [3]float64
+		wantOK   bool
+	}{
+		{"0,0,0", [3]float64{0, 0, 0}, true},
+		{"1.5,-2,30", [3]float64{1.5, -2, 30}, true},
+		{" 1 , 2 , 3 ", [3]float64{1, 2, 3}, true},
+		{"1,2", [3]float64{}, false},
+		{"1,2,3,4", [3]float64{}, false},
+		{"1,two,3", [3]float64{}, false},
+		{"", [3]float64{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePosition(tt.position)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("parsePosition(%q) = %v, %v, want %v, %v", tt.position, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestOutOfBounds(t *testing.T) {
+	topo := Topo{
+		Nets:     Nets{NetSize: 100},
+		Hosts:    []Node{{ID: "h1", Position: "500,0,0"}}, // wired; not checked
+		Switches: []Node{{ID: "s1", Position: "-5,0,0"}},  // wired; not checked
+		Aps:      []Node{{ID: "ap1", Position: "50,50,0"}, {ID: "ap2", Position: "101,0,0"}},
+		Stations: []Node{{ID: "sta1", Position: "0,0,0"}, {ID: "sta2", Position: "10,-1,0"}, {ID: "sta3"}},
+	}
+	tests := []Test{
+		{Name: "in", Type: MovementTestType, MoveNode: "sta1", Position: "100,100,100"},
+		{Name: "out", Type: MovementTestType, MoveNode: "sta1", Position: "0,0,150"},
+		{Name: "ping", Type: PingTestType, Src: "sta1", Dst: "sta2"},
+	}
+
+	got := topo.outOfBounds(tests)
+	wantMentions := []string{"node ap2", "node sta2", `test "out" moves sta1`}
+	if len(got) != len(wantMentions) {
+		t.Fatalf("outOfBounds() = %q, want %d warnings", got, len(wantMentions))
+	}
+	for i, w := range wantMentions {
+		if !strings.Contains(got[i], w) {
+			t.Errorf("warning %d = %q, want it to mention %s", i, got[i], w)
+		}
+	}
+
+	topo.Nets.NetSize = 0
+	if got := topo.outOfBounds(tests); got != nil {
+		t.Errorf("outOfBounds() with no net_size = %q, want none", got)
+	}
+}
+
+func TestTestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		test Test
+		want []string // substrings of each expected violation, in order
+	}{
+		{"ping", Test{Name: "p", Type: PingTestType, Src: "h1", Dst: "h2", Count: 3, DeadlineS: 10}, nil},
+		{"unset bounds", Test{Name: "p", Type: PingTestType}, nil},
+		{"movement", Test{Name: "m", Type: MovementTestType, MoveNode: "sta1", Position: "1,1,0", SettleMs: 500}, nil},
+		{"negative count", Test{Name: "p", Type: PingTestType, Count: -1}, []string{`test "p": count must be non-negative (given -1)`}},
+		{"negative deadline", Test{Name: "p", Type: PingTestType, DeadlineS: -5}, []string{"deadline_s must be non-negative (given -5)"}},
+		{"negative settle", Test{Name: "m", Type: MovementTestType, MoveNode: "sta1", Position: "1,1,0", SettleMs: -1},
+			[]string{"settle_ms must be non-negative (given -1)"}},
+		{"settle outside of a movement", Test{Name: "p", Type: PingTestType, SettleMs: 100}, []string{`settle_ms is only supported by "node movements" tests`}},
+		{"movement without a node", Test{Name: "m", Type: MovementTestType, Position: "1,1,0"}, []string{"must specify a node and position"}},
+		{"movement without a position", Test{Name: "m", Type: MovementTestType, MoveNode: "sta1"}, []string{"must specify a node and position"}},
+		{"every violation", Test{Name: "p", Type: PingTestType, Count: -1, DeadlineS: -1, SettleMs: -1},
+			[]string{"settle_ms must be non-negative", "count must be non-negative", "deadline_s must be non-negative", "settle_ms is only supported"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkViolations(t, tt.test.Validate(), tt.want)
+		})
+	}
+}
+
+func TestLinkValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints Constraints
+		want        []string // substrings of each expected violation, in order
+	}{
+		{"unset", Constraints{}, nil},
+		{"set", Constraints{LossPkt: 0.25, ThroughputMbps: 100, MTU: 1500, DelayMS: 20}, nil},
+		{"lossless", Constraints{LossPkt: 0}, nil},
+		{"total loss", Constraints{LossPkt: 1}, nil},
+		{"negative loss", Constraints{LossPkt: -0.1}, []string{"link 2 (h1-s1): constraints.loss_pkt must be within [0, 1] (given -0.1)"}},
+		{"loss above 1", Constraints{LossPkt: 1.5}, []string{"loss_pkt must be within [0, 1] (given 1.5)"}},
+		{"loss as a percentage", Constraints{LossPkt: 10}, []string{"loss_pkt must be within [0, 1] (given 10)"}},
+		{"negative throughput", Constraints{ThroughputMbps: -1}, []string{"constraints.throughput_mbps must be non-negative (given -1)"}},
+		{"negative mtu", Constraints{MTU: -1500}, []string{"constraints.mtu must be positive (given -1500)"}},
+		{"negative delay", Constraints{DelayMS: -20}, []string{"constraints.delay_ms must be non-negative (given -20)"}},
+		{"every violation", Constraints{LossPkt: 2, ThroughputMbps: -1, MTU: -1, DelayMS: -1},
+			[]string{"loss_pkt", "throughput_mbps", "mtu", "delay_ms"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := Link{NodeIDA: "h1", NodeIDB: "s1", Constraints: tt.constraints}
+			checkViolations(t, l.validate(2), tt.want)
+		})
+	}
+}
+
+func TestInputValidate(t *testing.T) {
+	in := Input{
+		Topo: Topo{Links: []Link{
+			{NodeIDA: "h1", NodeIDB: "s1"},
+			{NodeIDA: "s1", NodeIDB: "h2", Constraints: Constraints{LossPkt: 1.5, DelayMS: -1}},
+		}},
+		Tests: []Test{
+			{Name: "ok", Type: PingTestType, Src: "h1", Dst: "h2"},
+			{Name: "dup", Type: PingTestType, Count: -1},
+			{Name: "dup", Type: PingTestType, DeadlineS: -1},
+			{Type: MovementTestType},
+		},
+	}
+	_, err := in.Validate()
+	checkViolations(t, err, []string{
+		`test 1 ("dup"): count must be non-negative`,
+		`test 2 ("dup"): deadline_s must be non-negative`,
+		`test 3 (""): movements must specify a node and position`,
+		"link 1 (s1-h2): constraints.loss_pkt",
+		"link 1 (s1-h2): constraints.delay_ms",
+	})
+
+	in.Tests, in.Topo.Links = in.Tests[:1], in.Topo.Links[:1]
+	if _, err := in.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want no violations", err)
+	}
+}
+
+// checkViolations fails t unless err joins exactly one violation per want, each containing the corresponding substring.
+func checkViolations(t *testing.T, err error, want []string) {
+	t.Helper()
+	var got []string
+	if err != nil {
+		got = strings.Split(err.Error(), "\n")
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got violations %q, want %d", got, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(got[i], w) {
+			t.Errorf("violation %d = %q, want it to contain %q", i, got[i], w)
+		}
+	}
+}
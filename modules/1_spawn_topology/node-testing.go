@@ -5,7 +5,12 @@ This file is for the custom tests to run within mininet
 */
 
 import (
+	"Omen/modules/1_spawn_topology/models"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
 )
 
 /*
@@ -23,17 +28,41 @@ useCLI == false: run "pingall" test and end the session
 */
 func genCommand(useCLI bool) string {
 	// Build Mininet command
-	var mnCommand string = fmt.Sprintf("sudo python3 %s %s", config.RemotePathPython, config.RemotePathJSON)
-
-	if useCLI {
-		// mnCommand = fmt.Sprintf("sudo mn --custom %s --topo fromjson", config.RemotePath)
-		// fmt.Printf("-> Starting interactive Mininet session (type 'exit' to quit)\n")
-		fmt.Printf("-> Executing Python script: (cli flag enable)\n")
-	} else {
-		// mnCommand = fmt.Sprintf("sudo mn --custom %s --topo fromjson --test pingall", config.RemotePath)
-		// fmt.Printf("-> Running automated pingall test\n")
-		fmt.Printf("-> Executing Python script: (cli flag disable)\n")
+	// the remote paths are quoted as well, as they may contain spaces (ex: /tmp/topo file.json)
+	var mnCommand string = fmt.Sprintf("sudo python3 %s %s", shellQuote(config.RemotePathPython), shellQuote(config.RemotePathJSON))
+	// pass user-supplied driver arguments through verbatim; quoting keeps the shell from interpreting them
+	for _, arg := range driverArgs(&config) {
+		mnCommand += " " + shellQuote(arg)
 	}
 
 	return mnCommand
 }
+
+// driverArgs returns the arguments passed to the driver script after the topology: --repetitions (unless it is the default), then
+// the user-supplied --mn-args.
+func driverArgs(config *models.Config) []string {
+	var args []string
+	if config.Repetitions > 1 {
+		args = append(args, "--repetitions="+strconv.FormatUint(uint64(config.Repetitions), 10))
+	}
+	return append(args, config.MNArgs...)
+}
+
+// shellQuote wraps s in single quotes so a POSIX shell treats it as a single, literal word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// validateMNArgs checks that each driver argument can be safely passed through the remote (interactive) shell.
+// As the command is typed into a pty, control characters (ex: newlines) are rejected outright rather than quoted.
+func validateMNArgs(args []string) error {
+	for _, arg := range args {
+		if arg == "" {
+			return errors.New("--mn-arg cannot be empty")
+		}
+		if strings.ContainsFunc(arg, unicode.IsControl) {
+			return fmt.Errorf("--mn-arg %q cannot contain control characters", arg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os/exec"
+	"testing"
+
+	"Omen/modules/1_spawn_topology/models"
+)
+
+func TestGenCommandQuotesRemotePaths(t *testing.T) {
+	oldConfig := config
+	t.Cleanup(func() { config = oldConfig })
+	config = models.Config{
+		RemotePathPython: "/tmp/omen scripts/mininet-script.py",
+		RemotePathJSON:   "/tmp/topo file.json",
+		Repetitions:      1,
+		MNArgs:           []string{"--verbose"},
+	}
+
+	want := `sudo python3 '/tmp/omen scripts/mininet-script.py' '/tmp/topo file.json' '--verbose'`
+	if got := genCommand(false); got != want {
+		t.Errorf("genCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"plain", "--seed=42", `'--seed=42'`},
+		{"empty", "", `''`},
+		{"spaces", "a b  c", `'a b  c'`},
+		{"single quote", "it's", `'it'\''s'`},
+		{"only single quotes", "''", `''\'''\'''`},
+		{"double quotes", `"x"`, `'"x"'`},
+		{"command substitution", "$(rm -rf /)", `'$(rm -rf /)'`},
+		{"backticks", "`id`", "'`id`'"},
+		{"variable", "$HOME", `'$HOME'`},
+		{"separators", "a; b && c | d", `'a; b && c | d'`},
+		{"glob", "*", `'*'`},
+		{"newline", "a\nb", "'a\nb'"}, // quoted literally, though validateMNArgs rejects it before it reaches the pty
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shellQuote(tt.s)
+			if got != tt.want {
+				t.Errorf("shellQuote(%q) = %s, want %s", tt.s, got, tt.want)
+			}
+			// the shell must read the quoted word back as exactly s
+			out, err := exec.Command("sh", "-c", "printf %s "+got).Output()
+			if err != nil {
+				t.Fatalf("sh -c 'printf %%s %s': %v", got, err)
+			} else if string(out) != tt.s {
+				t.Errorf("sh read shellQuote(%q) back as %q", tt.s, out)
+			}
+		})
+	}
+}
+
+func TestValidateMNArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"plain", []string{"--seed=42", "--verbose"}, false},
+		{"quotes", []string{`--name="it's"`}, false},
+		{"command substitution", []string{"$(id)", "`id`"}, false}, // quoted by shellQuote, not rejected
+		{"spaces", []string{"a b"}, false},
+		{"empty", []string{"--seed=42", ""}, true},
+		{"newline", []string{"--seed=42\nsudo reboot"}, true},
+		{"carriage return", []string{"a\rb"}, true},
+		{"tab", []string{"a\tb"}, true},
+		{"escape", []string{"a\x1b[2Jb"}, true},
+		{"NUL", []string{"a\x00b"}, true},
+		{"interrupt", []string{"\x03"}, true},
+		{"DEL", []string{"a\x7f"}, true},
+		{"C1 control", []string{"a\u0085b"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateMNArgs(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("validateMNArgs(%q) = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+// This file implements the sample subcommand, which writes a template topology for new users to start from.
+
+import (
+	"Omen/modules/1_spawn_topology/models"
+	"Omen/prompt"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// sampleYAMLHeader prefixes YAML samples, as JSON cannot carry comments.
+const sampleYAMLHeader string = `# Sample Omen topology.
+#
+# meta: backend is "mininet" or "mininet-wifi"; duration_s must be positive.
+# topo.nets: noise_th is in dBm (<= 0). propagation_model.model is "logDistance" or "logNormalShadowing" (which also requires s > 0).
+# topo.aps: mode is one of a, b, g, n, ac, ax. Positions are "x,y,z".
+# topo.links: constraints are optional; omit any that should not be limited.
+# tests: each test runs in a timeframe (>= 0); a pingall matrix is measured at the end of every timeframe.
+#   "node movements" moves node to position, then waits settle_ms before measuring.
+#   "ping" pings dst from src count times.
+#   "iw" runs cmd (with {interface} substituted). Currently skipped by the driver script.
+# username, password, and address (<host>[:<port>]) may also be given here rather than prompted for.
+`
+
+// newSampleCommand returns the sample subcommand.
+func newSampleCommand() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "sample [<out>.(json|yaml)]",
+		Short: "write a template topology to start from",
+		Long: "sample writes a topology covering each node, link, and test type to the given file (or stdout, if omitted or -).\n" +
+			"The template is composed from the same structures topologies are read into, so it always matches the current schema.\n" +
+			"Files ending in .yaml or .yml are written as commented YAML; all others are written as JSON.",
+		Example: appName + " sample input.json\n" +
+			appName + " sample input.yaml\n" +
+			appName + " sample > input.json",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 || args[0] == "-" {
+				return writeSample(cmd.OutOrStdout(), false)
+			}
+			return writeSampleFile(args[0], force)
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the output file if it already exists")
+	return cmd
+}
+
+// writeSampleFile writes the sample topology to pth, in the format implied by its extension.
+// Fails if pth exists, unless force.
+func writeSampleFile(pth string, force bool) error {
+	f, err := createOutputFile(pth, force)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := writeSample(f, isYAMLPath(pth)); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	infof("Sample topology written to: %s\n", pth)
+	return nil
+}
+
+// createOutputFile creates the file at pth for writing.
+// If pth exists, the user is asked whether to overwrite it, unless force.
+func createOutputFile(pth string, force bool) (*os.File, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(pth, flags, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		if ok, cErr := prompt.Confirm(pth + " already exists. Overwrite it? [y/N] "); cErr != nil || !ok {
+			return nil, fmt.Errorf("%s already exists. Use --force (or --assume-yes) to overwrite it", pth)
+		}
+		return os.OpenFile(pth, os.O_WRONLY|os.O_TRUNC, 0644)
+	}
+	return f, err
+}
+
+// isYAMLPath reports whether pth has a YAML extension (.yaml or .yml).
+func isYAMLPath(pth string) bool {
+	ext := strings.ToLower(filepath.Ext(pth))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// writeSample writes the sample topology to w as indented JSON or, if asYAML, as commented YAML.
+func writeSample(w io.Writer, asYAML bool) error {
+	if asYAML {
+		if _, err := io.WriteString(w, sampleYAMLHeader); err != nil {
+			return err
+		}
+	}
+	return writeTopology(w, sampleInput(), asYAML)
+}
+
+// sampleInput returns the template topology: two APs, two stations, a constrained link, and one test of each type.
+func sampleInput() models.Input {
+	return models.Input{
+		SchemaVersion: "1.0",
+		Meta: models.Meta{
+			Backend:   "mininet-wifi",
+			Name:      "sample",
+			DurationS: 60,
+		},
+		Topo: models.Topo{
+			Hosts:    []models.Node{},
+			Switches: []models.Node{},
+			Aps: []models.Node{
+				{ID: "ap1", Mode: "g", Channel: 1, SSID: "omen-ssid1", Position: "0,0,0"},
+				{ID: "ap2", Mode: "a", Channel: 36, SSID: "omen-ssid2", Position: "60,0,0"},
+			},
+			Stations: []models.Node{
+				{ID: "sta1", Position: "5,5,0"},
+				{ID: "sta2", Position: "55,5,0"},
+			},
+			Nets: models.Nets{
+				NoiseThreashold: -91,
+				PropagationModel: models.Propmodel{
+					Model: "logNormalShadowing",
+					Exp:   3,
+					S:     1,
+				},
+			},
+			Links: []models.Link{
+				{NodeIDA: "ap1", NodeIDB: "ap2", Constraints: models.Constraints{
+					LossPkt:        0.5,
+					ThroughputMbps: 100,
+					MTU:            1500,
+					DelayMS:        2,
+				}},
+			},
+		},
+		Tests: []models.Test{
+			{Name: "move sta1 toward ap2", Type: models.MovementTestType, Timeframe: 1, MoveNode: "sta1", Position: "45,5,0", SettleMs: 500},
+			{Name: "ping sta1 to sta2", Type: models.PingTestType, Timeframe: 1, Src: "sta1", Dst: "sta2", Count: 3},
+			{Name: "check links", Type: models.IWTestType, Timeframe: 1, CMD: "iw dev {interface} link"},
+		},
+	}
+}
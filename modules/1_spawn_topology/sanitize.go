@@ -0,0 +1,97 @@
+package main
+
+// This file implements the sanitize subcommand, which strips connection details from a topology so it can be shared.
+
+import (
+	"Omen/modules/1_spawn_topology/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// newSanitizeCommand returns the sanitize subcommand.
+func newSanitizeCommand() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "sanitize <topo>.(json|yaml) [<out>.(json|yaml)]",
+		Short: "copy a topology without its credentials and address",
+		Long: "sanitize writes a copy of the given topology with the username, password, and address removed, " +
+			"so it can be shared (ex: attached to a bug report) without leaking lab credentials.\n" +
+			"The copy is written to the given file (or stdout, if omitted or -). " +
+			"Files ending in .yaml or .yml are written as YAML; all others are written as JSON. Stdout receives the input's format.\n" +
+			"As the copy is composed from the structures topologies are read into, fields the test runner does not recognize are dropped as well.",
+		Example: appName + " sanitize input.json shareable.json\n" +
+			appName + " sanitize input.yaml > shareable.yaml",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in, err := readTopology(args[0])
+			if err != nil {
+				return err
+			}
+			in = sanitizeInput(in)
+			if len(args) == 1 || args[1] == "-" {
+				return writeTopology(cmd.OutOrStdout(), in, isYAMLPath(args[0]))
+			}
+
+			f, err := createOutputFile(args[1], force)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if err := writeTopology(f, in, isYAMLPath(args[1])); err != nil {
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			infof("Sanitized topology written to: %s\n", args[1])
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the output file if it already exists")
+	return cmd
+}
+
+// sanitizeInput returns a copy of in with its connection details (username, password, and address) cleared.
+func sanitizeInput(in models.Input) models.Input {
+	in.Username, in.Password, in.Address = "", "", ""
+	return in
+}
+
+// readTopology reads the JSON (or, given a YAML extension, YAML) topology at pth.
+func readTopology(pth string) (models.Input, error) {
+	var in models.Input
+	data, err := os.ReadFile(pth)
+	if err != nil {
+		return in, fmt.Errorf("read topo file: %w", err)
+	}
+	if isYAMLPath(pth) {
+		if data, err = yaml.YAMLToJSON(data); err != nil {
+			return in, fmt.Errorf("parse topology YAML: %w", err)
+		}
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return in, fmt.Errorf("parse topology JSON: %w", err)
+	}
+	return in, nil
+}
+
+// writeTopology writes in to w as indented JSON or, if asYAML, as YAML.
+func writeTopology(w io.Writer, in models.Input, asYAML bool) error {
+	out, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal topology: %w", err)
+	}
+	if asYAML {
+		if out, err = yaml.JSONToYAML(out); err != nil {
+			return fmt.Errorf("convert topology to YAML: %w", err)
+		}
+	}
+	_, err = fmt.Fprintln(w, strings.TrimSpace(string(out)))
+	return err
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	omen "Omen"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// coalescedMarker is the name of the file, within the output directory, recording the hash of the inputs it was produced from.
+const coalescedMarker string = ".coalesced"
+
+// inputsHash returns a hex-encoded hash of everything that determines the output: the raw files in runDir (names and contents),
+// the contents of the --input topology (if given), the options that alter the CSVs written, and the module version.
+func inputsHash(runDir string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "version=%s\ndelimiter=%s\ncrlf=%t\nonly=%d\nstrict=%t\nparquet=%t\nedge-filter=%s\ngraphml=%t\ngzip=%t\n",
+		omen.Version, *delimiter, *useCRLF, *only, *strict, *parquetOut, *edgeFilterName, *graphMLOut, *gzipOut)
+
+	hashFile := func(label, pth string) error {
+		f, err := os.Open(pth)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		// prefix each file with its name and size so contents cannot run together
+		fmt.Fprintf(h, "%s\x00%d\x00", label, info.Size())
+		_, err = io.Copy(h, f)
+		return err
+	}
+
+	if *inputTopo != "" {
+		if err := hashFile("input", *inputTopo); err != nil {
+			return "", err
+		}
+	}
+	// WalkDir visits in lexical order, so the hash is stable
+	err := filepath.WalkDir(runDir, func(pth string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(runDir, pth)
+		if err != nil {
+			return err
+		}
+		return hashFile(filepath.ToSlash(rel), pth)
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// alreadyCoalesced reports whether the marker in outDir records the given inputs hash.
+// A missing or unreadable marker is treated as not coalesced.
+func alreadyCoalesced(outDir, hash string) bool {
+	data, err := os.ReadFile(filepath.Join(outDir, coalescedMarker))
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(data)) == hash
+}
+
+// writeCoalescedMarker records hash as the inputs the output in outDir was produced from.
+func writeCoalescedMarker(outDir, hash string) error {
+	return os.WriteFile(filepath.Join(outDir, coalescedMarker), []byte(hash+"\n"), 0644)
+}
+
+// removeCoalescedMarker deletes the marker in outDir (if it exists), so output is not considered complete until it is rewritten.
+func removeCoalescedMarker(outDir string) error {
+	if err := os.Remove(filepath.Join(outDir, coalescedMarker)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
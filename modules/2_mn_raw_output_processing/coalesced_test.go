@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_coalescedMarker(t *testing.T) {
+	runDir, outDir := t.TempDir(), t.TempDir()
+	raw := filepath.Join(runDir, "timeframe0.txt")
+	if err := os.WriteFile(raw, []byte("[pingall_full] 0:\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := inputsHash(runDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alreadyCoalesced(outDir, hash) {
+		t.Fatal("output without a marker reported as coalesced")
+	}
+	if err := writeCoalescedMarker(outDir, hash); err != nil {
+		t.Fatal(err)
+	}
+	if !alreadyCoalesced(outDir, hash) {
+		t.Error("output with a matching marker not reported as coalesced")
+	}
+
+	// altering a raw file must alter the hash
+	if err := os.WriteFile(raw, []byte("[pingall_full] 1:\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := inputsHash(runDir); err != nil {
+		t.Fatal(err)
+	} else if changed == hash || alreadyCoalesced(outDir, changed) {
+		t.Error("changed inputs reported as coalesced")
+	}
+
+	// as must altering an option that changes the output
+	t.Cleanup(func() { *delimiter = "," })
+	*delimiter = ";"
+	if changed, err := inputsHash(runDir); err != nil {
+		t.Fatal(err)
+	} else if changed == hash {
+		t.Error("changed delimiter did not change the inputs hash")
+	}
+
+	if err := removeCoalescedMarker(outDir); err != nil {
+		t.Fatal(err)
+	} else if err := removeCoalescedMarker(outDir); err != nil {
+		t.Errorf("removing a missing marker should not error: %v", err)
+	}
+}
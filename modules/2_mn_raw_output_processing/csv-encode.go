@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"Omen/modules/2_mn_raw_output_processing/models"
+)
+
+// A recordEncoder lays out structs of type T as CSV records of a given header.
+// Each string field tagged `csv:"<column>"` is placed under its column; columns without a field are left empty
+// (or hold the constant given at construction), so records cannot drift out of order with the header.
+type recordEncoder[T any] struct {
+	template []string // empty record of the header's width, holding any constant columns
+	columns  []int    // header index of each field of T; -1 if the field is untagged
+}
+
+// newRecordEncoder returns an encoder of T into records of header.
+// constants maps columns to values shared by every record (ex: a device type) and may be nil.
+// Panics if T is not a struct, a tagged field is not a string, or a column is tagged twice or absent from header,
+// as encoders are constructed at init and each is a programming error.
+func newRecordEncoder[T any](header []string, constants map[string]string) recordEncoder[T] {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("cannot encode %v as a CSV record: not a struct", typ))
+	}
+	index := func(col string) int {
+		i := slices.Index(header, col)
+		if i < 0 {
+			panic(fmt.Sprintf("cannot encode %v as a CSV record: column %q is not in the header", typ, col))
+		}
+		return i
+	}
+
+	enc := recordEncoder[T]{template: make([]string, len(header)), columns: make([]int, typ.NumField())}
+	tagged := make(map[string]bool, typ.NumField())
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		col, ok := field.Tag.Lookup("csv")
+		if !ok || col == "-" {
+			enc.columns[i] = -1
+			continue
+		} else if field.Type.Kind() != reflect.String {
+			panic(fmt.Sprintf("cannot encode %v as a CSV record: field %s is not a string", typ, field.Name))
+		} else if tagged[col] {
+			panic(fmt.Sprintf("cannot encode %v as a CSV record: column %q is tagged more than once", typ, col))
+		}
+		tagged[col] = true
+		enc.columns[i] = index(col)
+	}
+	for col, v := range constants {
+		if tagged[col] {
+			panic(fmt.Sprintf("cannot encode %v as a CSV record: constant column %q is also tagged", typ, col))
+		}
+		enc.template[index(col)] = v
+	}
+	return enc
+}
+
+// encode returns the record of v.
+func (e recordEncoder[T]) encode(v T) []string {
+	record := slices.Clone(e.template)
+	rv := reflect.ValueOf(v)
+	for i, col := range e.columns {
+		if col >= 0 {
+			record[col] = rv.Field(i).String()
+		}
+	}
+	return record
+}
+
+// Encoders of the model records written to CSV.
+var (
+	stationEncoder = newRecordEncoder[models.StationRecord](iwHeader, map[string]string{"device_type": "station"})
+	apEncoder      = newRecordEncoder[models.AccessPointRecord](iwHeader, map[string]string{"device_type": "access_point"})
+	nodeEncoder    = newRecordEncoder[models.NodeRecord](nodesHeader, nil)
+	edgeEncoder    = newRecordEncoder[models.EdgeRecord](edgesHeader, nil)
+)
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func Test_recordEncoder(t *testing.T) {
+	type row struct {
+		B       string `csv:"b"`
+		A       string `csv:"a"`
+		skipped string
+		Ignored string `csv:"-"`
+	}
+	enc := newRecordEncoder[row]([]string{"kind", "a", "b", "c"}, map[string]string{"kind": "test"})
+	got := enc.encode(row{A: "1", B: "2", skipped: "x", Ignored: "y"})
+	if want := []string{"test", "1", "2", ""}; !slices.Equal(got, want) {
+		t.Errorf("encode() = %q, want %q", got, want)
+	}
+	// records must not share the template
+	got[0] = "changed"
+	if again := enc.encode(row{}); again[0] != "test" {
+		t.Errorf("encoding mutated the constant column: %q", again)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name string
+			new  func()
+		}{
+			{"unknown column", func() {
+				newRecordEncoder[struct {
+					A string `csv:"missing"`
+				}]([]string{"a"}, nil)
+			}},
+			{"non-string field", func() {
+				newRecordEncoder[struct {
+					A int `csv:"a"`
+				}]([]string{"a"}, nil)
+			}},
+			{"duplicate column", func() {
+				newRecordEncoder[struct {
+					A string `csv:"a"`
+					B string `csv:"a"`
+				}]([]string{"a"}, nil)
+			}},
+			{"constant of a tagged column", func() {
+				newRecordEncoder[struct {
+					A string `csv:"a"`
+				}]([]string{"a"}, map[string]string{"a": "x"})
+			}},
+			{"not a struct", func() { newRecordEncoder[string]([]string{"a"}, nil) }},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				defer func() {
+					if recover() == nil {
+						t.Error("expected a panic")
+					}
+				}()
+				tt.new()
+			})
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"Omen/modules/2_mn_raw_output_processing/models"
+)
+
+// parsedDump incrementally writes parsed models to a file as an indented JSON array, for --dump-parsed.
+// The output is that of json.MarshalIndent(parsed, "", "  ") (plus a trailing newline), but parsed models need not be retained.
+type parsedDump struct {
+	f     *os.File
+	count int
+}
+
+// createParsedDump creates (or truncates) the file at pth and opens its array.
+func createParsedDump(pth string) (*parsedDump, error) {
+	f, err := os.Create(pth)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteString("["); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &parsedDump{f: f}, nil
+}
+
+// add appends p to the array.
+func (d *parsedDump) add(p models.ParsedRawFile) error {
+	data, err := json.MarshalIndent(p, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if d.count == 0 {
+		sep = "\n  "
+	}
+	if _, err := d.f.WriteString(sep); err != nil {
+		return err
+	}
+	if _, err := d.f.Write(data); err != nil {
+		return err
+	}
+	d.count += 1
+	return nil
+}
+
+// close terminates the array and closes the file.
+func (d *parsedDump) close() error {
+	end := "\n]\n"
+	if d.count == 0 {
+		end = "]\n"
+	}
+	if _, err := d.f.WriteString(end); err != nil {
+		d.f.Close()
+		return err
+	}
+	return d.f.Close()
+}
+
+// dumpParsed writes parsed to the file at pth as an indented JSON array.
+func dumpParsed(pth string, parsed []models.ParsedRawFile) error {
+	d, err := createParsedDump(pth)
+	if err != nil {
+		return err
+	}
+	for _, p := range parsed {
+		if err := d.add(p); err != nil {
+			d.close()
+			return err
+		}
+	}
+	return d.close()
+}
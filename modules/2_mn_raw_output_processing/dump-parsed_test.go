@@ -0,0 +1,41 @@
+package main
+
+import (
+	"Omen/modules/2_mn_raw_output_processing/models"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_dumpParsed(t *testing.T) {
+	tests := []struct {
+		name   string
+		parsed []models.ParsedRawFile
+	}{
+		{"none", []models.ParsedRawFile{}},
+		{"several", []models.ParsedRawFile{
+			{Timeframe: 0, Path: "timeframe0.txt", Movements: []models.MovementRecord{{MovementNumber: "0", NodeName: "sta1", Position: "0,0,0"}}},
+			{Timeframe: 1, Path: "timeframe1.txt", Pings: []models.PingRecord{{Src: "sta1", Dst: "ap1", LossPct: "0"}}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pth := filepath.Join(t.TempDir(), "parsed.json")
+			if err := dumpParsed(pth, tt.parsed); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(pth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := json.MarshalIndent(tt.parsed, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want)+"\n" {
+				t.Errorf("unexpected dump.\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+//go:build fixtures
+
+package main
+
+// This file generates synthetic raw output in the driver script's format, along with the CSVs Coalesce Output must produce from it.
+// It is only built with -tags fixtures (as by fixture_test.go and the GenFixtures mage target), so it never ships in the binary.
+
+import (
+	"encoding/csv"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fixtureRunDir is the name of the run directory the raw files of a fixture are written into.
+const fixtureRunDir = "20250101_000000"
+
+// A fixtureSpec configures the topology and measurements of a fixture.
+type fixtureSpec struct {
+	stations, aps int    // node counts
+	timeframes    int    // each timeframe is a movement of every node, written to its own raw file
+	repetitions   int    // pingall matrices measured per timeframe, as by the driver's --repetitions
+	seed          uint64 // seeds every random value, so a spec always generates the same fixture
+}
+
+// A fixture is a run of raw files and the output that must be coalesced from it (under the default flags).
+type fixture struct {
+	raw      map[string]string // contents of each raw file, by name
+	expected map[string]string // contents of each output CSV, by path relative to the output directory
+}
+
+// write writes the raw files of f into dir/raw/<fixtureRunDir> and its expected output into dir/expected.
+func (f fixture) write(dir string) error {
+	for prefix, files := range map[string]map[string]string{
+		filepath.Join(dir, "raw", fixtureRunDir): f.raw,
+		filepath.Join(dir, "expected"):           f.expected,
+	} {
+		for name, contents := range files {
+			pth := filepath.Join(prefix, name)
+			if err := os.MkdirAll(filepath.Dir(pth), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(pth, []byte(contents), 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fixtureNode is a station or access point of a generated timeframe.
+type fixtureNode struct {
+	name, position                         string // position as written to nodes.csv (ex: "10.0, -5.0, 0.0")
+	ap                                     bool
+	mac                                    string // ether of an AP; that of the AP a station is associated with ("" if none)
+	apName                                 string // name of the AP a station is associated with
+	rxBytes, rxPackets, txBytes, txPackets string
+}
+
+// fixturePing is a single row of a pingall matrix, as written by the driver and as it should be coalesced.
+type fixturePing struct {
+	src, dst          string
+	rawLoss, rawRTT   string
+	tx, rx, loss, rtt string
+}
+
+// generateFixture synthesizes the raw files of a run of the given spec and the output that must be coalesced from them.
+// Node names, measurements, and occasional failures (lost pings, unsampleable resources, unassociated stations) are
+// drawn from spec.seed.
+func generateFixture(spec fixtureSpec) fixture {
+	rng := rand.New(rand.NewPCG(spec.seed, 0))
+	f := fixture{raw: map[string]string{}, expected: map[string]string{}}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var pingRows, statRows, stationRows, apRows, associationRows, resourceRows [][]string
+	associations := map[string]string{} // station -> AP, as of the prior timeframe
+	for tf := range spec.timeframes {
+		tfStr, testFile := strconv.Itoa(tf), "timeframe"+strconv.Itoa(tf)+".txt"
+		timestamp := start.Add(time.Duration(tf) * time.Minute).Format(time.RFC3339)
+		nodes := generateNodes(rng, spec)
+
+		var raw strings.Builder
+		fmt.Fprintf(&raw, "[format] %d\n", len(rawFormats)-1) // the newest format version
+		fmt.Fprintf(&raw, "\n[timestamp] %d: %s\n\n", tf, timestamp)
+		for _, n := range nodes {
+			fmt.Fprintf(&raw, "\n[node movements] %d: move %s: moving %s -> [%s]\n", tf, n.name, n.name, n.position)
+			fmt.Fprintf(&raw, "Moved %s to [%s]\n", n.name, n.position)
+		}
+
+		// pingall
+		var pings []fixturePing
+		for rep := 1; rep <= spec.repetitions; rep++ {
+			fmt.Fprintf(&raw, "\n[pingall_full] %d: pairwise matrix (-c 1) across %d nodes", tf, len(nodes))
+			if spec.repetitions > 1 {
+				fmt.Fprintf(&raw, " (repetition %d/%d)", rep, spec.repetitions)
+			}
+			raw.WriteString("\nsrc,dst,tx,rx,loss_pct,avg_rtt_ms\n")
+			for _, s := range nodes {
+				for _, d := range nodes {
+					if s.name == d.name {
+						continue
+					}
+					p := generatePing(rng, s.name, d.name)
+					fmt.Fprintf(&raw, "%s,%s,%s,%s,%s,%s\n", p.src, p.dst, p.tx, p.rx, p.rawLoss, p.rawRTT)
+					pings = append(pings, p)
+				}
+			}
+		}
+		var movementRows [][]string
+		for _, p := range pings {
+			row := []string{"ping", tfStr, testFile, "", "", p.src, p.dst, p.tx, p.rx, p.loss, p.rtt, timestamp}
+			pingRows = append(pingRows, row)
+			movementRows = append(movementRows, row)
+		}
+		statRows = append(statRows, expectedPingStats(tfStr, testFile, pings)...)
+		f.expected[filepath.Join("timeframe"+tfStr, "ping_data_movement_"+tfStr+".csv")] = encodeFixtureCSV(pingAllHeader, movementRows)
+
+		// resources
+		fmt.Fprintf(&raw, "\n[resources] %d: per-node resource usage\nnode,pid,cpu_pct,rss_kb\n", tf)
+		for i, n := range nodes {
+			if rng.IntN(5) == 0 { // the driver could not sample the node
+				fmt.Fprintf(&raw, "%s,?,?,?\n", n.name)
+				resourceRows = append(resourceRows, []string{tfStr, testFile, n.name, "", "", ""})
+				continue
+			}
+			pid, cpu, rss := strconv.Itoa(1000+100*tf+i), strconv.FormatFloat(float64(rng.IntN(1000))/10, 'f', 1, 64), strconv.Itoa(2000+rng.IntN(8000))
+			fmt.Fprintf(&raw, "%s,%s,%s,%s\n", n.name, pid, cpu, rss)
+			resourceRows = append(resourceRows, []string{tfStr, testFile, n.name, pid, cpu, rss})
+		}
+
+		// associations, relative to the prior timeframe
+		fmt.Fprintf(&raw, "\n[associations] %d: association events\n", tf)
+		for _, n := range nodes {
+			if n.ap || associations[n.name] == n.apName {
+				continue
+			}
+			if old := associations[n.name]; old != "" {
+				fmt.Fprintf(&raw, "%s disassociated from %s\n", n.name, old)
+				associationRows = append(associationRows, []string{tfStr, testFile, n.name, old, "disassociated"})
+			}
+			if n.apName != "" {
+				fmt.Fprintf(&raw, "%s associated with %s\n", n.name, n.apName)
+				associationRows = append(associationRows, []string{tfStr, testFile, n.name, n.apName, "associated"})
+			}
+			associations[n.name] = n.apName
+		}
+
+		// iw
+		raw.WriteString("\n[iw_stations] check_all_links: running 'iw dev {interface} link' on all stations\n")
+		raw.WriteString(strings.Repeat("=", 60) + "\n")
+		for i := range nodes {
+			if nodes[i].ap {
+				apRows = append(apRows, writeAPBlock(rng, &raw, testFile, &nodes[i]))
+			} else {
+				stationRows = append(stationRows, writeStationBlock(rng, &raw, testFile, &nodes[i]))
+			}
+		}
+		raw.WriteString("\n" + strings.Repeat("=", 60) + "\n")
+		f.raw[testFile] = raw.String()
+
+		f.expected[filepath.Join("timeframe"+tfStr, "nodes.csv")] = encodeFixtureCSV(nodesHeader, expectedNodes(nodes, pings))
+		f.expected[filepath.Join("timeframe"+tfStr, "edges.csv")] = encodeFixtureCSV(edgesHeader, expectedEdges(pings))
+	}
+
+	f.expected[fullPingDataCSV] = encodeFixtureCSV(pingAllHeader, pingRows)
+	f.expected[pingStatsCSV] = encodeFixtureCSV(pingStatsHeader, statRows)
+	f.expected[fullIWDataCSV] = encodeFixtureCSV(iwHeader, append(stationRows, apRows...))
+	f.expected[associationsCSV] = encodeFixtureCSV(associationsHeader, associationRows)
+	f.expected[resourcesCSV] = encodeFixtureCSV(resourcesHeader, resourceRows)
+	return f
+}
+
+// generateNodes places the stations then access points of spec, associating each station with a random AP (or none).
+func generateNodes(rng *rand.Rand, spec fixtureSpec) []fixtureNode {
+	coord := func() string { return strconv.FormatFloat(float64(rng.IntN(2001)-1000)/10, 'f', 1, 64) }
+	nodes := make([]fixtureNode, 0, spec.stations+spec.aps)
+	aps := make([]fixtureNode, spec.aps)
+	for i := range aps {
+		aps[i] = fixtureNode{name: "ap" + strconv.Itoa(i+1), ap: true, mac: fmt.Sprintf("02:00:00:00:%02x:00", i+1)}
+	}
+	for i := range spec.stations {
+		n := fixtureNode{name: "sta" + strconv.Itoa(i+1)}
+		if j := rng.IntN(spec.aps + 1); j < spec.aps {
+			n.mac, n.apName = aps[j].mac, aps[j].name
+		}
+		nodes = append(nodes, n)
+	}
+	nodes = append(nodes, aps...)
+	for i := range nodes {
+		nodes[i].position = coord() + ", " + coord() + ", 0.0"
+	}
+	return nodes
+}
+
+// generatePing returns a ping from src to dst that succeeds, is lost, or errors (in which case the driver reports "+1 errors" as its loss).
+func generatePing(rng *rand.Rand, src, dst string) fixturePing {
+	switch r := rng.IntN(10); {
+	case r < 7:
+		rtt := strconv.FormatFloat(float64(1+rng.IntN(9999))/1000, 'f', 3, 64)
+		return fixturePing{src: src, dst: dst, tx: "1", rx: "1", rawLoss: "0", rawRTT: rtt, loss: "0", rtt: rtt}
+	case r < 9:
+		return fixturePing{src: src, dst: dst, tx: "1", rx: "0", rawLoss: "100", rawRTT: "?", loss: "100", rtt: "0"}
+	default:
+		return fixturePing{src: src, dst: dst, tx: "1", rx: "0", rawLoss: "+1 errors", rawRTT: "?", loss: "100", rtt: "0"}
+	}
+}
+
+// writeStationBlock writes the iw link output of station n into raw (recording its traffic in n), returning the row it must coalesce into.
+func writeStationBlock(rng *rand.Rand, raw *strings.Builder, testFile string, n *fixtureNode) []string {
+	fmt.Fprintf(raw, "\n--- Station %s ---\nCommand: iw dev %s-wlan0 link\nOutput:\n", n.name, n.name)
+	row := map[string]string{"device_type": "station", "test_file": testFile, "device_name": n.name}
+	if n.apName == "" {
+		raw.WriteString("Not connected.\n\n")
+		return fixtureRow(iwHeader, row)
+	}
+	n.rxBytes, n.rxPackets = strconv.Itoa(rng.IntN(200000)), strconv.Itoa(rng.IntN(5000))
+	n.txBytes, n.txPackets = strconv.Itoa(rng.IntN(10000)), strconv.Itoa(rng.IntN(100))
+	signal := strconv.Itoa(-20-rng.IntN(70)) + " dBm"
+	fmt.Fprintf(raw, "Connected to %s (on %s-wlan0)\n", n.mac, n.name)
+	fmt.Fprintf(raw, "\tSSID: ssid-%s\n\tfreq: 5180.0\n", n.apName)
+	fmt.Fprintf(raw, "\tRX: %s bytes (%s packets)\n\tTX: %s bytes (%s packets)\n", n.rxBytes, n.rxPackets, n.txBytes, n.txPackets)
+	fmt.Fprintf(raw, "\tsignal: %s\n\trx bitrate: 54.0 MBit/s\n\ttx bitrate: 6.0 MBit/s\n", signal)
+	raw.WriteString("\tbss flags: short-slot-time\n\tdtim period: 2\n\tbeacon int: 100\n\n")
+	maps.Copy(row, map[string]string{
+		"connected_to": n.mac, "connected_to_name": n.apName, "ssid": "ssid-" + n.apName, "freq": "5180.0",
+		"rx_bytes": n.rxBytes, "rx_packets": n.rxPackets, "tx_bytes": n.txBytes, "tx_packets": n.txPackets,
+		"signal": signal, "rx_bitrate": "54.0 MBit/s", "tx_bitrate": "6.0 MBit/s",
+		"bss_flags": "short-slot-time", "dtim_period": "2", "beacon_int": "100",
+	})
+	return fixtureRow(iwHeader, row)
+}
+
+// writeAPBlock writes the ifconfig output of access point n into raw (recording its traffic in n), returning the row it must coalesce into.
+func writeAPBlock(rng *rand.Rand, raw *strings.Builder, testFile string, n *fixtureNode) []string {
+	intf := n.name + "-wlan1"
+	n.rxPackets, n.rxBytes = strconv.Itoa(rng.IntN(500)), strconv.Itoa(rng.IntN(50000))
+	n.txPackets, n.txBytes = strconv.Itoa(rng.IntN(500)), strconv.Itoa(rng.IntN(50000))
+	rxErr, txErr := strconv.Itoa(rng.IntN(3)), strconv.Itoa(rng.IntN(3))
+	fmt.Fprintf(raw, "\n--- Access Point %s ---\nCommand: %s ifconfig %s\nOutput:\n", n.name, n.name, intf)
+	fmt.Fprintf(raw, "%s: flags=4163<UP,BROADCAST,RUNNING,MULTICAST>  mtu 1500\n", intf)
+	fmt.Fprintf(raw, "        ether %s  txqueuelen 1000  (Ethernet)\n", n.mac)
+	fmt.Fprintf(raw, "        RX packets %s  bytes %s (0.0 KB)\n", n.rxPackets, n.rxBytes)
+	fmt.Fprintf(raw, "        RX errors %s  dropped 0  overruns 0  frame 0\n", rxErr)
+	fmt.Fprintf(raw, "        TX packets %s  bytes %s (0.0 KB)\n", n.txPackets, n.txBytes)
+	fmt.Fprintf(raw, "        TX errors %s  dropped 0 overruns 0  carrier 0  collisions 0\n\n\n", txErr)
+	return fixtureRow(iwHeader, map[string]string{
+		"device_type": "access_point", "test_file": testFile, "device_name": n.name, "interface": intf,
+		"flags": "UP,BROADCAST,RUNNING,MULTICAST", "mtu": "1500", "ether": n.mac, "tx_queue_len": "1000",
+		"rx_packets": n.rxPackets, "rx_bytes": n.rxBytes, "rx_errors": rxErr, "rx_dropped": "0", "rx_overruns": "0", "rx_frame": "0",
+		"tx_packets": n.txPackets, "tx_bytes": n.txBytes, "tx_errors": txErr, "tx_dropped": "0", "tx_overruns": "0",
+		"tx_carrier": "0", "tx_collisions": "0",
+	})
+}
+
+// expectedNodes returns the rows of a timeframe's nodes.csv: each node's traffic (as parsed from its iw block) and
+// the fraction of its pings, sent or received, without loss.
+func expectedNodes(nodes []fixtureNode, pings []fixturePing) [][]string {
+	total, succeeded := map[string]int{}, map[string]int{}
+	for _, p := range pings {
+		total[p.src]++
+		total[p.dst]++
+		if p.loss == "0" {
+			succeeded[p.src]++
+			succeeded[p.dst]++
+		}
+	}
+	rows := make([][]string, 0, len(nodes))
+	for _, n := range nodes {
+		rate := 0.0
+		if total[n.name] > 0 {
+			rate = float64(succeeded[n.name]) / float64(total[n.name])
+		}
+		rows = append(rows, []string{
+			n.name, n.name, n.position, n.rxBytes, n.rxPackets, n.txBytes, n.txPackets, strconv.FormatFloat(rate, 'f', 2, 64),
+		})
+	}
+	return rows
+}
+
+// expectedEdges returns the rows of a timeframe's edges.csv under the default --edge-filter (no station to station edges).
+func expectedEdges(pings []fixturePing) [][]string {
+	edges := map[string][]string{}
+	for _, p := range pings {
+		if strings.HasPrefix(p.src, "sta") && strings.HasPrefix(p.dst, "sta") {
+			continue
+		}
+		edges[p.src+"-"+p.dst] = []string{p.src + "-" + p.dst, p.src, p.dst}
+	}
+	rows := make([][]string, 0, len(edges))
+	for _, id := range slices.Sorted(maps.Keys(edges)) {
+		rows = append(rows, edges[id])
+	}
+	return rows
+}
+
+// expectedPingStats returns the rows of ping_stats.csv for a timeframe's pings, in the order each pair was first pinged.
+func expectedPingStats(tf, testFile string, pings []fixturePing) [][]string {
+	var order [][2]string
+	byPair := map[[2]string][]fixturePing{}
+	for _, p := range pings {
+		key := [2]string{p.src, p.dst}
+		if _, ok := byPair[key]; !ok {
+			order = append(order, key)
+		}
+		byPair[key] = append(byPair[key], p)
+	}
+	rows := make([][]string, 0, len(order))
+	for _, key := range order {
+		var loss, rtt []float64
+		for _, p := range byPair[key] {
+			if p.loss == "0" {
+				loss = append(loss, 0)
+				v, _ := strconv.ParseFloat(p.rtt, 64)
+				rtt = append(rtt, v)
+			} else {
+				loss = append(loss, 100)
+			}
+		}
+		lossMean, lossStddev := fixtureStats(loss)
+		rttMean, rttStddev := fixtureStats(rtt)
+		rows = append(rows, []string{
+			tf, testFile, key[0], key[1], strconv.Itoa(len(byPair[key])), lossMean, lossStddev, rttMean, rttStddev,
+		})
+	}
+	return rows
+}
+
+// fixtureStats returns the mean and sample standard deviation of values as ping_stats.csv formats them.
+func fixtureStats(values []float64) (mean, stddev string) {
+	if len(values) == 0 {
+		return "", ""
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	m := sum / float64(len(values))
+	if len(values) == 1 {
+		return strconv.FormatFloat(m, 'f', 3, 64), ""
+	}
+	var squares float64
+	for _, v := range values {
+		squares += (v - m) * (v - m)
+	}
+	return strconv.FormatFloat(m, 'f', 3, 64), strconv.FormatFloat(math.Sqrt(squares/float64(len(values)-1)), 'f', 3, 64)
+}
+
+// fixtureRow lays values out under header, leaving columns without a value empty.
+func fixtureRow(header []string, values map[string]string) []string {
+	row := make([]string, len(header))
+	for i, col := range header {
+		row[i] = values[col]
+	}
+	return row
+}
+
+// encodeFixtureCSV returns the CSV of header and rows, as written under the default --delimiter.
+func encodeFixtureCSV(header []string, rows [][]string) string {
+	var sb strings.Builder
+	wr := csv.NewWriter(&sb)
+	wr.Write(header)
+	wr.WriteAll(rows) // writes to a strings.Builder cannot fail
+	return sb.String()
+}
@@ -0,0 +1,77 @@
+//go:build fixtures
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var fixturesOut = flag.String("fixtures.out", "", "write the generated fixtures into this directory rather than testing against them")
+
+// Coalescing the raw files of each generated fixture must produce exactly its expected CSVs, with or without --low-memory.
+func Test_fixtures(t *testing.T) {
+	specs := map[string]fixtureSpec{
+		"minimal":  {stations: 1, aps: 1, timeframes: 1, repetitions: 1, seed: 1},
+		"typical":  {stations: 4, aps: 2, timeframes: 3, repetitions: 1, seed: 2},
+		"repeated": {stations: 3, aps: 2, timeframes: 2, repetitions: 3, seed: 3},
+		"large":    {stations: 20, aps: 5, timeframes: 6, repetitions: 2, seed: 4},
+	}
+	for name, spec := range specs {
+		t.Run(name, func(t *testing.T) {
+			f := generateFixture(spec)
+			if *fixturesOut != "" {
+				if err := f.write(filepath.Join(*fixturesOut, name)); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			dir := t.TempDir()
+			if err := f.write(dir); err != nil {
+				t.Fatal(err)
+			}
+			runDir := filepath.Join(dir, "raw", fixtureRunDir)
+
+			*strict = true
+			defer func() { *strict = false }()
+			priorOutputDir, priorIssues := *outputDir, len(fileIssues)
+			defer func() { *outputDir = priorOutputDir }()
+
+			for mode, coalesce := range map[string]func(){
+				"full": func() {
+					parsed, err := processRawFileDirectory(runDir, -1, nil)
+					if err != nil {
+						t.Fatal(err)
+					}
+					writeCumulativeCSVs(parsed)
+					for _, p := range parsed {
+						writeTimeframe(p)
+					}
+				},
+				"low-memory": func() { processStreaming(runDir, nil) },
+			} {
+				*outputDir = filepath.Join(dir, mode)
+				if err := os.MkdirAll(*outputDir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				coalesce()
+				for pth, want := range f.expected {
+					got, err := os.ReadFile(filepath.Join(*outputDir, pth))
+					if err != nil {
+						t.Errorf("%s: %v", mode, err)
+						continue
+					}
+					if string(got) != want {
+						t.Errorf("%s: %s differs.\ngot:\n%s\nwant:\n%s", mode, pth, got, want)
+					}
+				}
+			}
+			if issues := fileIssues[priorIssues:]; len(issues) > 0 {
+				t.Errorf("unexpected warnings: %v", issues)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	omen "Omen"
+	"Omen/modules/2_mn_raw_output_processing/models"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -8,44 +10,154 @@ import (
 	"path"
 	"path/filepath"
 	"strconv"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/pflag"
 )
 
-// expected timestamp format in directory name
-const directoryNameFormat string = "20060102_150405"
-
 const (
-	fullPingDataCSV string = "ping_data.csv" // name of the cumulative ping data file
+	fullPingDataCSV string = "ping_data.csv"  // name of the cumulative ping data file
+	pingStatsCSV    string = "ping_stats.csv" // name of the cumulative ping data, aggregated over repetitions
 	fullIWDataCSV   string = "final_iw_data.csv"
+	associationsCSV string = "associations.csv"
+	resourcesCSV    string = "resources.csv"
+	testsCSV        string = "tests.csv"
 )
 
 // flag values
 var (
-	outputDir *string
+	outputDir       *string
+	version         *bool
+	jsonVersion     *bool
+	delimiter       *string
+	useCRLF         *bool
+	gzipOut         *bool
+	dumpParsedPath  *string
+	only            *int
+	strict          *bool
+	lowMemory       *bool
+	inputTopo       *string
+	failFast        *bool
+	collectWarnings *bool
+	parquetOut      *bool
+	quiet           *bool
+	force           *bool
+	edgeFilterName  *string
+	listRuns        *bool
+	retain          *int
+	graphMLOut      *bool
 )
 
+// infof prints informational output, unless --quiet was given.
+// Errors, warnings, and final results should be printed directly instead.
+func infof(format string, a ...any) {
+	if !*quiet {
+		fmt.Printf(format, a...)
+	}
+}
+
+// infoln is the Println form of infof.
+func infoln(a ...any) {
+	if !*quiet {
+		fmt.Println(a...)
+	}
+}
+
 // init defines and maps flags
 func init() {
 	outputDir = pflag.StringP("output", "o", "./results", "directory to write processed files to")
+	version = pflag.BoolP("version", "v", false, "print version, then exit")
+	jsonVersion = pflag.Bool("json-version", false, "print version and build information as JSON, then exit")
+	delimiter = pflag.String("delimiter", ",", "field delimiter of the CSV files written (ex: ';'). The visualization loader expects the default")
+	only = pflag.Int("only", -1, "process only the given timeframe, skipping the cumulative (all-timeframe) CSVs. Useful for iterating on a single timeframe")
+	lowMemory = pflag.Bool("low-memory", false, "parse and write one timeframe at a time rather than holding every timeframe in memory. Use for very large runs")
+	strict = pflag.Bool("strict", false, "treat malformed raw output (ex: duplicate nodes within a timeframe) as an error instead of warning")
+	inputTopo = pflag.String("input", "", "path to the input topology the raw output was produced from. If given, its tests are written to "+testsCSV+", mapped to the timeframes they produced")
+	failFast = pflag.Bool("fail-fast", false, "halt on the first raw file that cannot be processed instead of skipping it")
+	collectWarnings = pflag.Bool("collect-warnings", false, "rather than printing warnings as they occur, print a report of every warning (by file) once processing completes")
+	parquetOut = pflag.Bool("parquet", false, "also write the cumulative ping and IW data as (typed) Parquet files, for analytics tools like pandas or DuckDB")
+	quiet = pflag.BoolP("quiet", "q", false, "suppress informational output (ex: per-file progress), printing only errors, warnings, and final results")
+	force = pflag.Bool("force", false, "reprocess even if the output directory was already produced from identical inputs")
+	edgeFilterName = pflag.String("edge-filter", "no-sta-sta", "edges to include in each edges.csv. Must be one of {all|no-sta-sta|ap-sta-only}")
+	graphMLOut = pflag.Bool("export-graphml", false, "also write each timeframe's nodes and edges as "+graphMLFile+", for graph tools like Gephi or yEd")
+	listRuns = pflag.Bool("list-runs", false, "list the run directories within the given directory (newest first, with their file counts), then exit")
+	retain = pflag.Int("retain", 0, "after processing, delete all but the N newest run directories within the input's base directory. "+
+		"Only directories named as runs are deleted, and never the run just processed. 0 keeps every run")
+	useCRLF = pflag.Bool("use-crlf", false, "end lines of the CSV files written with \\r\\n instead of \\n")
+	gzipOut = pflag.Bool("gzip-output", false, "gzip each CSV written, suffixing its name with .gz (ex: ping_data.csv.gz). Parquet and GraphML files are unaffected")
+	dumpParsedPath = pflag.String("dump-parsed", "", "write every parsed raw file (all records extracted from it) as indented JSON to this path before any CSV is written. "+
+		"For debugging the parser; implies --force, so the dump is written even if the output is up to date")
 }
 
 func main() {
 	pflag.Parse()
+	if *jsonVersion {
+		if err := omen.WriteBuildInfoJSON(os.Stdout); err != nil {
+			fmt.Printf("Error writing version: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	} else if *version {
+		fmt.Println(omen.Version)
+		return
+	}
 	// validate arguments
 	if len(pflag.Args()) != 1 {
-		fmt.Printf("Usage: %s <path_to_mn_result_raw_directory>\n", os.Args[0])
+		fmt.Printf("Usage: %s <path_to_mn_result_raw_directory | path_to_run_directory>\n", os.Args[0])
 		fmt.Printf("Example: %s ../1_spawn_topology/mn_result_raw\n", os.Args[0])
+		fmt.Printf("Example: %s ../1_spawn_topology/mn_result_raw/20251103_143345_1\n", os.Args[0])
+		fmt.Printf("Example: %s --list-runs ../1_spawn_topology/mn_result_raw\n", os.Args[0])
 		os.Exit(1)
 	}
 	inputDir := pflag.Arg(0)
-
-	// Find the latest subdirectory
-	latestDir, err := findLatestDirectory(inputDir)
-	if err != nil {
-		fmt.Printf("Error finding latest directory: %v\n", err)
+	if *listRuns {
+		printRunDirectories(inputDir)
+		return
+	}
+	if *failFast && *collectWarnings {
+		fmt.Println("--fail-fast and --collect-warnings are mutually exclusive")
+		os.Exit(1)
+	}
+	if *retain < 0 {
+		fmt.Println("--retain cannot be negative")
+		os.Exit(1)
+	}
+	if *parquetOut && *lowMemory {
+		fmt.Println("--parquet is not supported with --low-memory")
+		os.Exit(1)
+	}
+	if f, found := edgeFilters[*edgeFilterName]; !found {
+		fmt.Printf("Invalid --edge-filter %q: must be one of all, no-sta-sta, or ap-sta-only\n", *edgeFilterName)
+		os.Exit(1)
+	} else {
+		keepEdge = f
+	}
+	defer reportFileIssues()
+	if r, err := parseDelimiter(*delimiter); err != nil {
+		fmt.Printf("Invalid --delimiter %q: %v\n", *delimiter, err)
 		os.Exit(1)
+	} else {
+		csvComma = r
+	}
+
+	var tests []models.TestDefinition
+	if *inputTopo != "" {
+		var err error
+		if tests, err = readTestDefinitions(*inputTopo); err != nil {
+			fmt.Printf("Error reading tests from --input: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Use the input directory if it is itself a run directory; otherwise, find the latest run within it
+	latestDir := inputDir
+	if _, _, ok := omen.ParseRunDirName(filepath.Base(filepath.Clean(inputDir))); !ok {
+		var err error
+		if latestDir, err = findLatestDirectory(inputDir); err != nil {
+			fmt.Printf("Error finding latest directory: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	// prepare output dir
@@ -54,18 +166,239 @@ func main() {
 		os.Exit(1)
 	}
 
-	fmt.Printf("Processing files in: %s\n", latestDir)
+	// skip reprocessing if the output is already up to date
+	hash, err := inputsHash(latestDir)
+	if err != nil {
+		fmt.Printf("Error hashing inputs: %v\n", err)
+		os.Exit(1)
+	}
+	if !*force && *dumpParsedPath == "" && alreadyCoalesced(*outputDir, hash) {
+		fmt.Printf("%s is already up to date with %s; skipping (use --force to reprocess)\n", *outputDir, latestDir)
+		retainRuns(inputDir, latestDir)
+		return
+	}
+	if err := removeCoalescedMarker(*outputDir); err != nil {
+		fmt.Printf("Error removing stale %s marker: %v\n", coalescedMarker, err)
+		os.Exit(1)
+	}
+	if err := os.Remove(filepath.Join(*outputDir, manifestFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Printf("Error removing stale %s: %v\n", manifestFile, err)
+		os.Exit(1)
+	}
+	// failures exit directly, so the manifest and marker are only written once processing completes;
+	// a run that parsed nothing produced no output to describe, so it gets neither
+	var noneParsed bool
+	outputs.Source = filepath.ToSlash(latestDir)
+	defer func() {
+		if noneParsed {
+			return
+		}
+		if err := writeManifest(*outputDir, outputs); err != nil {
+			fmt.Printf("Warning: failed to write %s: %v\n", manifestFile, err)
+		} else {
+			infof("Manifest written to: %s\n", filepath.Join(*outputDir, manifestFile))
+		}
+		if err := writeCoalescedMarker(*outputDir, hash); err != nil {
+			fmt.Printf("Warning: failed to write %s marker: %v\n", coalescedMarker, err)
+		}
+	}()
+
+	infof("Processing files in: %s\n", latestDir)
+
+	if *lowMemory {
+		noneParsed = !processStreaming(latestDir, tests)
+		retainRuns(inputDir, latestDir)
+		return
+	}
 
 	// Process all .txt files
-	parsed, err := processRawFileDirectory(latestDir)
+	parsed, err := processRawFileDirectory(latestDir, *only, printProgress)
 	if err != nil {
 		fmt.Printf("Error processing files: %v\n", err)
 		os.Exit(1)
-	} else if len(parsed) == 0 {
-		fmt.Printf("no raw files were parsed\n")
+	}
+	if *dumpParsedPath != "" {
+		if err := dumpParsed(*dumpParsedPath, parsed); err != nil {
+			fmt.Printf("Error writing --dump-parsed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Parsed records of %d raw files written to: %s\n", len(parsed), *dumpParsedPath)
+	}
+	if len(parsed) == 0 {
+		noneParsed = true
+		reportNoneParsed()
 		return
 	}
 
+	if *only >= 0 {
+		infof("--only %d given; skipping cumulative CSVs\n", *only)
+	} else {
+		writeCumulativeCSVs(parsed)
+		if *parquetOut {
+			writeParquetFiles(parsed)
+		}
+		if *inputTopo != "" {
+			produced := make(map[uint]bool, len(parsed))
+			for _, p := range parsed {
+				produced[p.Timeframe] = true
+			}
+			writeTestsFile(tests, produced)
+		}
+	}
+
+	// write a folder for each timeframe
+	for i := range parsed {
+		writeTimeframe(parsed[i])
+	}
+
+	retainRuns(inputDir, latestDir)
+}
+
+// processStreaming parses and writes each timeframe in turn, discarding its records before moving to the next.
+// The cumulative CSVs are built incrementally.
+// Reports whether any raw file was parsed. Exits on failure.
+func processStreaming(latestDir string, tests []models.TestDefinition) bool {
+	var cum *cumulativeCSVs
+	if *only >= 0 {
+		infof("--only %d given; skipping cumulative CSVs\n", *only)
+	} else {
+		var err error
+		if cum, err = openCumulativeCSVs(*outputDir); err != nil {
+			fmt.Printf("Error creating cumulative CSVs: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var dump *parsedDump
+	if *dumpParsedPath != "" {
+		var err error
+		if dump, err = createParsedDump(*dumpParsedPath); err != nil {
+			fmt.Printf("Error creating --dump-parsed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	produced := make(map[uint]bool)
+	err := walkRawFileDirectory(latestDir, *only, printProgress, func(p models.ParsedRawFile) error {
+		if dump != nil {
+			if err := dump.add(p); err != nil {
+				return fmt.Errorf("failed to append timeframe %d to --dump-parsed: %w", p.Timeframe, err)
+			}
+		}
+		if cum != nil {
+			if err := cum.add(p); err != nil {
+				return fmt.Errorf("failed to append timeframe %d to cumulative CSVs: %w", p.Timeframe, err)
+			}
+		}
+		writeTimeframe(p)
+		produced[p.Timeframe] = true
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error processing files: %v\n", err)
+		os.Exit(1)
+	}
+	if dump != nil {
+		if err := dump.close(); err != nil {
+			fmt.Printf("Error writing --dump-parsed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Parsed records of %d raw files written to: %s\n", dump.count, *dumpParsedPath)
+	}
+	if cum != nil {
+		if err := cum.close(); err != nil {
+			fmt.Printf("Error writing cumulative CSVs: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Successfully processed %d ping records (%d pairs), %d stations, %d access points, %d association events, and %d resource samples\n"+
+			"Cumulative results written to: %s\n", cum.pingCount, cum.pingStatCount, cum.staCount, cum.apCount, cum.assocCount, cum.resourceCount, *outputDir)
+		for _, name := range []string{fullPingDataCSV, pingStatsCSV, fullIWDataCSV, associationsCSV, resourcesCSV} {
+			outputs.addFile(*outputDir, csvOutputPath(filepath.Join(*outputDir, name)))
+		}
+		if *inputTopo != "" {
+			writeTestsFile(tests, produced)
+		}
+	}
+	if len(produced) == 0 {
+		reportNoneParsed()
+		return false
+	}
+	return true
+}
+
+// reportNoneParsed informs the user that no raw files were parsed.
+// Exits with an error if a specific timeframe was requested.
+func reportNoneParsed() {
+	if *only >= 0 {
+		fmt.Printf("timeframe%d.txt was not found or could not be parsed\n", *only)
+		os.Exit(1)
+	}
+	fmt.Printf("no raw files were parsed\n")
+}
+
+// writeTimeframe writes the subdirectory of CSVs for a single timeframe.
+// Exits on failure.
+func writeTimeframe(p models.ParsedRawFile) {
+	tf := p.Timeframe
+	// create subdir for this timeframe
+	tfDir := path.Join(*outputDir, "timeframe"+strconv.FormatUint(uint64(tf), 10))
+	if err := os.Mkdir(tfDir, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		fmt.Printf("failed to create directory %s: %v\n", tfDir, err)
+		os.Exit(1)
+	}
+
+	infof("writing data from timeframe %d\n", tf)
+	if errs := p.Validate(); len(errs) > 0 {
+		if *strict {
+			for _, err := range errs {
+				fmt.Printf("Error: %s: %v\n", filepath.Base(p.Path), err)
+			}
+			fmt.Printf("timeframe %d is inconsistent; not writing it (--strict)\n", tf)
+			os.Exit(1)
+		}
+		for _, err := range errs {
+			warnFile(filepath.Base(p.Path), "%v", err)
+		}
+	}
+	// process nodes for this timeframe
+	nodes := timeframeNodes(p)
+	err := writeNodesCSV(p, nodes, tfDir)
+	if err != nil {
+		fmt.Printf("Error processing nodes output: %v\n", err)
+		os.Exit(1)
+	}
+
+	// process edges for this timeframe
+	edges := timeframeEdges(p)
+	if err := writeEdgesCSV(p, edges, tfDir); err != nil {
+		fmt.Printf("Error processing edges output: %v\n", err)
+		os.Exit(1)
+	}
+	written := []string{csvOutputPath(path.Join(tfDir, "nodes.csv")), csvOutputPath(path.Join(tfDir, "edges.csv"))}
+	if *graphMLOut {
+		pth := path.Join(tfDir, graphMLFile)
+		if err := writeGraphML(pth, tf, nodes, edges); err != nil {
+			fmt.Printf("Error writing GraphML for timeframe %d: %v\n", tf, err)
+			os.Exit(1)
+		}
+		infof("\tGraphML for timeframe %d written to: %s\n", tf, pth)
+		written = append(written, pth)
+	}
+	// write position files into each timeframe
+	pth := path.Join(tfDir, "ping_data_movement_"+strconv.FormatInt(int64(tf), 10)+".csv")
+	if _, err := writeMovementCSV(pth, p); err != nil {
+		fmt.Printf("failed to write ping_data_movement file for timeframe %d: %v\n", tf, err)
+		os.Exit(1)
+	}
+	infof("\tPing CSV for timeframe %d written to: %s\n", tf, csvOutputPath(pth))
+	written = append(written, csvOutputPath(pth))
+	outputs.addTimeframe(*outputDir, tfDir, p, written)
+
+}
+
+// writeCumulativeCSVs writes the CSVs that span all timeframes into the output directory.
+// Exits on failure.
+func writeCumulativeCSVs(parsed []models.ParsedRawFile) {
 	{ // write complete ping data from all parsed models
 		op := filepath.Join(*outputDir, fullPingDataCSV)
 		count, err := writePingAllFull(op, parsed)
@@ -74,7 +407,19 @@ func main() {
 			os.Exit(1)
 		}
 		fmt.Printf("Successfully processed %d ping records\n"+
-			"Pingall results written to: %s\n", count, op)
+			"Pingall results written to: %s\n", count, csvOutputPath(op))
+		outputs.addFile(*outputDir, csvOutputPath(op))
+	}
+	{ // write ping data from all parsed models, aggregated over repetitions
+		op := filepath.Join(*outputDir, pingStatsCSV)
+		count, err := writePingStatsFull(op, parsed)
+		if err != nil {
+			fmt.Printf("Error writing ping stats CSV: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Successfully aggregated %d ping pairs\n"+
+			"Ping statistics written to: %s\n", count, csvOutputPath(op))
+		outputs.addFile(*outputDir, csvOutputPath(op))
 	}
 	{ // write complete IW data from all parsed models
 		op := filepath.Join(*outputDir, fullIWDataCSV)
@@ -84,69 +429,189 @@ func main() {
 			os.Exit(1)
 		}
 		fmt.Printf("Successfully processed %d stations and %d access points\n", staCount, apCount)
-		fmt.Printf("IW results written to: %s\n", op)
+		fmt.Printf("IW results written to: %s\n", csvOutputPath(op))
+		outputs.addFile(*outputDir, csvOutputPath(op))
 	}
-	// write a folder for each timeframe
-	for tf := range parsed {
-		// create subdir for this timeframe
-		tfDir := path.Join(*outputDir, "timeframe"+strconv.FormatUint(uint64(tf), 10))
-		if err := os.Mkdir(tfDir, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
-			fmt.Printf("failed to create directory %s: %v\n", tfDir, err)
+	{ // write association events from all parsed models
+		op := filepath.Join(*outputDir, associationsCSV)
+		count, err := writeAssociationsFull(op, parsed)
+		if err != nil {
+			fmt.Printf("Error writing associations CSV: %v\n", err)
 			os.Exit(1)
 		}
-
-		fmt.Printf("writing data from timeframe %d\n", tf)
-		// process nodes for this timeframe
-		err := writeNodesCSV(parsed[tf], tfDir)
+		fmt.Printf("Successfully processed %d association events\n"+
+			"Association events written to: %s\n", count, csvOutputPath(op))
+		outputs.addFile(*outputDir, csvOutputPath(op))
+	}
+	{ // write resource usage from all parsed models
+		op := filepath.Join(*outputDir, resourcesCSV)
+		count, err := writeResourcesFull(op, parsed)
 		if err != nil {
-			fmt.Printf("Error processing nodes output: %v\n", err)
+			fmt.Printf("Error writing resources CSV: %v\n", err)
 			os.Exit(1)
 		}
+		fmt.Printf("Successfully processed %d resource samples\n"+
+			"Resource usage written to: %s\n", count, csvOutputPath(op))
+		outputs.addFile(*outputDir, csvOutputPath(op))
+	}
+}
 
-		// process edges for this timeframe
-		if err := writeEdgesCSV(parsed[tf], tfDir); err != nil {
-			fmt.Printf("Error processing edges output: %v\n", err)
-			os.Exit(1)
-		}
-		// write position files into each timeframe
-		pth := path.Join(tfDir, "ping_data_movement_"+strconv.FormatInt(int64(tf), 10)+".csv")
-		if err := writeMovementCSV(pth, uint64(tf), parsed[tf]); err != nil {
-			fmt.Printf("failed to write ping_data_movement file for timeframe %d: %v\n", tf, err)
-			os.Exit(1)
-		}
-		fmt.Printf("\tPing CSV for timeframe %d written to: %s\n", tf, pth)
+// writeParquetFiles writes the Parquet forms of the cumulative ping and IW data into the output directory.
+// Exits on failure.
+func writeParquetFiles(parsed []models.ParsedRawFile) {
+	op := filepath.Join(*outputDir, fullPingDataParquet)
+	count, err := writePingParquet(op, parsed)
+	if err != nil {
+		fmt.Printf("Error writing ping Parquet: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%d ping records written to: %s\n", count, op)
+	outputs.addFile(*outputDir, op)
 
+	op = filepath.Join(*outputDir, fullIWDataParquet)
+	staCount, apCount, err := writeIWParquet(op, parsed)
+	if err != nil {
+		fmt.Printf("Error writing iw Parquet: %v\n", err)
+		os.Exit(1)
 	}
+	fmt.Printf("%d stations and %d access points written to: %s\n", staCount, apCount, op)
+	outputs.addFile(*outputDir, op)
+}
 
+// writeTestsFile writes the tests declared in --input into the output directory.
+// Exits on failure.
+func writeTestsFile(tests []models.TestDefinition, produced map[uint]bool) {
+	op := filepath.Join(*outputDir, testsCSV)
+	if err := writeTestsCSV(op, tests, produced); err != nil {
+		fmt.Printf("Error writing tests CSV: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Successfully processed %d tests\n"+
+		"Tests written to: %s\n", len(tests), csvOutputPath(op))
+	outputs.addFile(*outputDir, csvOutputPath(op))
 }
 
-// findLatestDirectory
-func findLatestDirectory(basePath string) (string, error) {
+// runDirNames returns the names of the subdirectories of basePath.
+func runDirNames(basePath string) ([]string, error) {
 	entries, err := os.ReadDir(basePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read directory %s: %v", basePath, err)
-	} else if len(entries) <= 0 {
-		return "", fmt.Errorf("no subdirectories found in %s", basePath)
+		return nil, fmt.Errorf("failed to read directory %s: %v", basePath, err)
 	}
-
-	var (
-		newestTime time.Time
-		newestDir  string
-	)
+	var names []string
 	for _, entry := range entries {
 		if entry.IsDir() {
-			v, err := time.Parse(directoryNameFormat, entry.Name())
-			if err != nil { // if an error occurs, skip it
-				continue
-			} else if newestTime.Before(v) {
-				newestTime = v
-				newestDir = entry.Name()
-			}
+			names = append(names, entry.Name())
 		}
 	}
-	if newestDir == "" {
+	return names, nil
+}
+
+// findLatestDirectory returns the path to the newest run directory (see omen.ParseRunDirName) within basePath.
+func findLatestDirectory(basePath string) (string, error) {
+	names, err := runDirNames(basePath)
+	if err != nil {
+		return "", err
+	} else if len(names) <= 0 {
+		return "", fmt.Errorf("no subdirectories found in %s", basePath)
+	}
+	newestDir, ok := omen.LatestRunDirName(names)
+	if !ok {
 		return "", fmt.Errorf("no subdirectories with the correct format found in %s", basePath)
 	}
 
 	return path.Join(basePath, newestDir), nil
 }
+
+// runListing describes a run directory, as printed by --list-runs.
+type runListing struct {
+	name      string
+	timestamp time.Time
+	files     uint // regular files within the directory (ex: timeframe0.txt)
+}
+
+// listRunDirectories returns the run directories within basePath, newest first.
+func listRunDirectories(basePath string) ([]runListing, error) {
+	names, err := runDirNames(basePath)
+	if err != nil {
+		return nil, err
+	}
+	var runs []runListing
+	for _, name := range omen.SortRunDirNames(names) {
+		ts, _, _ := omen.ParseRunDirName(name)
+		entries, err := os.ReadDir(filepath.Join(basePath, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %v", name, err)
+		}
+		var files uint
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				files += 1
+			}
+		}
+		runs = append(runs, runListing{name, ts, files})
+	}
+	return runs, nil
+}
+
+// printRunDirectories prints the run directories within basePath, newest first.
+// Exits on failure.
+func printRunDirectories(basePath string) {
+	runs, err := listRunDirectories(basePath)
+	if err != nil {
+		fmt.Printf("Error listing runs: %v\n", err)
+		os.Exit(1)
+	} else if len(runs) == 0 {
+		fmt.Printf("no run directories found in %s\n", basePath)
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN\tSTARTED\tFILES")
+	for _, r := range runs {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", r.name, r.timestamp.Format(time.DateTime), r.files)
+	}
+	tw.Flush()
+}
+
+// retainRuns prunes the run directories beside processedDir to the --retain newest (if given), sparing processedDir regardless.
+// inputDir is the directory given as input: either the base of the run directories or processedDir itself.
+// As the output has already been written, failures are warned about rather than exiting.
+func retainRuns(inputDir, processedDir string) {
+	if *retain == 0 {
+		return
+	}
+	base := inputDir
+	if filepath.Clean(inputDir) == filepath.Clean(processedDir) {
+		base = filepath.Dir(filepath.Clean(processedDir))
+	}
+	removed, err := pruneRunDirectories(base, *retain, filepath.Base(filepath.Clean(processedDir)))
+	for _, name := range removed {
+		infof("Removed run directory: %s\n", filepath.Join(base, name))
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to prune run directories: %v\n", err)
+	}
+}
+
+// pruneRunDirectories deletes all but the retain newest run directories (see omen.ParseRunDirName) within basePath, except for keep.
+// Entries not named as runs (and symlinks to directories) are never touched.
+// Returns the names of the directories deleted.
+func pruneRunDirectories(basePath string, retain int, keep string) (removed []string, _ error) {
+	names, err := runDirNames(basePath)
+	if err != nil {
+		return nil, err
+	}
+	sorted := omen.SortRunDirNames(names)
+	if len(sorted) <= retain {
+		return nil, nil
+	}
+	for _, name := range sorted[retain:] {
+		if name == keep {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(basePath, name)); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", name, err)
+		}
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	omen "Omen"
 	"os"
 	"path"
+	"slices"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -14,7 +17,7 @@ func Test_findLatestDirectory(t *testing.T) {
 	)
 	var (
 		// mostRecent is the name of the highest timestamp in each directory
-		mostRecent string = time.Now().Format(directoryNameFormat)
+		mostRecent string = time.Now().Format(omen.RunDirNameFormat)
 	)
 
 	// generate a few directory structures to test on
@@ -40,7 +43,7 @@ func Test_findLatestDirectory(t *testing.T) {
 			t.Fatal(err)
 		} else if err := os.Mkdir(path.Join(twoFileDir, mostRecent), tempFilePerm); err != nil {
 			t.Fatal(err)
-		} else if err := os.Mkdir(path.Join(twoFileDir, time.Now().AddDate(0, -1, 0).Format(directoryNameFormat)), tempFilePerm); err != nil {
+		} else if err := os.Mkdir(path.Join(twoFileDir, time.Now().AddDate(0, -1, 0).Format(omen.RunDirNameFormat)), tempFilePerm); err != nil {
 			t.Fatal(err)
 		}
 	}
@@ -49,9 +52,9 @@ func Test_findLatestDirectory(t *testing.T) {
 			t.Fatal(err)
 		} else if err := os.Mkdir(path.Join(threeFileDir, mostRecent), tempFilePerm); err != nil {
 			t.Fatal(err)
-		} else if err := os.Mkdir(path.Join(threeFileDir, time.Now().AddDate(0, -3, 0).Format(directoryNameFormat)), tempFilePerm); err != nil {
+		} else if err := os.Mkdir(path.Join(threeFileDir, time.Now().AddDate(0, -3, 0).Format(omen.RunDirNameFormat)), tempFilePerm); err != nil {
 			t.Fatal(err)
-		} else if err := os.Mkdir(path.Join(threeFileDir, time.Now().Add(-3*time.Minute).Format(directoryNameFormat)), tempFilePerm); err != nil {
+		} else if err := os.Mkdir(path.Join(threeFileDir, time.Now().Add(-3*time.Minute).Format(omen.RunDirNameFormat)), tempFilePerm); err != nil {
 			t.Fatal(err)
 		} else if err := os.Mkdir(path.Join(threeFileDir, "bad_sub_dir_name"), tempFilePerm); err != nil {
 			t.Fatal(err)
@@ -100,3 +103,96 @@ func Test_findLatestDirectory(t *testing.T) {
 		})
 	}
 }
+
+func Test_findLatestDirectory_suffixed(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20251103_143345", "20251103_143345_2", "20251103_143345_1", "20251102_235959_9"} {
+		if err := os.Mkdir(path.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := findLatestDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := path.Join(dir, "20251103_143345_2"); got != want {
+		t.Errorf("findLatestDirectory() = %v, want %v", got, want)
+	}
+}
+
+func Test_listRunDirectories(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]int{"20251103_143345": 2, "20251103_143345_1": 0, "20251102_235959": 1, "bad_sub_dir_name": 3}
+	for name, n := range files {
+		if err := os.Mkdir(path.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		for i := range n {
+			if err := os.WriteFile(path.Join(dir, name, "timeframe"+strconv.Itoa(i)+".txt"), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// subdirectories are not counted as files
+	if err := os.Mkdir(path.Join(dir, "20251102_235959", "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := listRunDirectories(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name  string
+		files uint
+	}{{"20251103_143345_1", 0}, {"20251103_143345", 2}, {"20251102_235959", 1}}
+	if len(got) != len(want) {
+		t.Fatalf("listRunDirectories() returned %d runs, want %d: %v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].name != w.name || got[i].files != w.files {
+			t.Errorf("run %d = (%s, %d files), want (%s, %d files)", i, got[i].name, got[i].files, w.name, w.files)
+		}
+	}
+	if ts := got[1].timestamp.Format(omen.RunDirNameFormat); ts != "20251103_143345" {
+		t.Errorf("run 1 timestamp = %s, want 20251103_143345", ts)
+	}
+}
+
+func Test_pruneRunDirectories(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20251103_143345", "20251103_143345_1", "20251102_235959", "20251101_000000", "keep_me"} {
+		if err := os.Mkdir(path.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// only directories named as runs are candidates
+	if err := os.WriteFile(path.Join(dir, "20251001_000000"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the processed run (20251101_000000) is spared, though it is the oldest
+	removed, err := pruneRunDirectories(dir, 2, "20251101_000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"20251102_235959"}; !slices.Equal(removed, want) {
+		t.Errorf("removed %v, want %v", removed, want)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var remaining []string
+	for _, e := range entries {
+		remaining = append(remaining, e.Name())
+	}
+	if want := []string{"20251001_000000", "20251101_000000", "20251103_143345", "20251103_143345_1", "keep_me"}; !slices.Equal(remaining, want) {
+		t.Errorf("remaining entries = %v, want %v", remaining, want)
+	}
+
+	// retaining more runs than exist removes nothing
+	if removed, err := pruneRunDirectories(dir, 10, ""); err != nil || len(removed) != 0 {
+		t.Errorf("pruneRunDirectories(retain=10) = %v, %v; want nothing removed", removed, err)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"Omen/modules/2_mn_raw_output_processing/models"
+)
+
+// manifestFile is the name of the file, within the output directory, describing everything written into it.
+const manifestFile string = "manifest.json"
+
+// manifestSchemaVersion is the version of the manifest's structure. Bump it when fields are removed or change meaning.
+const manifestSchemaVersion int = 1
+
+// manifest describes the output of a run, so downstream tooling (ex: the coordinator) can discover it rather than assume file names.
+// Paths are relative to the output directory and slash-separated.
+type manifest struct {
+	SchemaVersion int                 `json:"schema_version"`
+	GeneratedAt   time.Time           `json:"generated_at"`
+	Source        string              `json:"source"`     // run directory the raw files were read from
+	Files         []string            `json:"files"`      // files spanning all timeframes (ex: ping_data.csv)
+	Timeframes    []manifestTimeframe `json:"timeframes"` // in the order they were written
+}
+
+// manifestTimeframe describes the output of a single timeframe.
+type manifestTimeframe struct {
+	Timeframe uint           `json:"timeframe"`
+	Dir       string         `json:"dir"`    // subdirectory holding the timeframe's files
+	Source    string         `json:"source"` // raw file the timeframe was parsed from
+	Counts    manifestCounts `json:"counts"`
+	Files     []string       `json:"files"`
+}
+
+// manifestCounts are the number of records parsed from a timeframe's raw file.
+type manifestCounts struct {
+	Movements uint `json:"movements"`
+	Pings     uint `json:"pings"`
+	Stations  uint `json:"stations"`
+	APs       uint `json:"aps"`
+}
+
+// outputs collects the manifest as files are written; it is written out by writeManifest once processing completes.
+var outputs manifest
+
+// relOutputPath returns pth relative to outDir (slash-separated), or pth itself if it is not within outDir.
+func relOutputPath(outDir, pth string) string {
+	rel, err := filepath.Rel(outDir, pth)
+	if err != nil {
+		return filepath.ToSlash(pth)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// addFile records the file at pth (within outDir) as spanning all timeframes.
+func (m *manifest) addFile(outDir, pth string) {
+	m.Files = append(m.Files, relOutputPath(outDir, pth))
+}
+
+// addTimeframe records the timeframe parsed into p as written to dir (within outDir), as the files at paths.
+func (m *manifest) addTimeframe(outDir, dir string, p models.ParsedRawFile, paths []string) {
+	tf := manifestTimeframe{
+		Timeframe: p.Timeframe,
+		Dir:       relOutputPath(outDir, dir),
+		Source:    filepath.ToSlash(p.Path),
+		Counts: manifestCounts{
+			Movements: uint(len(p.Movements)),
+			Pings:     uint(len(p.Pings)),
+			Stations:  uint(len(p.Stations)),
+			APs:       uint(len(p.APs)),
+		},
+		Files: make([]string, len(paths)),
+	}
+	for i, pth := range paths {
+		tf.Files[i] = relOutputPath(outDir, pth)
+	}
+	m.Timeframes = append(m.Timeframes, tf)
+}
+
+// writeManifest stamps m with the schema version and the current time, then writes it as indented JSON into outDir.
+func writeManifest(outDir string, m manifest) error {
+	m.SchemaVersion = manifestSchemaVersion
+	m.GeneratedAt = time.Now().UTC()
+	if m.Files == nil { // list, rather than null, nothing written
+		m.Files = []string{}
+	}
+	if m.Timeframes == nil {
+		m.Timeframes = []manifestTimeframe{}
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, manifestFile), append(data, '\n'), 0644)
+}
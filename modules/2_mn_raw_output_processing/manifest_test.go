@@ -0,0 +1,86 @@
+package main
+
+import (
+	"Omen/modules/2_mn_raw_output_processing/models"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func Test_writeManifest(t *testing.T) {
+	outDir := t.TempDir()
+	var m manifest
+	m.Source = "mn_result_raw/20251103_143345"
+	m.addFile(outDir, filepath.Join(outDir, fullPingDataCSV))
+	m.addTimeframe(outDir, filepath.Join(outDir, "timeframe1"), models.ParsedRawFile{
+		Timeframe: 1,
+		Path:      "mn_result_raw/20251103_143345/timeframe1.txt",
+		Movements: []models.MovementRecord{{NodeName: "sta1"}},
+		Pings:     []models.PingRecord{{Src: "sta1", Dst: "ap1"}, {Src: "ap1", Dst: "sta1"}},
+		Stations:  []models.StationRecord{{StationName: "sta1"}},
+		APs:       []models.AccessPointRecord{{APName: "ap1"}},
+	}, []string{
+		filepath.Join(outDir, "timeframe1", "nodes.csv"),
+		filepath.Join(outDir, "timeframe1", "ping_data_movement_1.csv"),
+	})
+
+	before := time.Now().UTC()
+	if err := writeManifest(outDir, m); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, manifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got manifest
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.SchemaVersion != manifestSchemaVersion {
+		t.Errorf("schema version = %d, want %d", got.SchemaVersion, manifestSchemaVersion)
+	}
+	if got.GeneratedAt.Before(before.Truncate(time.Second)) {
+		t.Errorf("generated at %v, before the manifest was written (%v)", got.GeneratedAt, before)
+	}
+	if want := []string{fullPingDataCSV}; !slices.Equal(got.Files, want) {
+		t.Errorf("files = %q, want %q", got.Files, want)
+	}
+	if len(got.Timeframes) != 1 {
+		t.Fatalf("expected 1 timeframe, got %d", len(got.Timeframes))
+	}
+	tf := got.Timeframes[0]
+	if tf.Timeframe != 1 || tf.Dir != "timeframe1" || tf.Source != "mn_result_raw/20251103_143345/timeframe1.txt" {
+		t.Errorf("unexpected timeframe %+v", tf)
+	}
+	if want := (manifestCounts{Movements: 1, Pings: 2, Stations: 1, APs: 1}); tf.Counts != want {
+		t.Errorf("counts = %+v, want %+v", tf.Counts, want)
+	}
+	if want := []string{"timeframe1/nodes.csv", "timeframe1/ping_data_movement_1.csv"}; !slices.Equal(tf.Files, want) {
+		t.Errorf("timeframe files = %q, want %q", tf.Files, want)
+	}
+}
+
+// A manifest of nothing must list nothing, rather than null.
+func Test_writeManifest_empty(t *testing.T) {
+	outDir := t.TempDir()
+	if err := writeManifest(outDir, manifest{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, manifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"files", "timeframes"} {
+		if v, ok := got[key].([]any); !ok || len(v) != 0 {
+			t.Errorf("%s = %v, want an empty list", key, got[key])
+		}
+	}
+}
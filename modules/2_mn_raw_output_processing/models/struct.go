@@ -4,12 +4,14 @@ package models
 // ParsedRawFile is the collection of records pulled a raw timeframeX.txt file.
 // Each ParsedRawFile should represent exactly 1 timeframe.
 type ParsedRawFile struct {
-	Timeframe uint
-	Path      string // file path
-	Movements []MovementRecord
-	Pings     []PingRecord
-	Stations  []StationRecord
-	APs       []AccessPointRecord
+	Timeframe    uint
+	Path         string // file path
+	Movements    []MovementRecord
+	Pings        []PingRecord
+	Stations     []StationRecord
+	APs          []AccessPointRecord
+	Associations []AssociationRecord
+	Resources    []ResourceRecord
 }
 
 // A MovementRecord represents a single move action performed on a node during the last run.
@@ -20,6 +22,35 @@ type MovementRecord struct {
 	TestFile       string
 }
 
+// An AssociationRecord represents a station associating with or disassociating from an AP during a timeframe.
+type AssociationRecord struct {
+	Timeframe string
+	Station   string
+	AP        string
+	Event     string // "associated" or "disassociated"
+	TestFile  string
+}
+
+// A ResourceRecord represents the CPU and memory usage of a node's shell process, sampled after a timeframe's pingall.
+// Values the driver could not sample are empty.
+type ResourceRecord struct {
+	Timeframe string
+	TestFile  string
+	NodeName  string
+	PID       string
+	CPUPct    string // percent of a single CPU
+	RSSKB     string // resident set size, in KiB
+}
+
+// A TestDefinition is a test as declared in the "tests" section of the input topology.
+type TestDefinition struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Timeframe uint   `json:"timeframe"`
+	Node      string `json:"node"`     // for "node movements" tests
+	Position  string `json:"position"` // for "node movements" tests
+}
+
 type PingRecord struct {
 	MovementNumber string
 	TestFile       string
@@ -29,62 +60,70 @@ type PingRecord struct {
 	Rx             string
 	LossPct        string
 	AvgRttMs       string
+	Timestamp      string // RFC3339 wall-clock time of the pingall block; empty if the raw file predates timestamps
 }
 
+// A StationRecord is the iw output of a single station during a timeframe.
+// Fields are tagged with their column of the cumulative IW CSV.
 type StationRecord struct {
-	TestFile    string
-	StationName string
-	ConnectedTo string
-	SSID        string
-	Freq        string
-	RXBytes     string
-	RXPackets   string
-	TXBytes     string
-	TXPackets   string
-	Signal      string
-	RxBitrate   string
-	TxBitrate   string
-	BssFlags    string
-	DtimPeriod  string
-	BeaconInt   string
+	TestFile        string `csv:"test_file"`
+	StationName     string `csv:"device_name"`
+	ConnectedTo     string `csv:"connected_to"`      // MAC address of the AP the station is associated with
+	ConnectedToName string `csv:"connected_to_name"` // name of the AP (in the same timeframe) whose ether matches ConnectedTo; empty if none does
+	SSID            string `csv:"ssid"`
+	Freq            string `csv:"freq"`
+	RXBytes         string `csv:"rx_bytes"`
+	RXPackets       string `csv:"rx_packets"`
+	TXBytes         string `csv:"tx_bytes"`
+	TXPackets       string `csv:"tx_packets"`
+	Signal          string `csv:"signal"`
+	RxBitrate       string `csv:"rx_bitrate"`
+	TxBitrate       string `csv:"tx_bitrate"`
+	BssFlags        string `csv:"bss_flags"`
+	DtimPeriod      string `csv:"dtim_period"`
+	BeaconInt       string `csv:"beacon_int"`
 }
 
+// An AccessPointRecord is the interface output of a single access point during a timeframe.
+// Fields are tagged with their column of the cumulative IW CSV.
 type AccessPointRecord struct {
-	TestFile     string
-	APName       string
-	Interface    string
-	Flags        string
-	MTU          string
-	Ether        string
-	TxQueueLen   string
-	RXPackets    string
-	RXBytes      string
-	RXErrors     string
-	RXDropped    string
-	RXOverruns   string
-	RXFrame      string
-	TXPackets    string
-	TXBytes      string
-	TXErrors     string
-	TXDropped    string
-	TXOverruns   string
-	TXCarrier    string
-	TXCollisions string
+	TestFile     string `csv:"test_file"`
+	APName       string `csv:"device_name"`
+	Interface    string `csv:"interface"`
+	Flags        string `csv:"flags"`
+	MTU          string `csv:"mtu"`
+	Ether        string `csv:"ether"`
+	TxQueueLen   string `csv:"tx_queue_len"`
+	RXPackets    string `csv:"rx_packets"`
+	RXBytes      string `csv:"rx_bytes"`
+	RXErrors     string `csv:"rx_errors"`
+	RXDropped    string `csv:"rx_dropped"`
+	RXOverruns   string `csv:"rx_overruns"`
+	RXFrame      string `csv:"rx_frame"`
+	TXPackets    string `csv:"tx_packets"`
+	TXBytes      string `csv:"tx_bytes"`
+	TXErrors     string `csv:"tx_errors"`
+	TXDropped    string `csv:"tx_dropped"`
+	TXOverruns   string `csv:"tx_overruns"`
+	TXCarrier    string `csv:"tx_carrier"`
+	TXCollisions string `csv:"tx_collisions"`
 }
 
+// A NodeRecord is a row of a timeframe's nodes.csv; fields are tagged with their column.
 type NodeRecord struct {
-	ID             string
-	Title          string
-	Position       string
-	RXBytes        string
-	RXPackets      string
-	TXBytes        string
-	TXPackets      string
-	SuccessPctRate string
+	ID             string `csv:"id"`
+	Title          string `csv:"title"`
+	Position       string `csv:"position"`
+	RXBytes        string `csv:"rx_bytes"`
+	RXPackets      string `csv:"rx_packets"`
+	TXBytes        string `csv:"tx_bytes"`
+	TXPackets      string `csv:"tx_packets"`
+	SuccessPctRate string `csv:"success_pct_rate"`
 }
 
+// An EdgeRecord is a row of a timeframe's edges.csv; fields are tagged with their column.
 type EdgeRecord struct {
-	ID     string
-	Source string
-	Target string
+	ID     string `csv:"id"`
+	Source string `csv:"source"`
+	Target string `csv:"target"`
 }
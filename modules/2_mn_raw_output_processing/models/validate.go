@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Validate checks the records of the timeframe are consistent with one another, returning every inconsistency found:
+//
+//   - no two stations or access points share a name
+//   - every station and access point has a movement (from which its position is taken)
+//   - every movement is of a declared station or access point, to a position of the form "x,y,z" (optionally bracketed)
+//   - every ping is between declared stations and access points
+//
+// The ping check is skipped if no stations or access points were parsed (ex: a wired topology, which has no iw blocks).
+func (p ParsedRawFile) Validate() []error {
+	var errs []error
+
+	declared := make(map[string]string, len(p.Stations)+len(p.APs)) // node name -> type
+	declare := func(nodeType, name string) {
+		if prior, dup := declared[name]; dup {
+			errs = append(errs, fmt.Errorf("%s %s shares its name with another %s", nodeType, name, prior))
+			return
+		}
+		declared[name] = nodeType
+	}
+	for _, sta := range p.Stations {
+		declare("station", sta.StationName)
+	}
+	for _, ap := range p.APs {
+		declare("access point", ap.APName)
+	}
+
+	moved := make(map[string]bool, len(p.Movements))
+	undeclared := make(map[string]bool)
+	for _, mv := range p.Movements {
+		if _, found := declared[mv.NodeName]; !found {
+			undeclared[mv.NodeName] = true
+		}
+		moved[mv.NodeName] = true
+		if !validPosition(mv.Position) {
+			errs = append(errs, fmt.Errorf("movement %s of %s has an unparseable position %q", mv.MovementNumber, mv.NodeName, mv.Position))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(undeclared)) {
+		errs = append(errs, fmt.Errorf("moves node %q, which is not a declared station or access point. Is there a typo in the input JSON?", name))
+	}
+	unpositioned := func(nodeType, name string) {
+		if !moved[name] {
+			errs = append(errs, fmt.Errorf("no position recorded for %s %s", nodeType, name))
+			moved[name] = true // each name is only reported once, even if it is shared
+		}
+	}
+	for _, sta := range p.Stations {
+		unpositioned("station", sta.StationName)
+	}
+	for _, ap := range p.APs {
+		unpositioned("access point", ap.APName)
+	}
+
+	if len(declared) > 0 {
+		unknown := make(map[string]bool)
+		for _, ping := range p.Pings {
+			for _, node := range []string{ping.Src, ping.Dst} {
+				if _, found := declared[node]; !found {
+					unknown[node] = true
+				}
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(unknown)) {
+			errs = append(errs, fmt.Errorf("pings node %q, which is not a declared station or access point", name))
+		}
+	}
+
+	return errs
+}
+
+// validPosition reports whether pos is of the form "x,y,z" or "[x, y, z]", each coordinate being a number.
+func validPosition(pos string) bool {
+	coords := strings.Split(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(pos), "["), "]"), ",")
+	if len(coords) != 3 {
+		return false
+	}
+	for _, c := range coords {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(c), 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
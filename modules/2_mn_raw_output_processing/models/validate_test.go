@@ -0,0 +1,57 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsedRawFile_Validate(t *testing.T) {
+	valid := ParsedRawFile{
+		Stations: []StationRecord{{StationName: "sta1"}, {StationName: "sta2"}},
+		APs:      []AccessPointRecord{{APName: "ap1"}},
+		Movements: []MovementRecord{
+			{MovementNumber: "0", NodeName: "sta1", Position: "0,0,0"},
+			{MovementNumber: "0", NodeName: "sta2", Position: "[70.0, -5.0, 0.0]"},
+			{MovementNumber: "0", NodeName: "ap1", Position: " 1.5, 2, 3 "},
+		},
+		Pings: []PingRecord{{Src: "sta1", Dst: "sta2"}, {Src: "sta2", Dst: "ap1"}},
+	}
+	if errs := valid.Validate(); len(errs) != 0 {
+		t.Errorf("expected a consistent timeframe, got %v", errs)
+	}
+
+	// wired topologies have no iw blocks, so their pings cannot be checked
+	wired := ParsedRawFile{Pings: []PingRecord{{Src: "h1", Dst: "h2"}}}
+	if errs := wired.Validate(); len(errs) != 0 {
+		t.Errorf("expected pings to go unchecked without declared nodes, got %v", errs)
+	}
+
+	inconsistent := ParsedRawFile{
+		Stations: []StationRecord{{StationName: "sta1"}, {StationName: "sta2"}, {StationName: "sta1"}},
+		APs:      []AccessPointRecord{{APName: "ap1"}, {APName: "sta2"}},
+		Movements: []MovementRecord{
+			{MovementNumber: "1", NodeName: "sta1", Position: "0,0"},
+			{MovementNumber: "1", NodeName: "sat2", Position: "1,1,0"},
+			{MovementNumber: "1", NodeName: "sat2", Position: "2,2,0"},
+		},
+		Pings: []PingRecord{{Src: "sta1", Dst: "sta3"}, {Src: "sta3", Dst: "sta1"}},
+	}
+	want := []string{
+		"station sta1 shares its name with another station",
+		"access point sta2 shares its name with another station",
+		`movement 1 of sta1 has an unparseable position "0,0"`,
+		`moves node "sat2", which is not a declared station or access point`,
+		"no position recorded for station sta2",
+		"no position recorded for access point ap1",
+		`pings node "sta3", which is not a declared station or access point`,
+	}
+	errs := inconsistent.Validate()
+	if len(errs) != len(want) {
+		t.Fatalf("Validate() returned %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(errs[i].Error(), w) {
+			t.Errorf("error %d = %q, want %q", i, errs[i], w)
+		}
+	}
+}
@@ -3,7 +3,7 @@ package main
 import (
 	"Omen/modules/2_mn_raw_output_processing/models"
 	"bufio"
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"io/fs"
 	"maps"
@@ -11,26 +11,149 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
-// Regex patterns
-// Updated to handle both old format (70,10,0) and new format ([70.0, 10.0, 0.0])
+// Regex patterns common to every raw format version (see rawFormats for those that differ)
 var (
-	movementPattern     = regexp.MustCompile(`\[node movements\]\s+(\d+):\s+move\s+(\w+):\s+moving\s+\w+\s+->\s+\[?([0-9.,\s-]+)\]?`)
-	pingallStartPattern = regexp.MustCompile(`\[pingall_full\]\s+(\d+):`)
+	formatPattern       = regexp.MustCompile(`^\[format\]\s+(\d+)$`)
+	pingallStartPattern = regexp.MustCompile(`\[pingall_full\]\s+(\d+):(?:.*\bacross (\d+) nodes)?`)
 	csvHeaderPattern    = regexp.MustCompile(`^src,dst,tx,rx,loss_pct,avg_rtt_ms$`)
 	iwStartPattern      = regexp.MustCompile(`\[iw_stations\]`)
+	associationsPattern = regexp.MustCompile(`\[associations\]\s+(\d+):`)
+	resourcesPattern    = regexp.MustCompile(`\[resources\]\s+(\d+):`)
+	timestampPattern    = regexp.MustCompile(`^\[timestamp\]\s+(\d+):\s+(\S+)$`)
+	associationPattern  = regexp.MustCompile(`^(\w+) (associated with|disassociated from) (\w+)$`)
 	stationPattern      = regexp.MustCompile(`^--- Station (\w+) ---$`)
 	apPattern           = regexp.MustCompile(`^--- Access Point (\w+) ---$`)
+	// fields of a station's iw block
+	connectedPattern = regexp.MustCompile(`^Connected to ([0-9a-f:]+)`)
+	stationRXPattern = regexp.MustCompile(`RX: (\d+) bytes \((\d+) packets\)`)
+	stationTXPattern = regexp.MustCompile(`TX: (\d+) bytes \((\d+) packets\)`)
+	// fields of an access point's iw block (ifconfig output)
+	apFlagsPattern = regexp.MustCompile(`flags=(\d+)<([^>]+)>`)
+	apMTUPattern   = regexp.MustCompile(`mtu (\d+)`)
+	apTxqPattern   = regexp.MustCompile(`txqueuelen (\d+)`)
+	apEtherPattern = regexp.MustCompile(`ether ([0-9a-f:]+)`)
+	apRXPattern    = regexp.MustCompile(`RX packets (\d+)\s+bytes (\d+)`)
+	apRXErrPattern = regexp.MustCompile(`RX errors (\d+)\s+dropped (\d+)\s+overruns (\d+)\s+frame (\d+)`)
+	apTXPattern    = regexp.MustCompile(`TX packets (\d+)\s+bytes (\d+)`)
+	apTXErrPattern = regexp.MustCompile(`TX errors (\d+)\s+dropped (\d+)\s+overruns (\d+)\s+carrier (\d+)\s+collisions (\d+)`)
+	// CSI (ex: colors, cursor movement) and OSC (ex: window titles) escape sequences, as emitted by the remote PTY
+	ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[@-_]`)
 )
 
+// A rawFormat is the set of patterns that differ between versions of the driver's raw output format.
+// Each raw file declares its version on a leading "[format] N" line; files without one are version 0.
+type rawFormat struct {
+	movement *regexp.Regexp // groups: movement number, node, position (without brackets)
+}
+
+// rawFormats holds the patterns of each raw format version, indexed by version.
+// When the driver's output changes, append a version (and bump the driver's RAW_FORMAT_VERSION) rather than loosening a pattern.
+var rawFormats = []rawFormat{
+	// 0: files predating the format marker, whose positions may be either bare (70,10,0) or bracketed ([70.0, 10.0, 0.0])
+	{movement: regexp.MustCompile(`\[node movements\]\s+(\d+):\s+move\s+(\w+):\s+moving\s+\w+\s+->\s+\[?([0-9.,\s-]+)\]?`)},
+	// 1: positions are bracketed ([70.0, 10.0, 0.0])
+	{movement: regexp.MustCompile(`\[node movements\]\s+(\d+):\s+move\s+(\w+):\s+moving\s+\w+\s+->\s+\[([0-9.,\s-]+)\]`)},
+}
+
+// ErrUnsupportedFormat is returned by processFile when a raw file declares a format version newer than any in rawFormats.
+var ErrUnsupportedFormat = errors.New("unsupported raw format version")
+
+// A fileIssue is a problem found in a single raw file that did not halt processing.
+type fileIssue struct {
+	file  string
+	issue string
+}
+
+// fileIssues records every issue passed to warnFile, for the --collect-warnings report.
+var fileIssues []fileIssue
+
+// warnFile records an issue with the given raw file.
+// The issue is printed immediately unless --collect-warnings was given, in which case it is deferred to reportFileIssues.
+func warnFile(file, format string, a ...any) {
+	issue := fmt.Sprintf(format, a...)
+	fileIssues = append(fileIssues, fileIssue{file: file, issue: issue})
+	if !*collectWarnings {
+		fmt.Printf("WARNING: %s: %s\n", file, issue)
+	}
+}
+
+// reportFileIssues prints every issue recorded by warnFile, grouped by file.
+// Does nothing unless --collect-warnings was given.
+func reportFileIssues() {
+	if !*collectWarnings {
+		return
+	}
+	if len(fileIssues) == 0 {
+		infoln("No warnings were raised")
+		return
+	}
+	byFile := make(map[string][]string)
+	for _, fi := range fileIssues {
+		byFile[fi.file] = append(byFile[fi.file], fi.issue)
+	}
+	fmt.Printf("%d warning(s) across %d file(s):\n", len(fileIssues), len(byFile))
+	for _, file := range slices.Sorted(maps.Keys(byFile)) {
+		fmt.Printf("  %s:\n", file)
+		for _, issue := range byFile[file] {
+			fmt.Printf("    - %s\n", issue)
+		}
+	}
+}
+
+// ErrDuplicateNode is returned (under --strict) when a raw file contains more than one iw block for the same node.
+var ErrDuplicateNode = errors.New("duplicate node")
+
+// ErrIncompletePingall is returned (under --strict) when a pingall matrix has fewer or more rows than the N×(N-1) pairs of the node count the driver reported.
+// Typically, this means the pingall was interrupted, rather than that the missing links failed.
+var ErrIncompletePingall = errors.New("incomplete pingall matrix")
+
+// ProgressFunc is invoked as each raw file begins processing, allowing callers to report progress.
+// current is 1-indexed and total is the number of raw files that will be processed.
+type ProgressFunc func(current, total int, file string)
+
+// printProgress is the ProgressFunc used by the CLI, printing a line per file.
+func printProgress(_, _ int, file string) {
+	infof("Processing file: %s\n", file)
+}
+
 // processRawFileDirectory processes each .txt file (expecting 1 file per timeframe, of the nomenclature 'timeframeX.txt') in the given directory,
-// parsing the data into records for node movements, ping results, station info (via iw), and access point info (also via iw).
-func processRawFileDirectory(directory string) ([]models.ParsedRawFile, error) {
+// parsing the data into records for node movements, ping results, association events, resource usage, station info (via iw), and access point info (also via iw).
+//
+// If only is non-negative, all files other than 'timeframe<only>.txt' are skipped.
+// If progress is non-nil, it is invoked before each file is processed.
+func processRawFileDirectory(directory string, only int, progress ProgressFunc) ([]models.ParsedRawFile, error) {
 	var parsed []models.ParsedRawFile
 
+	err := walkRawFileDirectory(directory, only, progress, func(m models.ParsedRawFile) error {
+		// sanity check our index
+		if only < 0 && len(parsed) != int(m.Timeframe) {
+			fmt.Printf("Warning: parsed timeframe does not equal the current # of parsed models. %d parsed, %d latest timeframe", len(parsed), m.Timeframe)
+		}
+
+		parsed = append(parsed, m)
+		return nil
+	})
+
+	return parsed, err
+}
+
+// walkRawFileDirectory parses the raw files in directory (see processRawFileDirectory), passing each to fn as soon as it is parsed.
+// Nothing is retained between files, so memory usage is bound by the largest file rather than the whole directory.
+//
+// If fn returns an error, the walk halts and returns it.
+func walkRawFileDirectory(directory string, only int, progress ProgressFunc, fn func(models.ParsedRawFile) error) error {
+	// collect the raw files up front so progress can report a total
+	var files []models.ParsedRawFile
 	err := filepath.WalkDir(directory, func(pth string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -44,63 +167,181 @@ func processRawFileDirectory(directory string) ([]models.ParsedRawFile, error) {
 			return nil
 		} else if scanned != 1 {
 			return nil
+		} else if only >= 0 && m.Timeframe != uint(only) {
+			return nil
 		}
-		fmt.Printf("Processing file: %s\n", m.Path)
+		files = append(files, m)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-		m.Movements, m.Pings, m.Stations, m.APs, err = processFile(pth, d.Name())
-		if err != nil {
-			fmt.Printf("Warning: Error processing file %s: %v\n", d.Name(), err)
-			return nil // continue
-		}
-		// sanity check our index
-		if len(parsed) != int(m.Timeframe) {
-			fmt.Printf("Warning: parsed timeframe does not equal the current # of parsed models. %d parsed, %d latest timeframe", len(parsed), m.Timeframe)
+	for i, m := range files {
+		name := filepath.Base(m.Path)
+		if progress != nil {
+			progress(i+1, len(files), m.Path)
 		}
 
-		parsed = append(parsed, m)
-		return nil
-	})
+		m.Movements, m.Pings, m.Associations, m.Resources, m.Stations, m.APs, err = processFile(m.Path, name)
+		if errors.Is(err, ErrDuplicateNode) || errors.Is(err, ErrIncompletePingall) || (err != nil && *failFast) { // these are only returned under --strict
+			return fmt.Errorf("%s: %w", name, err)
+		} else if err != nil {
+			warnFile(name, "error processing file, skipping it: %v", err)
+			continue
+		}
 
-	return parsed, err
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // processFile walks timeframeX.txt file to parse out usable data.
 // Relies on direct string matches to figure out the structure of a line.
 //
 // If an error occurs, no arrays are returned to ensure incomplete data is not passed in.
+//
+// Each node's iw block is collected whole, then parsed into its record independently of the others (see parseIWBlocks).
+// If a node has multiple iw blocks (ex: a rerun was appended to the file), only the first is kept.
+// Under --strict, a duplicate block is an error (ErrDuplicateNode) instead.
+//
+// If the driver reported the node count of a pingall matrix, a matrix without exactly N×(N-1) rows is warned about
+// (or, under --strict, is an error (ErrIncompletePingall)).
+//
+// The file's leading "[format] N" line selects the patterns its records are parsed with (see rawFormats).
+// A version newer than this module supports is an error (ErrUnsupportedFormat), rather than risking a silent misparse.
 func processFile(filePath, fileName string) (
-	movements []models.MovementRecord, pings []models.PingRecord,
-	stations []models.StationRecord, aps []models.AccessPointRecord,
+	movements []models.MovementRecord, pings []models.PingRecord, associations []models.AssociationRecord,
+	resources []models.ResourceRecord, stations []models.StationRecord, aps []models.AccessPointRecord,
 	_ error,
 ) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, err
 	}
 	defer file.Close()
 
 	var (
 		currentMovementNumber string
+		currentTimestamp      string // RFC3339 wall-clock time the driver measured the current timeframe at
 		inPingallSection      bool
+		inAssociationSection  bool
+		currentAssociationTF  string
+		inResourcesSection    bool
+		currentResourcesTF    string
 		inIwSection           bool
-		currentStationName    string
-		currentAPName         string
-		inStationOutput       bool
-		inAPOutput            bool
+		iwBlocks              []iwBlock
+		currentIWBlock        = -1                // index of the iw block whose lines are being collected; -1 if none
+		seenNodes             = map[string]bool{} // nodes whose iw block has been processed; keyed by "<type>:<name>"
+		pingallExpected       = -1                // rows the current pingall matrix should have; -1 if unknown
+		pingallRows           int                 // rows parsed from the current pingall matrix
+		format                = rawFormats[0]     // patterns of the file's format version; set by its leading marker, if any
+		leading               = true              // no non-blank line has been read, so a format marker may follow
 	)
 
+	// checkPingall compares the rows of the current pingall matrix against the count the driver reported
+	checkPingall := func() error {
+		if pingallExpected < 0 || pingallRows == pingallExpected {
+			return nil
+		}
+		msg := fmt.Sprintf("pingall matrix for timeframe %s has %d of %d expected rows; the test may not have finished",
+			currentMovementNumber, pingallRows, pingallExpected)
+		if *strict {
+			return fmt.Errorf("%w: %s", ErrIncompletePingall, msg)
+		}
+		warnFile(fileName, "%s", msg)
+		return nil
+	}
+
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+		line := strings.TrimSpace(sanitizeLine(scanner.Text()))
+
+		// Check for the format marker, which may only precede every other line
+		if leading && line != "" {
+			leading = false
+			if matches := formatPattern.FindStringSubmatch(line); matches != nil {
+				version, err := strconv.Atoi(matches[1])
+				if err != nil || version >= len(rawFormats) {
+					return nil, nil, nil, nil, nil, nil, fmt.Errorf("%w %s (newest supported: %d)", ErrUnsupportedFormat, matches[1], len(rawFormats)-1)
+				}
+				format = rawFormats[version]
+				continue
+			}
+		}
 
 		// Check for iw_stations section start
 		if iwStartPattern.MatchString(line) {
 			inIwSection = true
+			inAssociationSection = false
+			continue
+		}
+
+		// Check for the wall-clock time of the measurements that follow
+		if matches := timestampPattern.FindStringSubmatch(line); matches != nil {
+			if ts, err := time.Parse(time.RFC3339, matches[2]); err != nil {
+				warnFile(fileName, "invalid timestamp %q: %v", matches[2], err)
+				currentTimestamp = ""
+			} else {
+				currentTimestamp = ts.UTC().Format(time.RFC3339)
+			}
+			inPingallSection = false
 			continue
 		}
 
+		// Check for resources section start
+		if matches := resourcesPattern.FindStringSubmatch(line); matches != nil {
+			currentResourcesTF = matches[1]
+			inResourcesSection = true
+			inPingallSection = false
+			continue
+		}
+		// Process resource usage rows, until the section ends
+		if inResourcesSection {
+			if line == "" || strings.HasPrefix(line, "[") {
+				inResourcesSection = false // let the line be handled below
+			} else {
+				if r, ok := parseResourceLine(line); ok {
+					r.Timeframe, r.TestFile = currentResourcesTF, fileName
+					resources = append(resources, r)
+				}
+				continue
+			}
+		}
+
+		// Check for associations section start
+		if matches := associationsPattern.FindStringSubmatch(line); matches != nil {
+			currentAssociationTF = matches[1]
+			inAssociationSection = true
+			inPingallSection = false
+			continue
+		}
+		// Process association events, until the section ends
+		if inAssociationSection {
+			if line == "" || strings.HasPrefix(line, "[") {
+				inAssociationSection = false // let the line be handled below
+			} else {
+				if matches := associationPattern.FindStringSubmatch(line); matches != nil {
+					event := "associated"
+					if matches[2] == "disassociated from" {
+						event = "disassociated"
+					}
+					associations = append(associations, models.AssociationRecord{
+						Timeframe: currentAssociationTF,
+						Station:   matches[1],
+						AP:        matches[3],
+						Event:     event,
+						TestFile:  fileName,
+					})
+				}
+				continue
+			}
+		}
+
 		// Check for node movement
-		if matches := movementPattern.FindStringSubmatch(line); matches != nil {
+		if matches := format.movement.FindStringSubmatch(line); matches != nil {
 			movement := models.MovementRecord{
 				MovementNumber: matches[1],
 				NodeName:       matches[2],
@@ -114,8 +355,15 @@ func processFile(filePath, fileName string) (
 
 		// Check for pingall section start
 		if matches := pingallStartPattern.FindStringSubmatch(line); matches != nil {
+			if err := checkPingall(); err != nil { // the prior matrix is complete
+				return nil, nil, nil, nil, nil, nil, err
+			}
 			currentMovementNumber = matches[1]
 			inPingallSection = true
+			pingallExpected, pingallRows = -1, 0
+			if n, err := strconv.Atoi(matches[2]); err == nil {
+				pingallExpected = n * (n - 1)
+			}
 			continue
 		}
 
@@ -126,59 +374,33 @@ func processFile(filePath, fileName string) (
 
 		// Process iw_stations data
 		if inIwSection {
-			// Check for station header
-			if matches := stationPattern.FindStringSubmatch(line); matches != nil {
-				currentStationName = matches[1]
-				inStationOutput = false
-				inAPOutput = false
-				continue
-			}
-
-			// Check for AP header
-			if matches := apPattern.FindStringSubmatch(line); matches != nil {
-				currentAPName = matches[1]
-				inStationOutput = false
-				inAPOutput = false
-				continue
-			}
-
-			// Check for Output: line
-			if strings.HasPrefix(line, "Output:") {
-				if currentStationName != "" {
-					inStationOutput = true
-				} else if currentAPName != "" {
-					inAPOutput = true
+			// Check for station or AP header, each of which begins a block that runs until an empty line or the next header
+			stationMatches, apMatches := stationPattern.FindStringSubmatch(line), apPattern.FindStringSubmatch(line)
+			if stationMatches != nil || apMatches != nil {
+				block := iwBlock{accessPoint: apMatches != nil}
+				nodeType, name := "station", stationMatches
+				if block.accessPoint {
+					nodeType, name = "access point", apMatches
+				}
+				currentIWBlock = -1
+				if block.name, err = checkDuplicateNode(seenNodes, nodeType, name[1], fileName); err != nil {
+					return nil, nil, nil, nil, nil, nil, err
+				} else if block.name != "" {
+					iwBlocks = append(iwBlocks, block)
+					currentIWBlock = len(iwBlocks) - 1
 				}
 				continue
 			}
 
-			// Process station data
-			if inStationOutput && currentStationName != "" {
-				stations = processStationData(stations, line, currentStationName, fileName)
-			}
-
-			// Process AP data
-			if inAPOutput && currentAPName != "" {
-				aps = processAPData(aps, line, currentAPName, fileName)
-			}
-
-			// Reset when we hit a new section or end (station/AP header)
-			if line == "" || strings.HasPrefix(line, "---") {
-				// Before resetting, check if we have a station that wasn't added yet
-				// (this happens when station is "Not connected")
-				if inStationOutput && currentStationName != "" && !stationExists(stations, currentStationName, fileName) {
-					// Create an empty station record for "Not connected" stations
-					station := models.StationRecord{
-						TestFile:    fileName,
-						StationName: currentStationName,
-					}
-					stations = append(stations, station)
+			if currentIWBlock >= 0 {
+				if line == "" || strings.HasPrefix(line, "---") {
+					currentIWBlock = -1
+				} else if strings.HasPrefix(line, "Output:") {
+					iwBlocks[currentIWBlock].output = true
+					continue
+				} else if iwBlocks[currentIWBlock].output {
+					iwBlocks[currentIWBlock].lines = append(iwBlocks[currentIWBlock].lines, line)
 				}
-
-				inStationOutput = false
-				inAPOutput = false
-				currentStationName = ""
-				currentAPName = ""
 			}
 		}
 
@@ -210,8 +432,10 @@ func processFile(filePath, fileName string) (
 					Rx:             parts[3],
 					LossPct:        lossPct,
 					AvgRttMs:       avgRttMs,
+					Timestamp:      currentTimestamp,
 				}
 				pings = append(pings, ping)
+				pingallRows += 1
 			}
 		}
 
@@ -222,36 +446,141 @@ func processFile(filePath, fileName string) (
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, err
 	}
+	if err := checkPingall(); err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+
+	stations, aps = parseIWBlocks(iwBlocks, fileName)
+	resolveConnectedAPs(stations, aps)
 
-	return movements, pings, stations, aps, nil
+	return movements, pings, associations, resources, stations, aps, nil
 }
 
-func processStationData(stations []models.StationRecord, line, stationName, fileName string) []models.StationRecord {
-	line = strings.TrimSpace(line)
+// resolveConnectedAPs sets the ConnectedToName of each station to the AP whose interface has the MAC address the station is connected to.
+// Stations connected to an address no AP reported (or that are not connected) are left unresolved.
+func resolveConnectedAPs(stations []models.StationRecord, aps []models.AccessPointRecord) {
+	apsByMAC := make(map[string]string, len(aps))
+	for _, ap := range aps {
+		if ap.Ether != "" {
+			apsByMAC[strings.ToLower(ap.Ether)] = ap.APName
+		}
+	}
+	for i := range stations {
+		stations[i].ConnectedToName = apsByMAC[strings.ToLower(stations[i].ConnectedTo)]
+	}
+}
 
-	// Check if this is the start of a new station record
-	if strings.HasPrefix(line, "Connected to ") {
-		// Extract MAC address
-		connectedPattern := regexp.MustCompile(`^Connected to ([0-9a-f:]+)`)
-		if matches := connectedPattern.FindStringSubmatch(line); matches != nil {
-			station := models.StationRecord{
-				TestFile:    fileName,
-				StationName: stationName,
-				ConnectedTo: matches[1],
-			}
-			stations = append(stations, station)
+// parseResourceLine parses a row of a [resources] section, of the form node,pid,cpu_pct,rss_kb.
+// Values the driver could not sample ("?") are left empty.
+// Returns false for the header and malformed rows.
+func parseResourceLine(line string) (models.ResourceRecord, bool) {
+	parts := strings.Split(line, ",")
+	if len(parts) != 4 || parts[0] == "node" {
+		return models.ResourceRecord{}, false
+	}
+	for i := range parts {
+		if parts[i] = strings.TrimSpace(parts[i]); parts[i] == "?" {
+			parts[i] = ""
+		}
+	}
+	return models.ResourceRecord{NodeName: parts[0], PID: parts[1], CPUPct: parts[2], RSSKB: parts[3]}, true
+}
+
+// sanitizeLine strips ANSI escape sequences, invalid UTF-8, and non-printable characters (other than tabs) from a raw line.
+// Terminal output captured through a PTY can contain these, which otherwise pollute parsed fields.
+func sanitizeLine(line string) string {
+	line = ansiEscapePattern.ReplaceAllString(line, "")
+	return strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || (r != '\t' && !unicode.IsPrint(r)) {
+			return -1
 		}
-	} else if len(stations) > 0 {
-		// Update the last station record with additional data
-		lastIdx := len(stations) - 1
-		if stations[lastIdx].StationName == stationName {
-			updateStationField(&stations[lastIdx], line)
+		return r
+	}, strings.ToValidUTF8(line, ""))
+}
+
+// checkDuplicateNode records that an iw block for the given node was found.
+// Returns the name of the node if this is its first block.
+// If the node was already seen, warns and returns "" so the block is skipped (or errors under --strict).
+func checkDuplicateNode(seen map[string]bool, nodeType, name, fileName string) (string, error) {
+	key := nodeType + ":" + name
+	if !seen[key] {
+		seen[key] = true
+		return name, nil
+	}
+	if *strict {
+		return "", fmt.Errorf("%w: %s %s has multiple iw blocks", ErrDuplicateNode, nodeType, name)
+	}
+	warnFile(fileName, "multiple iw blocks for %s %s; keeping the first", nodeType, name)
+	return "", nil
+}
+
+// An iwBlock is the output of a single station's ("--- Station X ---") or access point's ("--- Access Point X ---") iw block.
+type iwBlock struct {
+	name        string
+	accessPoint bool
+	output      bool     // the block's "Output:" line was reached
+	lines       []string // lines following "Output:"
+}
+
+// parseIWBlocks parses each block into a station or access point record, spreading the blocks across GOMAXPROCS workers.
+// Records are returned in the order of their blocks.
+// Blocks that never reached their output, and access point blocks without an interface, produce no record.
+func parseIWBlocks(blocks []iwBlock, fileName string) (stations []models.StationRecord, aps []models.AccessPointRecord) {
+	type result struct {
+		station models.StationRecord
+		ap      models.AccessPointRecord
+		ok      bool
+	}
+	results := make([]result, len(blocks))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(blocks)) {
+		wg.Go(func() {
+			for i := range indices {
+				if !blocks[i].output {
+					continue
+				} else if blocks[i].accessPoint {
+					results[i].ap, results[i].ok = parseAPBlock(blocks[i], fileName)
+				} else {
+					results[i].station, results[i].ok = parseStationBlock(blocks[i], fileName), true
+				}
+			}
+		})
+	}
+	for i := range blocks {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	for i, r := range results {
+		if !r.ok {
+			continue
+		} else if blocks[i].accessPoint {
+			aps = append(aps, r.ap)
+		} else {
+			stations = append(stations, r.station)
 		}
 	}
+	return stations, aps
+}
 
-	return stations
+// parseStationBlock parses the `iw dev <interface> link` output of a station's block.
+// A station that is not connected produces a record with only its name and test file.
+func parseStationBlock(block iwBlock, fileName string) models.StationRecord {
+	station := models.StationRecord{TestFile: fileName, StationName: block.name}
+	for _, line := range block.lines {
+		if matches := connectedPattern.FindStringSubmatch(line); matches != nil {
+			if station.ConnectedTo == "" {
+				station.ConnectedTo = matches[1]
+			}
+			continue
+		}
+		updateStationField(&station, line)
+	}
+	return station
 }
 
 func updateStationField(station *models.StationRecord, line string) {
@@ -263,15 +592,13 @@ func updateStationField(station *models.StationRecord, line string) {
 		station.Freq = strings.TrimPrefix(line, "freq: ")
 	} else if strings.HasPrefix(line, "RX: ") {
 		// Extract bytes and packets from "RX: 343809 bytes (8714 packets)"
-		rxPattern := regexp.MustCompile(`RX: (\d+) bytes \((\d+) packets\)`)
-		if matches := rxPattern.FindStringSubmatch(line); matches != nil {
+		if matches := stationRXPattern.FindStringSubmatch(line); matches != nil {
 			station.RXBytes = matches[1]
 			station.RXPackets = matches[2]
 		}
 	} else if strings.HasPrefix(line, "TX: ") {
 		// Extract bytes and packets from "TX: 4898 bytes (68 packets)"
-		txPattern := regexp.MustCompile(`TX: (\d+) bytes \((\d+) packets\)`)
-		if matches := txPattern.FindStringSubmatch(line); matches != nil {
+		if matches := stationTXPattern.FindStringSubmatch(line); matches != nil {
 			station.TXBytes = matches[1]
 			station.TXPackets = matches[2]
 		}
@@ -290,35 +617,24 @@ func updateStationField(station *models.StationRecord, line string) {
 	}
 }
 
-func processAPData(aps []models.AccessPointRecord, line, apName, fileName string) []models.AccessPointRecord {
-	line = strings.TrimSpace(line)
-
-	// Check if this is the interface line (start of AP record)
-	if strings.Contains(line, ": flags=") {
-		// Extract interface name and basic info
-		parts := strings.Split(line, ":")
-		if len(parts) > 0 {
-			interfaceName := strings.TrimSpace(parts[0])
-
-			ap := models.AccessPointRecord{
-				TestFile:  fileName,
-				APName:    apName,
-				Interface: interfaceName,
+// parseAPBlock parses the ifconfig output of an access point's block.
+// Only the first interface is parsed; returns false if the block reported no interface.
+func parseAPBlock(block iwBlock, fileName string) (_ models.AccessPointRecord, found bool) {
+	ap := models.AccessPointRecord{TestFile: fileName, APName: block.name}
+	for _, line := range block.lines {
+		// the interface line (ex: "ap1-wlan1: flags=4163<UP,BROADCAST,RUNNING,MULTICAST>  mtu 1500") begins the record
+		if strings.Contains(line, ": flags=") {
+			if found {
+				break
 			}
-
-			// Extract flags, MTU, etc. from the line
-			updateAPField(&ap, line)
-			aps = append(aps, ap)
+			ap.Interface = strings.TrimSpace(strings.Split(line, ":")[0])
+			found = true
 		}
-	} else if len(aps) > 0 {
-		// Update the last AP record with additional data
-		lastIdx := len(aps) - 1
-		if aps[lastIdx].APName == apName {
-			updateAPField(&aps[lastIdx], line)
+		if found {
+			updateAPField(&ap, line)
 		}
 	}
-
-	return aps
+	return ap, found
 }
 
 func updateAPField(ap *models.AccessPointRecord, line string) {
@@ -327,38 +643,36 @@ func updateAPField(ap *models.AccessPointRecord, line string) {
 	// Parse the main interface line
 	if strings.Contains(line, "flags=") && strings.Contains(line, "mtu") {
 		// Extract flags pattern
-		flagsPattern := regexp.MustCompile(`flags=(\d+)<([^>]+)>`)
-		if matches := flagsPattern.FindStringSubmatch(line); matches != nil {
+		if matches := apFlagsPattern.FindStringSubmatch(line); matches != nil {
 			ap.Flags = matches[2]
 		}
 
 		// Extract MTU
-		mtuPattern := regexp.MustCompile(`mtu (\d+)`)
-		if matches := mtuPattern.FindStringSubmatch(line); matches != nil {
+		if matches := apMTUPattern.FindStringSubmatch(line); matches != nil {
 			ap.MTU = matches[1]
 		}
 
 		// Extract txqueuelen
-		txqPattern := regexp.MustCompile(`txqueuelen (\d+)`)
-		if matches := txqPattern.FindStringSubmatch(line); matches != nil {
+		if matches := apTxqPattern.FindStringSubmatch(line); matches != nil {
 			ap.TxQueueLen = matches[1]
 		}
 	} else if strings.HasPrefix(line, "ether ") {
-		etherPattern := regexp.MustCompile(`ether ([0-9a-f:]+)`)
-		if matches := etherPattern.FindStringSubmatch(line); matches != nil {
+		// Parse "ether 02:00:00:00:04:00  txqueuelen 1000  (Ethernet)"
+		if matches := apEtherPattern.FindStringSubmatch(line); matches != nil {
 			ap.Ether = matches[1]
 		}
+		if matches := apTxqPattern.FindStringSubmatch(line); matches != nil {
+			ap.TxQueueLen = matches[1]
+		}
 	} else if strings.HasPrefix(line, "RX packets") {
 		// Parse "RX packets 137  bytes 8598 (8.5 KB)"
-		rxPattern := regexp.MustCompile(`RX packets (\d+)\s+bytes (\d+)`)
-		if matches := rxPattern.FindStringSubmatch(line); matches != nil {
+		if matches := apRXPattern.FindStringSubmatch(line); matches != nil {
 			ap.RXPackets = matches[1]
 			ap.RXBytes = matches[2]
 		}
 	} else if strings.HasPrefix(line, "RX errors") {
 		// Parse "RX errors 0  dropped 0  overruns 0  frame 0"
-		rxErrPattern := regexp.MustCompile(`RX errors (\d+)\s+dropped (\d+)\s+overruns (\d+)\s+frame (\d+)`)
-		if matches := rxErrPattern.FindStringSubmatch(line); matches != nil {
+		if matches := apRXErrPattern.FindStringSubmatch(line); matches != nil {
 			ap.RXErrors = matches[1]
 			ap.RXDropped = matches[2]
 			ap.RXOverruns = matches[3]
@@ -366,15 +680,13 @@ func updateAPField(ap *models.AccessPointRecord, line string) {
 		}
 	} else if strings.HasPrefix(line, "TX packets") {
 		// Parse "TX packets 137  bytes 11064 (11.0 KB)"
-		txPattern := regexp.MustCompile(`TX packets (\d+)\s+bytes (\d+)`)
-		if matches := txPattern.FindStringSubmatch(line); matches != nil {
+		if matches := apTXPattern.FindStringSubmatch(line); matches != nil {
 			ap.TXPackets = matches[1]
 			ap.TXBytes = matches[2]
 		}
 	} else if strings.HasPrefix(line, "TX errors") {
 		// Parse "TX errors 0  dropped 0 overruns 0  carrier 0  collisions 0"
-		txErrPattern := regexp.MustCompile(`TX errors (\d+)\s+dropped (\d+)\s+overruns (\d+)\s+carrier (\d+)\s+collisions (\d+)`)
-		if matches := txErrPattern.FindStringSubmatch(line); matches != nil {
+		if matches := apTXErrPattern.FindStringSubmatch(line); matches != nil {
 			ap.TXErrors = matches[1]
 			ap.TXDropped = matches[2]
 			ap.TXOverruns = matches[3]
@@ -384,135 +696,173 @@ func updateAPField(ap *models.AccessPointRecord, line string) {
 	}
 }
 
-// writeNodesCSV generates a nodes.csv file inside of tfDirPath using the parsed data for this timeframe.
-func writeNodesCSV(parsed models.ParsedRawFile, tfDirPath string) error {
+// A graphNode is a station or access point of a single timeframe, as written to nodes.csv (and the GraphML export).
+type graphNode struct {
+	id, position                           string
+	rxBytes, rxPackets, txBytes, txPackets string
+	successRate                            float64 // fraction (0-1) of the pings to and from the node without loss
+	accessPoint                            bool
+}
+
+// timeframeNodes collects the stations, followed by the access points, of this timeframe.
+// Nodes without a recorded position are warned about and skipped.
+func timeframeNodes(parsed models.ParsedRawFile) []graphNode {
 	// Calculate success rates based on cumulative pings
 	successRates := calculateSuccessRates(parsed.Pings)
 
+	// look up positions by node name
+	positions := movementPositions(parsed)
+
+	nodes := make([]graphNode, 0, len(parsed.Stations)+len(parsed.APs))
+	for _, sta := range parsed.Stations {
+		pos, found := positions[sta.StationName]
+		if !found { // reported by ParsedRawFile.Validate
+			continue
+		}
+		nodes = append(nodes, graphNode{
+			id: sta.StationName, position: pos,
+			rxBytes: sta.RXBytes, rxPackets: sta.RXPackets, txBytes: sta.TXBytes, txPackets: sta.TXPackets,
+			successRate: successRates[sta.StationName],
+		})
+	}
+	for _, ap := range parsed.APs {
+		pos, found := positions[ap.APName]
+		if !found { // reported by ParsedRawFile.Validate
+			continue
+		}
+		nodes = append(nodes, graphNode{
+			id: ap.APName, position: pos,
+			rxBytes: ap.RXBytes, rxPackets: ap.RXPackets, txBytes: ap.TXBytes, txPackets: ap.TXPackets,
+			successRate: successRates[ap.APName], accessPoint: true,
+		})
+	}
+	return nodes
+}
+
+// Headers of the per-timeframe CSVs.
+var (
+	nodesHeader = []string{"id", "title", "position", "rx_bytes", "rx_packets", "tx_bytes", "tx_packets", "success_pct_rate"}
+	edgesHeader = []string{"id", "source", "target"}
+)
+
+// writeNodesCSV generates a nodes.csv file inside of tfDirPath from the nodes of this timeframe (see timeframeNodes).
+func writeNodesCSV(parsed models.ParsedRawFile, nodes []graphNode, tfDirPath string) error {
 	// prep output file
 	csvPath := path.Join(tfDirPath, "nodes.csv")
-	f, err := os.Create(csvPath)
+	f, err := createCSV(csvPath)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	writer := csv.NewWriter(f)
+	writer := newCSVWriter(f)
 	defer writer.Flush()
 
 	// write header
-	hdr := []string{"id", "title", "position", "rx_bytes", "rx_packets", "tx_bytes", "tx_packets", "success_pct_rate"}
-	if err := writer.Write(hdr); err != nil {
+	if err := writer.Write(nodesHeader); err != nil {
 		return err
 	}
 
-	// write stations
-	for i, sta := range parsed.Stations {
-		// validate that movement node lines up with station node
-		if parsed.Movements[i].NodeName != sta.StationName {
-			fmt.Printf("WARNING: movement node name does not match station name! node: %s != station: %s\n", parsed.Movements[i].NodeName, sta.StationName)
-			continue
-		}
-
-		record := []string{
-			sta.StationName,              // id
-			sta.StationName,              // title
-			parsed.Movements[i].Position, // position
-			sta.RXBytes,
-			sta.RXPackets,
-			sta.TXBytes,
-			sta.TXPackets,
-			fmt.Sprintf("%.2f", successRates[sta.StationName]),
-		}
+	for _, n := range nodes {
+		record := nodeEncoder.encode(models.NodeRecord{
+			ID:             n.id,
+			Title:          n.id,
+			Position:       n.position,
+			RXBytes:        n.rxBytes,
+			RXPackets:      n.rxPackets,
+			TXBytes:        n.txBytes,
+			TXPackets:      n.txPackets,
+			SuccessPctRate: fmt.Sprintf("%.2f", n.successRate),
+		})
 		if err := writer.Write(record); err != nil {
 			return err
 		}
 	}
-	// write aps
-	for i, ap := range parsed.APs {
-		// validate that movement node lines up with station node
-		if parsed.Movements[i+len(parsed.Stations)].NodeName != ap.APName {
-			fmt.Printf("WARNING: movement node name does not match station name! node: %s != station: %s\n", parsed.Movements[i].NodeName, ap.APName)
-			continue
-		}
 
-		record := []string{
-			ap.APName,
-			ap.APName,
-			parsed.Movements[i].Position,
-			ap.RXBytes,
-			ap.RXPackets,
-			ap.TXBytes,
-			ap.TXPackets,
-			fmt.Sprintf("%.2f", successRates[ap.APName]),
-		}
-		if err := writer.Write(record); err != nil {
-			return err
-		}
-	}
-
-	fmt.Printf("\tNodes CSV for timeframe %d written to: %s\n", parsed.Timeframe, csvPath)
+	infof("\tNodes CSV for timeframe %d written to: %s\n", parsed.Timeframe, csvOutputPath(csvPath))
 
 	return nil
 }
 
-// writeEdgesCSV generates an edges.csv file inside of tfDirPath using the parsed data for this timeframe.
-// Duplicates are coalesced.
+// movementPositions maps the name of each node moved in this timeframe to its (last) position.
+func movementPositions(parsed models.ParsedRawFile) map[string]string {
+	positions := make(map[string]string, len(parsed.Movements))
+	for _, mv := range parsed.Movements {
+		positions[mv.NodeName] = mv.Position
+	}
+	return positions
+}
+
+// An edgeFilter reports whether the edge from src to dst should be written to edges.csv.
+type edgeFilter func(src, dst string) bool
+
+// edgeFilters are the values accepted by --edge-filter.
 //
-// NOTE(rlandau): station to station edges are ignored using "sta" substring matches.
-// It is quite brittle.
-func writeEdgesCSV(parsed models.ParsedRawFile, tfDirPath string) error {
+// NOTE: nodes are classified using "sta" and "ap" substring matches, which is just as brittle as it has always been.
+var edgeFilters = map[string]edgeFilter{
+	"all":        func(_, _ string) bool { return true },
+	"no-sta-sta": func(src, dst string) bool { return !(isStationName(src) && isStationName(dst)) },
+	"ap-sta-only": func(src, dst string) bool {
+		return (isAPName(src) && isStationName(dst)) || (isStationName(src) && isAPName(dst))
+	},
+}
+
+// keepEdge is the edge filter applied by timeframeEdges, as set by --edge-filter.
+var keepEdge = edgeFilters["no-sta-sta"]
+
+func isStationName(name string) bool { return strings.Contains(name, "sta") }
+func isAPName(name string) bool      { return strings.Contains(name, "ap") }
+
+// A graphEdge is a pinged pair of nodes of a single timeframe, as written to edges.csv (and the GraphML export).
+type graphEdge struct {
+	id, source, target string
+}
+
+// timeframeEdges collects the edges of this timeframe accepted by keepEdge (by default, station to station edges are ignored).
+// Duplicates are coalesced and edges are sorted by id.
+func timeframeEdges(parsed models.ParsedRawFile) []graphEdge {
+	// use a map to consolidate duplicates
+	edges := map[string]graphEdge{}
+	for _, ping := range parsed.Pings {
+		if !keepEdge(ping.Src, ping.Dst) {
+			continue
+		}
+		id := ping.Src + "-" + ping.Dst
+		edges[id] = graphEdge{id, ping.Src, ping.Dst}
+	}
+
+	sorted := make([]graphEdge, 0, len(edges))
+	for _, id := range slices.Sorted(maps.Keys(edges)) {
+		sorted = append(sorted, edges[id])
+	}
+	return sorted
+}
+
+// writeEdgesCSV generates an edges.csv file inside of tfDirPath from the edges of this timeframe (see timeframeEdges).
+func writeEdgesCSV(parsed models.ParsedRawFile, edges []graphEdge, tfDirPath string) error {
 	// prep output file
 	csvPath := path.Join(tfDirPath, "edges.csv")
-	f, err := os.Create(csvPath)
+	f, err := createCSV(csvPath)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	writer := csv.NewWriter(f)
+	writer := newCSVWriter(f)
 	defer writer.Flush()
 
 	// write header
-	header := []string{"id", "source", "target"}
-	if err := writer.Write(header); err != nil {
+	if err := writer.Write(edgesHeader); err != nil {
 		return err
 	}
 
-	// use a map to consolidate duplicates; the map keys are broken apart later
-	edges := map[string]struct {
-		src    string
-		target string
-	}{}
-	for _, ping := range parsed.Pings {
-		// ignore station to station edges
-		if strings.Contains(ping.Src, "sta") && strings.Contains(ping.Dst, "sta") {
-			continue
-		}
-
-		id := ping.Src + "-" + ping.Dst
-		edges[id] = struct {
-			src    string
-			target string
-		}{
-			ping.Src, ping.Dst,
-		}
-	}
-
-	// sort and write the map into a file, breaking id into source and target
-	elems := slices.Sorted(maps.Keys(edges))
-	for _, id := range elems {
-		record := []string{
-			id,               // id
-			edges[id].src,    // src
-			edges[id].target, // target
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("failed to write line '%s' to %s: %w", id, csvPath, err)
+	for _, e := range edges {
+		if err := writer.Write(edgeEncoder.encode(models.EdgeRecord{ID: e.id, Source: e.source, Target: e.target})); err != nil {
+			return fmt.Errorf("failed to write line '%s' to %s: %w", e.id, csvPath, err)
 		}
 	}
 
-	fmt.Printf("\tEdges CSV for timeframe %d written to: %s\n", parsed.Timeframe, csvPath)
+	infof("\tEdges CSV for timeframe %d written to: %s\n", parsed.Timeframe, csvOutputPath(csvPath))
 
 	return nil
 }
@@ -549,9 +899,9 @@ func calculateSuccessRates(pings []models.PingRecord) map[string]float64 {
 }
 
 // getCumulativePings returns all pings from test files up to and including the specified test file.
-// The ordering is based on movement numbers extracted from the file names (e.g., test1.txt -> 1, test2.txt -> 2).
+// The ordering is based on timeframe numbers extracted from the file names (e.g., timeframe1.txt -> 1, timeframe2.txt -> 2).
 func getCumulativePings(allPings []models.PingRecord, upToTestFile string) []models.PingRecord {
-	// Extract movement number from the target test file
+	// Extract timeframe number from the target test file
 	targetMovementNum := extractMovementNumber(upToTestFile)
 
 	var cumulativePings []models.PingRecord
@@ -565,37 +915,24 @@ func getCumulativePings(allPings []models.PingRecord, upToTestFile string) []mod
 	return cumulativePings
 }
 
-// getTestName extracts the test name from a test file name (e.g., "test1.txt" -> "test1")
+// getTestName extracts the test name from a test file name (e.g., "timeframe1.txt" -> "timeframe1")
 func getTestName(testFile string) string {
 	// Remove the .txt extension
 	name := strings.TrimSuffix(testFile, ".txt")
 	return name
 }
 
-// extractMovementNumber extracts the movement number from a test file name.
-// For example, "test1.txt" -> 1, "test2.txt" -> 2, etc.
+// extractMovementNumber extracts the timeframe number from a raw file name.
+// Uses the same 'timeframeX.txt' nomenclature as processRawFileDirectory.
+// For example, "timeframe1.txt" -> 1, "timeframe2.txt" -> 2, etc.
+//
+// Returns -1 if the file name does not match the expected format.
 func extractMovementNumber(testFile string) int {
-	// Extract the test name without extension
-	name := getTestName(testFile)
-
-	// Extract the number from the test name (e.g., "test1" -> 1)
-	// This assumes the format is "testN" where N is a number
-	numStr := strings.TrimPrefix(name, "test")
-
-	// Try to parse the number
 	var num int
-	fmt.Sscanf(numStr, "%d", &num)
-	return num
-}
-
-// stationExists checks if a station record already exists for the given station name and test file.
-func stationExists(stations []models.StationRecord, stationName, testFile string) bool {
-	for _, station := range stations {
-		if station.StationName == stationName && station.TestFile == testFile {
-			return true
-		}
+	if scanned, err := fmt.Sscanf(strings.ToLower(filepath.Base(testFile)), "timeframe%d.txt", &num); err != nil || scanned != 1 {
+		return -1
 	}
-	return false
+	return num
 }
 
 // getPositionMap builds a map of node names to their positions from movement records.
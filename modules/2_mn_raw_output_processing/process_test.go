@@ -0,0 +1,506 @@
+package main
+
+import (
+	"Omen/modules/2_mn_raw_output_processing/models"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_extractMovementNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		testFile string
+		want     int
+	}{
+		{"timeframe 0", "timeframe0.txt", 0},
+		{"timeframe 2", "timeframe2.txt", 2},
+		{"double digits", "timeframe12.txt", 12},
+		{"uppercase", "Timeframe3.TXT", 3},
+		{"full path", "mn_result_raw/20251103_143345/timeframe1.txt", 1},
+		{"legacy test naming", "test1.txt", -1},
+		{"no number", "timeframe.txt", -1},
+		{"empty", "", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractMovementNumber(tt.testFile); got != tt.want {
+				t.Errorf("extractMovementNumber(%q) = %v, want %v", tt.testFile, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getCumulativePings(t *testing.T) {
+	all := []models.PingRecord{
+		{TestFile: "timeframe0.txt", Src: "sta1", Dst: "sta2"},
+		{TestFile: "timeframe1.txt", Src: "sta1", Dst: "sta3"},
+		{TestFile: "timeframe1.txt", Src: "sta2", Dst: "sta3"},
+		{TestFile: "timeframe2.txt", Src: "sta3", Dst: "sta1"},
+	}
+
+	tests := []struct {
+		name         string
+		upToTestFile string
+		want         int
+	}{
+		{"first timeframe", "timeframe0.txt", 1},
+		{"middle timeframe", "timeframe1.txt", 3},
+		{"last timeframe", "timeframe2.txt", 4},
+		{"beyond last timeframe", "timeframe9.txt", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getCumulativePings(all, tt.upToTestFile); len(got) != tt.want {
+				t.Errorf("getCumulativePings(%q) returned %d pings, want %d", tt.upToTestFile, len(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_movementPositions(t *testing.T) {
+	parsed := models.ParsedRawFile{
+		Stations: []models.StationRecord{{StationName: "sta1"}, {StationName: "sta2"}},
+		APs:      []models.AccessPointRecord{{APName: "ap1"}},
+		// out of declaration order, with a typo'd node and a node moved twice
+		Movements: []models.MovementRecord{
+			{NodeName: "ap1", Position: "0,0,0"},
+			{NodeName: "sta2", Position: "5,5,0"},
+			{NodeName: "sat1", Position: "1,1,0"},
+			{NodeName: "sta1", Position: "2,2,0"},
+			{NodeName: "sta1", Position: "3,3,0"},
+			{NodeName: "sat1", Position: "4,4,0"},
+		},
+	}
+
+	positions := movementPositions(parsed)
+	for node, want := range map[string]string{"ap1": "0,0,0", "sta1": "3,3,0", "sta2": "5,5,0", "sat1": "4,4,0"} {
+		if got := positions[node]; got != want {
+			t.Errorf("position of %s = %q, want %q", node, got, want)
+		}
+	}
+}
+
+func Test_processFile_associations(t *testing.T) {
+	const raw = `
+[pingall_full] 1: pairwise matrix (-c 1)
+src,dst,tx,rx,loss_pct,avg_rtt_ms
+sta1,ap1,1,1,0,0.5
+
+[associations] 1: association events
+sta1 disassociated from ap1
+sta1 associated with ap2
+sta2 associated with ap1
+
+[iw_stations] check_all_links: running 'iw dev {interface} link' on all stations
+`
+	pth := filepath.Join(t.TempDir(), "timeframe1.txt")
+	if err := os.WriteFile(pth, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, pings, associations, _, _, _, err := processFile(pth, "timeframe1.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pings) != 1 {
+		t.Errorf("parsed %d pings, want 1", len(pings))
+	}
+	want := []models.AssociationRecord{
+		{Timeframe: "1", Station: "sta1", AP: "ap1", Event: "disassociated", TestFile: "timeframe1.txt"},
+		{Timeframe: "1", Station: "sta1", AP: "ap2", Event: "associated", TestFile: "timeframe1.txt"},
+		{Timeframe: "1", Station: "sta2", AP: "ap1", Event: "associated", TestFile: "timeframe1.txt"},
+	}
+	if !slices.Equal(associations, want) {
+		t.Errorf("associations = %v, want %v", associations, want)
+	}
+}
+
+func Test_processFile_duplicateNodes(t *testing.T) {
+	const raw = `
+[iw_stations] check_all_links: running 'iw dev {interface} link' on all stations
+
+--- Station sta1 ---
+Command: iw dev sta1-wlan0 link
+Output:
+Connected to 02:00:00:00:01:00 (on sta1-wlan0)
+	SSID: first
+
+--- Station sta1 ---
+Command: iw dev sta1-wlan0 link
+Output:
+Connected to 02:00:00:00:02:00 (on sta1-wlan0)
+	SSID: rerun
+
+`
+	pth := filepath.Join(t.TempDir(), "timeframe0.txt")
+	if err := os.WriteFile(pth, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("lenient", func(t *testing.T) {
+		_, _, _, _, stations, _, err := processFile(pth, "timeframe0.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stations) != 1 {
+			t.Fatalf("parsed %d stations, want 1: %v", len(stations), stations)
+		} else if stations[0].ConnectedTo != "02:00:00:00:01:00" || stations[0].SSID != "first" {
+			t.Errorf("expected the first block to be kept, got %+v", stations[0])
+		}
+	})
+	t.Run("strict", func(t *testing.T) {
+		*strict = true
+		defer func() { *strict = false }()
+		if _, _, _, _, _, _, err := processFile(pth, "timeframe0.txt"); !errors.Is(err, ErrDuplicateNode) {
+			t.Errorf("expected ErrDuplicateNode, got %v", err)
+		}
+	})
+}
+
+func Test_processFile_formatVersion(t *testing.T) {
+	const movements = `
+[node movements] 0: move sta1: moving sta1 -> 70,10,0
+[node movements] 0: move sta2: moving sta2 -> [0.0, -10.0, 0.0]
+`
+	tests := []struct {
+		name    string
+		marker  string
+		want    []string // positions parsed
+		wantErr error
+	}{
+		{"unmarked accepts either position form", "", []string{"70,10,0", "0.0, -10.0, 0.0"}, nil},
+		{"version 0 accepts either position form", "[format] 0\n", []string{"70,10,0", "0.0, -10.0, 0.0"}, nil},
+		{"version 1 requires brackets", "\n[format] 1\n", []string{"0.0, -10.0, 0.0"}, nil},
+		{"unsupported version", "[format] " + strconv.Itoa(len(rawFormats)) + "\n", nil, ErrUnsupportedFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pth := filepath.Join(t.TempDir(), "timeframe0.txt")
+			if err := os.WriteFile(pth, []byte(tt.marker+movements), 0644); err != nil {
+				t.Fatal(err)
+			}
+			mvs, _, _, _, _, _, err := processFile(pth, "timeframe0.txt")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("processFile() error = %v, want %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, mv := range mvs {
+				got = append(got, mv.Position)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parsed positions %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_processFile_incompletePingall(t *testing.T) {
+	const header = `
+[pingall_full] 0: pairwise matrix (-c 1) across 3 nodes
+src,dst,tx,rx,loss_pct,avg_rtt_ms
+sta1,sta2,1,1,0,0.5
+sta1,ap1,1,1,0,0.5
+sta2,sta1,1,0,100,?
+sta2,ap1,1,1,0,0.5
+`
+	tests := []struct {
+		name      string
+		raw       string
+		wantIssue bool
+	}{
+		{"complete", header + "ap1,sta1,1,1,0,0.5\nap1,sta2,1,1,0,0.5\n", false},
+		{"truncated", header, true},
+		{"node count not reported", strings.Replace(header, " across 3 nodes", "", 1), false},
+	}
+	t.Cleanup(func() { fileIssues = nil })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pth := filepath.Join(t.TempDir(), "timeframe0.txt")
+			if err := os.WriteFile(pth, []byte(tt.raw), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			fileIssues = nil
+			if _, _, _, _, _, _, err := processFile(pth, "timeframe0.txt"); err != nil {
+				t.Fatal(err)
+			} else if gotIssue := len(fileIssues) > 0; gotIssue != tt.wantIssue {
+				t.Errorf("warned = %v, want %v (issues: %v)", gotIssue, tt.wantIssue, fileIssues)
+			}
+
+			*strict = true
+			defer func() { *strict = false }()
+			_, _, _, _, _, _, err := processFile(pth, "timeframe0.txt")
+			if gotErr := errors.Is(err, ErrIncompletePingall); gotErr != tt.wantIssue {
+				t.Errorf("under --strict, err = %v, want ErrIncompletePingall: %v", err, tt.wantIssue)
+			}
+		})
+	}
+}
+
+func Test_sanitizeLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"clean", "signal: -42 dBm", "signal: -42 dBm"},
+		{"color", "\x1b[01;32msignal\x1b[00m: -42 dBm", "signal: -42 dBm"},
+		{"cursor movement", "freq: 2412\x1b[K\x1b[?2004h", "freq: 2412"},
+		{"window title", "\x1b]0;wifi@mininet: ~\x07freq: 2412", "freq: 2412"},
+		{"carriage return and bell", "freq: 2412\r\a", "freq: 2412"},
+		{"invalid utf-8", "freq: \xff2412", "freq: 2412"},
+		{"tabs are kept", "\tSSID: omen", "\tSSID: omen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeLine(tt.line); got != tt.want {
+				t.Errorf("sanitizeLine(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_walkRawFileDirectory_progress(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"timeframe0.txt", "timeframe1.txt", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type call struct {
+		current, total int
+		file           string
+	}
+	var calls []call
+	err := walkRawFileDirectory(dir, -1, func(current, total int, file string) {
+		calls = append(calls, call{current, total, filepath.Base(file)})
+	}, func(models.ParsedRawFile) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []call{{1, 2, "timeframe0.txt"}, {2, 2, "timeframe1.txt"}}
+	if !slices.Equal(calls, want) {
+		t.Errorf("progress calls = %v, want %v", calls, want)
+	}
+}
+
+func Test_walkRawFileDirectory_failFast(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "timeframe0.txt"), []byte("\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// a dangling link cannot be opened, so processing it fails
+	if err := os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "timeframe1.txt")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { *failFast, *collectWarnings, fileIssues = false, false, nil })
+
+	*collectWarnings, fileIssues = true, nil
+	var count int
+	if err := walkRawFileDirectory(dir, -1, nil, func(models.ParsedRawFile) error { count++; return nil }); err != nil {
+		t.Fatalf("unexpected error when collecting warnings: %v", err)
+	}
+	if count != 1 || len(fileIssues) != 1 || fileIssues[0].file != "timeframe1.txt" {
+		t.Errorf("expected 1 parsed file and 1 issue for timeframe1.txt, got %d parsed and issues %v", count, fileIssues)
+	}
+
+	*collectWarnings, *failFast = false, true
+	if err := walkRawFileDirectory(dir, -1, nil, func(models.ParsedRawFile) error { return nil }); err == nil {
+		t.Error("expected an error under --fail-fast")
+	}
+}
+
+func Test_edgeFilters(t *testing.T) {
+	edges := [][2]string{{"sta1", "sta2"}, {"sta1", "ap1"}, {"ap1", "sta2"}, {"ap1", "ap2"}}
+	tests := map[string][]bool{
+		"all":         {true, true, true, true},
+		"no-sta-sta":  {false, true, true, true},
+		"ap-sta-only": {false, true, true, false},
+	}
+	for name, want := range tests {
+		filter, found := edgeFilters[name]
+		if !found {
+			t.Fatalf("missing edge filter %q", name)
+		}
+		for i, e := range edges {
+			if got := filter(e[0], e[1]); got != want[i] {
+				t.Errorf("%s(%s, %s) = %v, want %v", name, e[0], e[1], got, want[i])
+			}
+		}
+	}
+}
+
+func Test_processFile_timestamps(t *testing.T) {
+	const raw = `
+[timestamp] 1: 2025-11-03T14:33:45Z
+
+[pingall_full] 1: pairwise matrix (-c 1)
+src,dst,tx,rx,loss_pct,avg_rtt_ms
+sta1,ap1,1,1,0,0.5
+sta2,ap1,1,1,0,0.7
+`
+	pth := filepath.Join(t.TempDir(), "timeframe1.txt")
+	if err := os.WriteFile(pth, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, pings, _, _, _, _, err := processFile(pth, "timeframe1.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pings) != 2 {
+		t.Fatalf("parsed %d pings, want 2", len(pings))
+	}
+	for _, p := range pings {
+		if p.Timestamp != "2025-11-03T14:33:45Z" {
+			t.Errorf("ping %s->%s has timestamp %q", p.Src, p.Dst, p.Timestamp)
+		}
+	}
+}
+
+func Test_processFile_resources(t *testing.T) {
+	const raw = `
+[pingall_full] 2: pairwise matrix (-c 1)
+src,dst,tx,rx,loss_pct,avg_rtt_ms
+sta1,ap1,1,1,0,0.5
+
+[resources] 2: per-node resource usage
+node,pid,cpu_pct,rss_kb
+sta1,1234,0.3,3520
+ap1,?,?,?
+malformed,row
+
+[associations] 2: association events
+sta1 associated with ap1
+`
+	pth := filepath.Join(t.TempDir(), "timeframe2.txt")
+	if err := os.WriteFile(pth, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, pings, associations, resources, _, _, err := processFile(pth, "timeframe2.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pings) != 1 || len(associations) != 1 {
+		t.Errorf("parsed %d pings and %d associations, want 1 of each", len(pings), len(associations))
+	}
+	want := []models.ResourceRecord{
+		{Timeframe: "2", TestFile: "timeframe2.txt", NodeName: "sta1", PID: "1234", CPUPct: "0.3", RSSKB: "3520"},
+		{Timeframe: "2", TestFile: "timeframe2.txt", NodeName: "ap1"},
+	}
+	if !slices.Equal(resources, want) {
+		t.Errorf("resources = %v, want %v", resources, want)
+	}
+}
+
+// Test_processFile_afterAssociations ensures the associations section ends at the next section, rather than swallowing it.
+func Test_processFile_afterAssociations(t *testing.T) {
+	const raw = `
+[associations] 3: association events
+sta1 associated with ap1
+sta2 disassociated from ap1
+[timestamp] 3: 2025-01-01T00:03:00Z
+[pingall_full] 3: pairwise matrix (-c 1)
+src,dst,tx,rx,loss_pct,avg_rtt_ms
+sta1,sta2,1,1,0,0.5
+sta2,sta1,1,0,100,0
+
+[resources] 3: per-node resource usage
+node,pid,cpu_pct,rss_kb
+sta1,1234,0.3,3520
+`
+	pth := filepath.Join(t.TempDir(), "timeframe3.txt")
+	if err := os.WriteFile(pth, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, pings, associations, resources, _, _, err := processFile(pth, "timeframe3.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(associations) != 2 {
+		t.Errorf("parsed %d associations, want 2", len(associations))
+	}
+	if len(pings) != 2 {
+		t.Fatalf("parsed %d pings following the associations, want 2", len(pings))
+	}
+	for _, p := range pings {
+		if p.Timestamp != "2025-01-01T00:03:00Z" {
+			t.Errorf("ping %s->%s has timestamp %q", p.Src, p.Dst, p.Timestamp)
+		}
+	}
+	if len(resources) != 1 {
+		t.Errorf("parsed %d resource samples following the associations, want 1", len(resources))
+	}
+}
+
+func Test_resolveConnectedAPs(t *testing.T) {
+	aps := []models.AccessPointRecord{
+		{APName: "ap1", Interface: "ap1-wlan1", Ether: "02:00:00:00:04:00"},
+		{APName: "ap2", Interface: "ap2-wlan1", Ether: "02:00:00:00:05:00"},
+		{APName: "ap3", Interface: "ap3-wlan1"}, // ether not reported
+		{APName: "ap5", Interface: "ap5-wlan1", Ether: "02:00:00:00:0a:00"},
+	}
+	stations := []models.StationRecord{
+		{StationName: "sta1", ConnectedTo: "02:00:00:00:04:00"},
+		{StationName: "sta2", ConnectedTo: "02:00:00:00:05:00"},
+		{StationName: "sta3", ConnectedTo: "02:00:00:00:06:00"}, // unknown AP
+		{StationName: "sta4"}, // not connected
+		{StationName: "sta5", ConnectedTo: "02:00:00:00:0A:00"}, // case differs
+	}
+
+	resolveConnectedAPs(stations, aps)
+	want := []string{"ap1", "ap2", "", "", "ap5"}
+	for i, sta := range stations {
+		if sta.ConnectedToName != want[i] {
+			t.Errorf("%s: ConnectedToName = %q, want %q", sta.StationName, sta.ConnectedToName, want[i])
+		}
+	}
+}
+
+func Test_parseIWBlocks(t *testing.T) {
+	var blocks []iwBlock
+	for i := range 200 {
+		blocks = append(blocks, iwBlock{
+			name: "sta" + strconv.Itoa(i), output: true,
+			// fields preceding the connection line are kept with the station they belong to
+			lines: []string{"SSID: ssid" + strconv.Itoa(i), "Connected to 02:00:00:00:04:00 (on sta-wlan0)", "signal: -40 dBm"},
+		})
+	}
+	blocks = append(blocks,
+		iwBlock{name: "sta200", output: true, lines: []string{"Not connected."}},
+		iwBlock{name: "sta201"}, // never reached its output
+		iwBlock{name: "ap1", accessPoint: true, output: true, lines: []string{
+			"ap1-wlan1: flags=4163<UP,BROADCAST,RUNNING,MULTICAST>  mtu 1500",
+			"ether 02:00:00:00:04:00  txqueuelen 1000  (Ethernet)",
+			"RX packets 88  bytes 6928 (6.9 KB)",
+			"ap1-wlan2: flags=4163<UP,BROADCAST,RUNNING,MULTICAST>  mtu 9000", // only the first interface is kept
+		}},
+		iwBlock{name: "ap2", accessPoint: true, output: true, lines: []string{"error fetching interface information: Device not found"}},
+	)
+
+	stations, aps := parseIWBlocks(blocks, "timeframe0.txt")
+	if len(stations) != 201 {
+		t.Fatalf("parsed %d stations, want 201", len(stations))
+	}
+	for i, sta := range stations[:200] {
+		if want := "sta" + strconv.Itoa(i); sta.StationName != want {
+			t.Fatalf("station %d is %s, want %s (block order was not kept)", i, sta.StationName, want)
+		} else if sta.SSID != "ssid"+strconv.Itoa(i) || sta.ConnectedTo != "02:00:00:00:04:00" || sta.Signal != "-40 dBm" {
+			t.Errorf("station %s parsed as %+v", sta.StationName, sta)
+		}
+	}
+	if want := (models.StationRecord{TestFile: "timeframe0.txt", StationName: "sta200"}); stations[200] != want {
+		t.Errorf("unconnected station parsed as %+v, want %+v", stations[200], want)
+	}
+
+	if len(aps) != 1 {
+		t.Fatalf("parsed %d access points, want 1: %+v", len(aps), aps)
+	}
+	if ap := aps[0]; ap.APName != "ap1" || ap.Interface != "ap1-wlan1" || ap.MTU != "1500" || ap.Ether != "02:00:00:00:04:00" || ap.RXPackets != "88" {
+		t.Errorf("access point parsed as %+v", ap)
+	}
+}
@@ -1,156 +1,564 @@
 package main
 
 import (
+	"compress/gzip"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
 	"os"
+	"path/filepath"
 	"strconv"
+	"unicode/utf8"
 
 	"Omen/modules/2_mn_raw_output_processing/models"
 )
 
+// csvComma is the field delimiter of every CSV writer, as set by --delimiter.
+var csvComma rune = ','
+
+// parseDelimiter validates that s is a single character usable as a CSV field delimiter.
+func parseDelimiter(s string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 || size != len(s) {
+		return 0, errors.New("delimiter must be exactly one character")
+	} else if r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, errors.New("delimiter cannot be a quote, newline, or invalid character")
+	}
+	return r, nil
+}
+
+// newCSVWriter returns a csv.Writer on w, using the delimiter and line endings set by --delimiter and --use-crlf.
+// All CSV output should be written through a writer returned by this function.
+func newCSVWriter(w io.Writer) *csv.Writer {
+	wr := csv.NewWriter(w)
+	wr.Comma = csvComma
+	wr.UseCRLF = *useCRLF
+	return wr
+}
+
+// csvOutputPath returns the path a CSV destined for pth is written to: pth itself or, if --gzip-output, pth suffixed with ".gz".
+func csvOutputPath(pth string) string {
+	if *gzipOut {
+		return pth + ".gz"
+	}
+	return pth
+}
+
+// createCSV creates (or truncates) the file at csvOutputPath(pth), removing the other form of it (compressed or not) left by a prior run,
+// so the loader (which accepts either) cannot pick up a stale copy.
+// If --gzip-output, writes to the returned file are compressed; closing it finishes the gzip stream, then closes the file.
+func createCSV(pth string) (io.WriteCloser, error) {
+	stale := pth + ".gz"
+	if *gzipOut {
+		stale = pth
+	}
+	if err := os.Remove(stale); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("remove stale %s: %w", stale, err)
+	}
+	f, err := os.Create(csvOutputPath(pth))
+	if err != nil {
+		return nil, err
+	}
+	if !*gzipOut {
+		return f, nil
+	}
+	return gzipFile{gzip.NewWriter(f), f}, nil
+}
+
+// gzipFile is a file written through a gzip.Writer.
+type gzipFile struct {
+	*gzip.Writer
+	f *os.File
+}
+
+// Close flushes and terminates the gzip stream, then closes the underlying file.
+func (g gzipFile) Close() error {
+	return errors.Join(g.Writer.Close(), g.f.Close())
+}
+
+// Headers of the CSVs that span all timeframes.
+var (
+	pingAllHeader = []string{
+		"data_type", "movement_number", "test_file", "node_name", "position",
+		"src", "dst", "tx", "rx", "loss_pct", "avg_rtt_ms", "timestamp",
+	}
+	iwHeader = []string{
+		"device_type", "test_file", "device_name", "interface", "connected_to", "connected_to_name", "ssid", "freq",
+		"rx_bytes", "rx_packets", "tx_bytes", "tx_packets", "signal", "rx_bitrate", "tx_bitrate",
+		"bss_flags", "dtim_period", "beacon_int", "flags", "mtu", "ether", "tx_queue_len",
+		"rx_errors", "rx_dropped", "rx_overruns", "rx_frame", "tx_errors", "tx_dropped",
+		"tx_overruns", "tx_carrier", "tx_collisions",
+	}
+	associationsHeader = []string{"timeframe", "test_file", "station", "ap", "event"}
+	resourcesHeader    = []string{"timeframe", "test_file", "node_name", "pid", "cpu_pct", "rss_kb"}
+	pingStatsHeader    = []string{
+		"movement_number", "test_file", "src", "dst", "samples",
+		"mean_loss_pct", "stddev_loss_pct", "mean_avg_rtt_ms", "stddev_avg_rtt_ms",
+	}
+	testsHeader = []string{"test_name", "test_type", "timeframe", "node_name", "position", "produced"}
+)
+
 // writePingAllFull writes ping data from complete test to the given output.
 //
 // Uses the following format:
-// data_type,movement_number,test_file,node_name,position,src,dst,tx,rx,loss_pct,avg_rtt_ms
+// data_type,movement_number,test_file,node_name,position,src,dst,tx,rx,loss_pct,avg_rtt_ms,timestamp
 //
 // NOTE(rlandau): This format is somewhat a relic from earlier I/O Contracts.
 // data_type is always "ping" and node_name+position are always empty.
 func writePingAllFull(outputPath string, parsed []models.ParsedRawFile) (count uint, _ error) {
-	file, err := os.Create(outputPath)
+	file, err := createCSV(outputPath)
 	if err != nil {
 		return 0, err
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
+	writer := newCSVWriter(file)
 	defer writer.Flush()
 
 	// Write header
-	header := []string{
-		"data_type", "movement_number", "test_file", "node_name", "position",
-		"src", "dst", "tx", "rx", "loss_pct", "avg_rtt_ms",
-	}
-	if err := writer.Write(header); err != nil {
+	if err := writer.Write(pingAllHeader); err != nil {
 		return 0, err
 	}
 
 	// collect ping data from all files
 	for _, p := range parsed {
-		for _, ping := range p.Pings {
-			record := []string{
-				"ping", strconv.FormatUint(uint64(p.Timeframe), 10), ping.TestFile, "", "", // Empty movement fields
-				ping.Src, ping.Dst, ping.Tx, ping.Rx, ping.LossPct, ping.AvgRttMs,
-			}
-			if err := writer.Write(record); err != nil {
-				return count, err
-			}
-			count += 1
+		n, err := writePingRows(writer, p)
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+// writePingRows writes the pings of a single parsed model in the format of writePingAllFull.
+func writePingRows(writer *csv.Writer, p models.ParsedRawFile) (count uint, _ error) {
+	for _, ping := range p.Pings {
+		record := []string{
+			"ping", strconv.FormatUint(uint64(p.Timeframe), 10), ping.TestFile, "", "", // Empty movement fields
+			ping.Src, ping.Dst, ping.Tx, ping.Rx, ping.LossPct, ping.AvgRttMs, ping.Timestamp,
+		}
+		if err := writer.Write(record); err != nil {
+			return count, err
+		}
+		count += 1
+	}
+	return count, nil
+}
+
+// writePingStatsFull aggregates the pings of each (timeframe, src, dst) pair from all parsed models into the file at outputPath.
+// With --repetitions, the test runner measures each pair more than once per timeframe; each other pair has a single sample.
+//
+// Uses the following format:
+// movement_number,test_file,src,dst,samples,mean_loss_pct,stddev_loss_pct,mean_avg_rtt_ms,stddev_avg_rtt_ms
+func writePingStatsFull(outputPath string, parsed []models.ParsedRawFile) (count uint, _ error) {
+	file, err := createCSV(outputPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := newCSVWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write(pingStatsHeader); err != nil {
+		return 0, err
+	}
+	for _, p := range parsed {
+		n, err := writePingStatsRows(writer, p)
+		count += n
+		if err != nil {
+			return count, err
 		}
 	}
 
 	return count, nil
 }
 
+// writePingStatsRows writes the aggregated pings of a single parsed model in the format of writePingStatsFull.
+// Pairs are written in the order they were first pinged.
+func writePingStatsRows(writer *csv.Writer, p models.ParsedRawFile) (count uint, _ error) {
+	for _, st := range aggregatePings(p.Pings) {
+		lossMean, lossStddev := meanStddev(st.loss)
+		rttMean, rttStddev := meanStddev(st.rtt)
+		record := []string{
+			strconv.FormatUint(uint64(p.Timeframe), 10), st.testFile, st.src, st.dst, strconv.Itoa(st.samples),
+			lossMean, lossStddev, rttMean, rttStddev,
+		}
+		if err := writer.Write(record); err != nil {
+			return count, err
+		}
+		count += 1
+	}
+	return count, nil
+}
+
+// pingStat collects the repeated pings of a single src -> dst pair.
+type pingStat struct {
+	testFile, src, dst string
+	samples            int       // pings of the pair, including those with unparseable values
+	loss               []float64 // loss_pct of each ping with a numeric loss
+	rtt                []float64 // avg_rtt_ms of each ping that received a reply (as failed pings report an RTT of 0)
+}
+
+// aggregatePings groups pings by src and dst, in the order each pair first appears.
+func aggregatePings(pings []models.PingRecord) []pingStat {
+	var stats []pingStat
+	index := make(map[[2]string]int)
+	for _, ping := range pings {
+		key := [2]string{ping.Src, ping.Dst}
+		i, ok := index[key]
+		if !ok {
+			i = len(stats)
+			index[key] = i
+			stats = append(stats, pingStat{testFile: ping.TestFile, src: ping.Src, dst: ping.Dst})
+		}
+		st := &stats[i]
+		st.samples += 1
+		loss, err := strconv.ParseFloat(ping.LossPct, 64)
+		if err != nil {
+			continue
+		}
+		st.loss = append(st.loss, loss)
+		if loss < 100 {
+			if rtt, err := strconv.ParseFloat(ping.AvgRttMs, 64); err == nil {
+				st.rtt = append(st.rtt, rtt)
+			}
+		}
+	}
+	return stats
+}
+
+// meanStddev returns the mean and sample standard deviation of values, formatted to 3 decimal places.
+// The mean is empty if there are no values and the standard deviation is empty if there are fewer than 2.
+func meanStddev(values []float64) (mean, stddev string) {
+	if len(values) == 0 {
+		return "", ""
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	m := sum / float64(len(values))
+	mean = strconv.FormatFloat(m, 'f', 3, 64)
+	if len(values) < 2 {
+		return mean, ""
+	}
+	var sq float64
+	for _, v := range values {
+		sq += (v - m) * (v - m)
+	}
+	return mean, strconv.FormatFloat(math.Sqrt(sq/float64(len(values)-1)), 'f', 3, 64)
+}
+
 // writeIWFull walks the parsed models and writes their connection information into the file at outputPath.
 //
 // The file will contain all stas from all raw files followed by all aps from all raw files.
 func writeIWFull(outputPath string, parsed []models.ParsedRawFile) (staCount, apCount uint, _ error) {
-	file, err := os.Create(outputPath)
+	file, err := createCSV(outputPath)
 	if err != nil {
 		return 0, 0, err
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
+	writer := newCSVWriter(file)
 	defer writer.Flush()
 
 	// Write header
-	header := []string{
-		"device_type", "test_file", "device_name", "interface", "connected_to", "ssid", "freq",
-		"rx_bytes", "rx_packets", "tx_bytes", "tx_packets", "signal", "rx_bitrate", "tx_bitrate",
-		"bss_flags", "dtim_period", "beacon_int", "flags", "mtu", "ether", "tx_queue_len",
-		"rx_errors", "rx_dropped", "rx_overruns", "rx_frame", "tx_errors", "tx_dropped",
-		"tx_overruns", "tx_carrier", "tx_collisions",
-	}
-	if err := writer.Write(header); err != nil {
+	if err := writer.Write(iwHeader); err != nil {
 		return 0, 0, err
 	}
 
 	// Write station records
 	for _, p := range parsed {
-		for _, station := range p.Stations {
-			record := []string{
-				"station", station.TestFile, station.StationName, "", station.ConnectedTo, station.SSID,
-				station.Freq, station.RXBytes, station.RXPackets, station.TXBytes, station.TXPackets,
-				station.Signal, station.RxBitrate, station.TxBitrate, station.BssFlags, station.DtimPeriod,
-				station.BeaconInt, "", "", "", "", "", "", "", "", "", "", "", "", "",
-			}
-			if err := writer.Write(record); err != nil {
-				return staCount, apCount, err
-			}
-			staCount += 1
+		n, err := writeStationRows(writer, p)
+		staCount += n
+		if err != nil {
+			return staCount, apCount, err
 		}
 	}
 	// Write AP records
 	for _, p := range parsed {
-		for _, ap := range p.APs {
-			record := []string{
-				"access_point", ap.TestFile, ap.APName, ap.Interface, "", "", "", ap.RXBytes, ap.RXPackets,
-				ap.TXBytes, ap.TXPackets, "", "", "", "", "", "", ap.Flags, ap.MTU, ap.Ether,
-				ap.TxQueueLen, ap.RXErrors, ap.RXDropped, ap.RXOverruns, ap.RXFrame, ap.TXErrors,
-				ap.TXDropped, ap.TXOverruns, ap.TXCarrier, ap.TXCollisions,
-			}
-			if err := writer.Write(record); err != nil {
-				return staCount, apCount, err
-			}
-			apCount += 1
+		n, err := writeAPRows(writer, p)
+		apCount += n
+		if err != nil {
+			return staCount, apCount, err
 		}
 	}
 
 	return staCount, apCount, nil
 }
 
-// Params:
+// writeStationRows writes the stations of a single parsed model in the format of writeIWFull.
+func writeStationRows(writer *csv.Writer, p models.ParsedRawFile) (count uint, _ error) {
+	for _, station := range p.Stations {
+		record := stationEncoder.encode(station)
+		if err := writer.Write(record); err != nil {
+			return count, err
+		}
+		count += 1
+	}
+	return count, nil
+}
+
+// writeAPRows writes the access points of a single parsed model in the format of writeIWFull.
+func writeAPRows(writer *csv.Writer, p models.ParsedRawFile) (count uint, _ error) {
+	for _, ap := range p.APs {
+		record := apEncoder.encode(ap)
+		if err := writer.Write(record); err != nil {
+			return count, err
+		}
+		count += 1
+	}
+	return count, nil
+}
+
+// writeAssociationsFull writes the association events from all parsed models into the file at outputPath.
 //
-// outPath: the file path to create/truncate and write data to.
+// Uses the following format:
+// timeframe,test_file,station,ap,event
+func writeAssociationsFull(outputPath string, parsed []models.ParsedRawFile) (count uint, _ error) {
+	file, err := createCSV(outputPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := newCSVWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write(associationsHeader); err != nil {
+		return 0, err
+	}
+	for _, p := range parsed {
+		n, err := writeAssociationRows(writer, p)
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+// writeAssociationRows writes the association events of a single parsed model in the format of writeAssociationsFull.
+func writeAssociationRows(writer *csv.Writer, p models.ParsedRawFile) (count uint, _ error) {
+	for _, a := range p.Associations {
+		if err := writer.Write([]string{a.Timeframe, a.TestFile, a.Station, a.AP, a.Event}); err != nil {
+			return count, err
+		}
+		count += 1
+	}
+	return count, nil
+}
+
+// writeResourcesFull writes the per-node resource usage from all parsed models into the file at outputPath.
 //
-// timeframe: the timeframe we are processing for (under the "movement_number" column)
+// Uses the following format:
+// timeframe,test_file,node_name,pid,cpu_pct,rss_kb
+func writeResourcesFull(outputPath string, parsed []models.ParsedRawFile) (count uint, _ error) {
+	file, err := createCSV(outputPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := newCSVWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write(resourcesHeader); err != nil {
+		return 0, err
+	}
+	for _, p := range parsed {
+		n, err := writeResourceRows(writer, p)
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+// writeResourceRows writes the resource usage of a single parsed model in the format of writeResourcesFull.
+func writeResourceRows(writer *csv.Writer, p models.ParsedRawFile) (count uint, _ error) {
+	for _, r := range p.Resources {
+		if err := writer.Write([]string{r.Timeframe, r.TestFile, r.NodeName, r.PID, r.CPUPct, r.RSSKB}); err != nil {
+			return count, err
+		}
+		count += 1
+	}
+	return count, nil
+}
+
+// readTestDefinitions reads the tests declared in the input topology at pth.
+func readTestDefinitions(pth string) ([]models.TestDefinition, error) {
+	data, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	var input struct {
+		Tests []models.TestDefinition `json:"tests"`
+	}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", pth, err)
+	}
+	return input.Tests, nil
+}
+
+// writeTestsCSV writes the declared tests into the file at outputPath, mapping each to the timeframe it ran in.
+// produced is the set of timeframes for which raw output was parsed.
 //
-// rawTestFileName: "timeframeX.txt", where X==timeframe
-func writeMovementCSV(outPath string, timeframe uint64, parsed models.ParsedRawFile) error {
-	f, err := os.Create(outPath)
+// Uses the following format:
+// test_name,test_type,timeframe,node_name,position,produced
+func writeTestsCSV(outputPath string, tests []models.TestDefinition, produced map[uint]bool) error {
+	file, err := createCSV(outputPath)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	defer file.Close()
 
-	wr := csv.NewWriter(f)
-	defer wr.Flush()
+	writer := newCSVWriter(file)
+	defer writer.Flush()
 
-	// header
-	hdr := []string{"data_type", "movement_number", "test_file", "node_name", "position", "src", "dst", "tx", "rx", "loss_pct", "avg_rtt_ms"}
-	if err := wr.Write(hdr); err != nil {
+	if err := writer.Write(testsHeader); err != nil {
 		return err
 	}
-
-	for _, ping := range parsed.Pings {
-		record := []string{
-			"ping",                            // data_type
-			strconv.FormatUint(timeframe, 10), // movement_number
-			ping.TestFile,                     // test_file
-			"",                                // node name is always empty
-			"",                                // position is always empty
-			ping.Src,
-			ping.Dst,
-			ping.Tx,
-			ping.Rx,
-			ping.LossPct,
-			ping.AvgRttMs,
-		}
-		if err := wr.Write(record); err != nil {
+	for _, t := range tests {
+		if err := writer.Write([]string{
+			t.Name, t.Type, strconv.FormatUint(uint64(t.Timeframe), 10), t.Node, t.Position,
+			strconv.FormatBool(produced[t.Timeframe]),
+		}); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
+// cumulativeCSVs incrementally writes the CSVs that span all timeframes, one parsed model at a time.
+// The output is identical to that of writePingAllFull, writePingStatsFull, writeIWFull, writeAssociationsFull, and writeResourcesFull,
+// but parsed models need not be retained.
+//
+// As the IW CSV lists all stations before all APs, AP rows are spooled to a temporary file and appended by close.
+type cumulativeCSVs struct {
+	files                                                                  []io.WriteCloser // every file opened, for closing
+	iwFile                                                                 io.Writer
+	apSpool                                                                *os.File
+	ping, pingStats, iw, ap, associations, resources                       *csv.Writer
+	pingCount, pingStatCount, staCount, apCount, assocCount, resourceCount uint
+}
+
+// openCumulativeCSVs creates the cumulative CSVs in dir and writes their headers.
+func openCumulativeCSVs(dir string) (_ *cumulativeCSVs, err error) {
+	c := &cumulativeCSVs{}
+	defer func() {
+		if err != nil {
+			for _, f := range c.files {
+				f.Close()
+			}
+		}
+	}()
+	open := func(name string, header []string) (*csv.Writer, error) {
+		f, err := createCSV(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		c.files = append(c.files, f)
+		wr := newCSVWriter(f)
+		return wr, wr.Write(header)
+	}
+
+	if c.ping, err = open(fullPingDataCSV, pingAllHeader); err != nil {
+		return nil, err
+	}
+	if c.pingStats, err = open(pingStatsCSV, pingStatsHeader); err != nil {
+		return nil, err
+	}
+	if c.iw, err = open(fullIWDataCSV, iwHeader); err != nil {
+		return nil, err
+	}
+	c.iwFile = c.files[len(c.files)-1]
+	if c.associations, err = open(associationsCSV, associationsHeader); err != nil {
+		return nil, err
+	}
+	if c.resources, err = open(resourcesCSV, resourcesHeader); err != nil {
+		return nil, err
+	}
+	if c.apSpool, err = os.CreateTemp("", "omen-ap-rows-*.csv"); err != nil {
+		return nil, err
+	}
+	c.files = append(c.files, c.apSpool)
+	c.ap = newCSVWriter(c.apSpool)
+
+	return c, nil
+}
+
+// add writes the records of p into each cumulative CSV.
+func (c *cumulativeCSVs) add(p models.ParsedRawFile) error {
+	for _, w := range []struct {
+		rows  func(*csv.Writer, models.ParsedRawFile) (uint, error)
+		wr    *csv.Writer
+		count *uint
+	}{
+		{writePingRows, c.ping, &c.pingCount},
+		{writePingStatsRows, c.pingStats, &c.pingStatCount},
+		{writeStationRows, c.iw, &c.staCount},
+		{writeAPRows, c.ap, &c.apCount},
+		{writeAssociationRows, c.associations, &c.assocCount},
+		{writeResourceRows, c.resources, &c.resourceCount},
+	} {
+		n, err := w.rows(w.wr, p)
+		*w.count += n
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// close appends the spooled AP rows to the IW CSV, then flushes and closes every file.
+func (c *cumulativeCSVs) close() error {
+	var errs []error
+	c.ap.Flush()
+	c.iw.Flush()
+	if err := errors.Join(c.ap.Error(), c.iw.Error()); err != nil {
+		errs = append(errs, err)
+	} else if _, err := c.apSpool.Seek(0, io.SeekStart); err != nil {
+		errs = append(errs, err)
+	} else if _, err := io.Copy(c.iwFile, c.apSpool); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, wr := range []*csv.Writer{c.ping, c.pingStats, c.associations, c.resources} {
+		wr.Flush()
+		errs = append(errs, wr.Error())
+	}
+	for _, f := range c.files {
+		errs = append(errs, f.Close())
+	}
+	errs = append(errs, os.Remove(c.apSpool.Name()))
+	return errors.Join(errs...)
+}
+
+// writeMovementCSV writes the pings of a single timeframe to the file at outPath, in the format of writePingAllFull.
+// Returns the number of ping rows written.
+func writeMovementCSV(outPath string, parsed models.ParsedRawFile) (count uint, _ error) {
+	f, err := createCSV(outPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	wr := newCSVWriter(f)
+	defer wr.Flush()
+
+	if err := wr.Write(pingAllHeader); err != nil {
+		return 0, err
+	}
+	return writePingRows(wr, parsed)
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"Omen/modules/2_mn_raw_output_processing/models"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_parseDelimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    rune
+		wantErr bool
+	}{
+		{"comma", ",", ',', false},
+		{"semicolon", ";", ';', false},
+		{"tab", "\t", '\t', false},
+		{"multibyte", "¦", '¦', false},
+		{"empty", "", 0, true},
+		{"multiple characters", ";;", 0, true},
+		{"quote", `"`, 0, true},
+		{"newline", "\n", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDelimiter(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDelimiter(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			} else if got != tt.want {
+				t.Errorf("parseDelimiter(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+// The streamed cumulative CSVs must be identical to those written from all parsed models at once.
+func Test_cumulativeCSVs(t *testing.T) {
+	parsed := []models.ParsedRawFile{
+		{
+			Timeframe:    0,
+			Pings:        []models.PingRecord{{TestFile: "timeframe0.txt", Src: "sta1", Dst: "ap1"}},
+			Stations:     []models.StationRecord{{TestFile: "timeframe0.txt", StationName: "sta1"}},
+			APs:          []models.AccessPointRecord{{TestFile: "timeframe0.txt", APName: "ap1"}},
+			Associations: []models.AssociationRecord{{Timeframe: "0", Station: "sta1", AP: "ap1", Event: "associated"}},
+			Resources:    []models.ResourceRecord{{Timeframe: "0", NodeName: "sta1", PID: "42", CPUPct: "0.5", RSSKB: "3400"}},
+		},
+		{
+			Timeframe: 1,
+			Pings:     []models.PingRecord{{TestFile: "timeframe1.txt", Src: "ap1", Dst: "sta1"}},
+			Stations:  []models.StationRecord{{TestFile: "timeframe1.txt", StationName: "sta1"}},
+			APs:       []models.AccessPointRecord{{TestFile: "timeframe1.txt", APName: "ap1"}},
+		},
+	}
+
+	fullDir, streamDir := t.TempDir(), t.TempDir()
+	if _, err := writePingAllFull(filepath.Join(fullDir, fullPingDataCSV), parsed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writePingStatsFull(filepath.Join(fullDir, pingStatsCSV), parsed); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := writeIWFull(filepath.Join(fullDir, fullIWDataCSV), parsed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeAssociationsFull(filepath.Join(fullDir, associationsCSV), parsed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeResourcesFull(filepath.Join(fullDir, resourcesCSV), parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	cum, err := openCumulativeCSVs(streamDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range parsed {
+		if err := cum.add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cum.close(); err != nil {
+		t.Fatal(err)
+	}
+	if cum.pingCount != 2 || cum.pingStatCount != 2 || cum.staCount != 2 || cum.apCount != 2 || cum.assocCount != 1 || cum.resourceCount != 1 {
+		t.Errorf("unexpected counts: %d pings, %d ping pairs, %d stations, %d aps, %d associations, %d resource samples",
+			cum.pingCount, cum.pingStatCount, cum.staCount, cum.apCount, cum.assocCount, cum.resourceCount)
+	}
+
+	for _, name := range []string{fullPingDataCSV, pingStatsCSV, fullIWDataCSV, associationsCSV, resourcesCSV} {
+		want, err := os.ReadFile(filepath.Join(fullDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join(streamDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s differs.\nstreamed:\n%s\nfull:\n%s", name, got, want)
+		}
+	}
+}
+
+func Test_writeTestsCSV(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "topo.json")
+	if err := os.WriteFile(input, []byte(`{"tests": [
+		{"name": "move sta1", "type": "node movements", "timeframe": 1, "node": "sta1", "position": "0,5,0"},
+		{"name": "move sta2", "type": "node movements", "timeframe": 3, "node": "sta2", "position": "1,1,0"}
+	]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests, err := readTestDefinitions(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, testsCSV)
+	if err := writeTestsCSV(out, tests, map[uint]bool{0: true, 1: true}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "test_name,test_type,timeframe,node_name,position,produced\n" +
+		"move sta1,node movements,1,sta1,\"0,5,0\",true\n" +
+		"move sta2,node movements,3,sta2,\"1,1,0\",false\n"
+	if string(got) != want {
+		t.Errorf("unexpected tests CSV.\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func Test_gzipOutput(t *testing.T) {
+	parsed := []models.ParsedRawFile{{
+		Timeframe: 0,
+		Pings:     []models.PingRecord{{TestFile: "timeframe0.txt", Src: "sta1", Dst: "ap1"}},
+		Stations:  []models.StationRecord{{TestFile: "timeframe0.txt", StationName: "sta1"}},
+		APs:       []models.AccessPointRecord{{TestFile: "timeframe0.txt", APName: "ap1"}},
+	}}
+	plainDir, gzDir := t.TempDir(), t.TempDir()
+	if _, err := writePingAllFull(filepath.Join(plainDir, fullPingDataCSV), parsed); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := writeIWFull(filepath.Join(plainDir, fullIWDataCSV), parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { *gzipOut = false })
+	*gzipOut = true
+	// stream the cumulative CSVs, so the IW CSV's spooled AP rows are appended through the gzip stream
+	cum, err := openCumulativeCSVs(gzDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cum.add(parsed[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := cum.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{fullPingDataCSV, fullIWDataCSV} {
+		if _, err := os.Stat(filepath.Join(gzDir, name)); !os.IsNotExist(err) {
+			t.Errorf("uncompressed %s was written (stat error: %v)", name, err)
+		}
+		want, err := os.ReadFile(filepath.Join(plainDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(filepath.Join(gzDir, name+".gz"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s differs once decompressed.\ngot:\n%s\nwant:\n%s", name, got, want)
+		}
+	}
+}
+
+func Test_createCSV_removesOtherForm(t *testing.T) {
+	t.Cleanup(func() { *gzipOut = false })
+	for _, gz := range []bool{false, true} {
+		*gzipOut = gz
+		pth := filepath.Join(t.TempDir(), "nodes.csv")
+		for _, p := range []string{pth, pth + ".gz"} { // as left by prior runs with and without --gzip-output
+			if err := os.WriteFile(p, []byte("stale\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		f, err := createCSV(pth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+		stale := pth + ".gz"
+		if gz {
+			stale = pth
+		}
+		if _, err := os.Stat(stale); !os.IsNotExist(err) {
+			t.Errorf("gzip=%v: stale %s was not removed (stat error: %v)", gz, stale, err)
+		}
+		if _, err := os.Stat(csvOutputPath(pth)); err != nil {
+			t.Errorf("gzip=%v: %s was not written: %v", gz, csvOutputPath(pth), err)
+		}
+	}
+}
+
+func Test_writePingStatsFull(t *testing.T) {
+	parsed := []models.ParsedRawFile{
+		{Timeframe: 0, Pings: []models.PingRecord{
+			{TestFile: "timeframe0.txt", Src: "sta1", Dst: "sta2", LossPct: "0", AvgRttMs: "1.0"},
+			{TestFile: "timeframe0.txt", Src: "sta2", Dst: "sta1", LossPct: "0", AvgRttMs: "2.5"},
+			{TestFile: "timeframe0.txt", Src: "sta1", Dst: "sta2", LossPct: "100", AvgRttMs: "0"}, // failed pings carry no RTT
+			{TestFile: "timeframe0.txt", Src: "sta1", Dst: "sta2", LossPct: "0", AvgRttMs: "3.0"},
+		}},
+		{Timeframe: 1, Pings: []models.PingRecord{
+			{TestFile: "timeframe1.txt", Src: "sta1", Dst: "sta2", LossPct: "?", AvgRttMs: "0"},
+		}},
+	}
+	pth := filepath.Join(t.TempDir(), pingStatsCSV)
+	count, err := writePingStatsFull(pth, parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 pairs, got %d", count)
+	}
+	got, err := os.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "movement_number,test_file,src,dst,samples,mean_loss_pct,stddev_loss_pct,mean_avg_rtt_ms,stddev_avg_rtt_ms\n" +
+		"0,timeframe0.txt,sta1,sta2,3,33.333,57.735,2.000,1.414\n" +
+		"0,timeframe0.txt,sta2,sta1,1,0.000,,2.500,\n" +
+		"1,timeframe1.txt,sta1,sta2,1,,,,\n"
+	if string(got) != want {
+		t.Errorf("unexpected ping stats.\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func Test_writeMovementCSV(t *testing.T) {
+	p := models.ParsedRawFile{Timeframe: 2, Pings: []models.PingRecord{
+		{TestFile: "timeframe2.txt", Src: "sta1", Dst: "sta2", Tx: "5", Rx: "5", LossPct: "0", AvgRttMs: "1.204", Timestamp: "2025-11-03 14:33:45"},
+		{TestFile: "timeframe2.txt", Src: "sta2", Dst: "sta1", Tx: "5", Rx: "4", LossPct: "20", AvgRttMs: "2.5", Timestamp: "2025-11-03 14:33:46"},
+		{TestFile: "timeframe2.txt", Src: "sta1", Dst: "ap1", Tx: "5", Rx: "0", LossPct: "100", AvgRttMs: "0", Timestamp: "2025-11-03 14:33:47"},
+	}}
+	pth := filepath.Join(t.TempDir(), "ping_data_movement_2.csv")
+	count, err := writeMovementCSV(pth, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 rows, got %d", count)
+	}
+	got, err := os.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "data_type,movement_number,test_file,node_name,position,src,dst,tx,rx,loss_pct,avg_rtt_ms,timestamp\n" +
+		"ping,2,timeframe2.txt,,,sta1,sta2,5,5,0,1.204,2025-11-03 14:33:45\n" +
+		"ping,2,timeframe2.txt,,,sta2,sta1,5,4,20,2.5,2025-11-03 14:33:46\n" +
+		"ping,2,timeframe2.txt,,,sta1,ap1,5,0,100,0,2025-11-03 14:33:47\n"
+	if string(got) != want {
+		t.Errorf("unexpected movement CSV.\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// The count returned by writePingAllFull must be the number of rows it wrote, across every parsed model.
+func Test_writePingAllFull_count(t *testing.T) {
+	raw := map[string]string{
+		"timeframe0.txt": `
+[pingall_full] 0: pairwise matrix (-c 1) across 3 nodes
+src,dst,tx,rx,loss_pct,avg_rtt_ms
+sta1,sta2,1,1,0,0.5
+sta1,ap1,1,1,0,0.5
+sta2,sta1,1,0,100,?
+sta2,ap1,1,1,0,0.5
+ap1,sta1,1,1,0,0.5
+ap1,sta2,1,1,0,0.5
+`,
+		"timeframe1.txt": `
+[pingall_full] 1: pairwise matrix (-c 1) across 2 nodes
+src,dst,tx,rx,loss_pct,avg_rtt_ms
+sta1,sta2,1,1,0,0.7
+sta2,sta1,1,1,0,0.6
+`,
+	}
+	dir := t.TempDir()
+	for name, data := range raw {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var parsed []models.ParsedRawFile
+	if err := walkRawFileDirectory(dir, -1, nil, func(p models.ParsedRawFile) error {
+		parsed = append(parsed, p)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("expected 2 parsed files, got %d", len(parsed))
+	}
+
+	pth := filepath.Join(t.TempDir(), fullPingDataCSV)
+	count, err := writePingAllFull(pth, parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 8 {
+		t.Errorf("expected a count of 8, got %d", count)
+	}
+	got, err := os.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := uint(strings.Count(string(got), "\n")) - 1; rows != count { // less the header
+		t.Errorf("wrote %d rows, but counted %d", rows, count)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/xml"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// graphMLFile is the name of the GraphML export written into each timeframe's directory (if --export-graphml).
+const graphMLFile string = "graph.graphml"
+
+// graphMLNodeKeys are the attributes declared for (and, where known, set on) each node of a GraphML export.
+// x, y, and z are the node's position; Gephi and yEd lay nodes out by x and y.
+var graphMLNodeKeys = []graphMLKey{
+	{ID: "kind", For: "node", Name: "kind", Type: "string"}, // "station" or "access_point"
+	{ID: "position", For: "node", Name: "position", Type: "string"},
+	{ID: "x", For: "node", Name: "x", Type: "double"},
+	{ID: "y", For: "node", Name: "y", Type: "double"},
+	{ID: "z", For: "node", Name: "z", Type: "double"},
+	{ID: "rx_bytes", For: "node", Name: "rx_bytes", Type: "long"},
+	{ID: "rx_packets", For: "node", Name: "rx_packets", Type: "long"},
+	{ID: "tx_bytes", For: "node", Name: "tx_bytes", Type: "long"},
+	{ID: "tx_packets", For: "node", Name: "tx_packets", Type: "long"},
+	{ID: "success_pct_rate", For: "node", Name: "success_pct_rate", Type: "double"},
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// writeGraphML writes the nodes and edges of a timeframe (see timeframeNodes and timeframeEdges) as a directed GraphML graph to the file at outputPath.
+// Attributes that are missing or do not parse as their declared type are omitted from a node.
+// Edges to nodes that were skipped (ex: for lacking a position) are kept, their endpoints being declared without attributes.
+func writeGraphML(outputPath string, timeframe uint, nodes []graphNode, edges []graphEdge) error {
+	graph := graphMLGraph{ID: "timeframe" + strconv.FormatUint(uint64(timeframe), 10), EdgeDefault: "directed"}
+	declared := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		graph.Nodes = append(graph.Nodes, graphMLNode{ID: n.id, Data: graphMLNodeData(n)})
+		declared[n.id] = true
+	}
+	for _, e := range edges {
+		for _, end := range []string{e.source, e.target} {
+			if !declared[end] {
+				graph.Nodes = append(graph.Nodes, graphMLNode{ID: end})
+				declared[end] = true
+			}
+		}
+		graph.Edges = append(graph.Edges, graphMLEdge{ID: e.id, Source: e.source, Target: e.target})
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(f)
+	enc.Indent("", "  ")
+	if err := enc.Encode(graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphMLNodeKeys,
+		Graph: graph,
+	}); err != nil {
+		return err
+	}
+	if _, err := f.WriteString("\n"); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// graphMLNodeData returns the attributes of n, keyed by graphMLNodeKeys.
+func graphMLNodeData(n graphNode) []graphMLData {
+	var data []graphMLData
+	set := func(key, value string) {
+		if value != "" {
+			data = append(data, graphMLData{key, value})
+		}
+	}
+	setInt := func(key, value string) {
+		if v := optInt(value); v != nil {
+			set(key, strconv.FormatInt(*v, 10))
+		}
+	}
+
+	if n.accessPoint {
+		set("kind", "access_point")
+	} else {
+		set("kind", "station")
+	}
+	set("position", n.position)
+	if coords := strings.Split(strings.Trim(n.position, "[] "), ","); len(coords) == 3 {
+		for i, axis := range []string{"x", "y", "z"} {
+			if v := optFloat(coords[i]); v != nil {
+				set(axis, strconv.FormatFloat(*v, 'f', -1, 64))
+			}
+		}
+	}
+	setInt("rx_bytes", n.rxBytes)
+	setInt("rx_packets", n.rxPackets)
+	setInt("tx_bytes", n.txBytes)
+	setInt("tx_packets", n.txPackets)
+	set("success_pct_rate", strconv.FormatFloat(n.successRate, 'f', 2, 64))
+	return data
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_writeGraphML(t *testing.T) {
+	nodes := []graphNode{
+		{id: "sta1", position: "[1.5, -2, 0]", rxBytes: "10", rxPackets: "?", txBytes: "30", txPackets: "4", successRate: 0.5},
+		{id: "ap1", position: "0,0,0", accessPoint: true, successRate: 1},
+	}
+	edges := []graphEdge{
+		{"ap1-sta1", "ap1", "sta1"},
+		{"sta1-sta2", "sta1", "sta2"}, // sta2 has no position, so was not collected
+	}
+	pth := filepath.Join(t.TempDir(), graphMLFile)
+	if err := writeGraphML(pth, 3, nodes, edges); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc graphMLDocument
+	if err := xml.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v", err)
+	}
+	if doc.Graph.ID != "timeframe3" || doc.Graph.EdgeDefault != "directed" {
+		t.Errorf("graph = %q (%s edges), want timeframe3 (directed edges)", doc.Graph.ID, doc.Graph.EdgeDefault)
+	}
+	if len(doc.Graph.Edges) != len(edges) {
+		t.Errorf("wrote %d edges, want %d", len(doc.Graph.Edges), len(edges))
+	}
+
+	got := make(map[string]map[string]string)
+	for _, n := range doc.Graph.Nodes {
+		got[n.ID] = make(map[string]string)
+		for _, d := range n.Data {
+			got[n.ID][d.Key] = d.Value
+		}
+	}
+	want := map[string]map[string]string{
+		"sta1": {"kind": "station", "position": "[1.5, -2, 0]", "x": "1.5", "y": "-2", "z": "0",
+			"rx_bytes": "10", "tx_bytes": "30", "tx_packets": "4", "success_pct_rate": "0.50"}, // rx_packets does not parse
+		"ap1":  {"kind": "access_point", "position": "0,0,0", "x": "0", "y": "0", "z": "0", "success_pct_rate": "1.00"},
+		"sta2": {}, // declared only as an edge's endpoint
+	}
+	if len(got) != len(want) {
+		t.Fatalf("wrote nodes %v, want %v", got, want)
+	}
+	for id, attrs := range want {
+		if len(got[id]) != len(attrs) {
+			t.Errorf("node %s: attributes = %v, want %v", id, got[id], attrs)
+			continue
+		}
+		for k, v := range attrs {
+			if got[id][k] != v {
+				t.Errorf("node %s: %s = %q, want %q", id, k, got[id][k], v)
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"Omen/modules/2_mn_raw_output_processing/models"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	fullPingDataParquet string = "ping_data.parquet" // name of the cumulative ping data file, as Parquet
+	fullIWDataParquet   string = "final_iw_data.parquet"
+)
+
+// pingRow is a row of the Parquet form of the cumulative ping data.
+// Columns match those of writePingAllFull, less the relic (always constant) columns, but are typed.
+// Values that are missing or do not parse are null.
+type pingRow struct {
+	MovementNumber int64    `parquet:"movement_number"`
+	TestFile       string   `parquet:"test_file"`
+	Src            string   `parquet:"src"`
+	Dst            string   `parquet:"dst"`
+	Tx             *int64   `parquet:"tx,optional"`
+	Rx             *int64   `parquet:"rx,optional"`
+	LossPct        *float64 `parquet:"loss_pct,optional"`
+	AvgRttMs       *float64 `parquet:"avg_rtt_ms,optional"`
+	Timestamp      *string  `parquet:"timestamp,optional"` // RFC3339
+}
+
+// iwRow is a row of the Parquet form of the cumulative IW data.
+// Columns match those of writeIWFull, but are typed.
+// Values that are missing (ex: station-only columns of an access point) or do not parse are null.
+// Values that carry units (ex: "-39 dBm") are left as strings.
+type iwRow struct {
+	DeviceType      string   `parquet:"device_type"`
+	TestFile        string   `parquet:"test_file"`
+	DeviceName      string   `parquet:"device_name"`
+	Interface       *string  `parquet:"interface,optional"`
+	ConnectedTo     *string  `parquet:"connected_to,optional"`
+	ConnectedToName *string  `parquet:"connected_to_name,optional"`
+	SSID            *string  `parquet:"ssid,optional"`
+	Freq            *float64 `parquet:"freq,optional"`
+	RXBytes         *int64   `parquet:"rx_bytes,optional"`
+	RXPackets       *int64   `parquet:"rx_packets,optional"`
+	TXBytes         *int64   `parquet:"tx_bytes,optional"`
+	TXPackets       *int64   `parquet:"tx_packets,optional"`
+	Signal          *string  `parquet:"signal,optional"`
+	RxBitrate       *string  `parquet:"rx_bitrate,optional"`
+	TxBitrate       *string  `parquet:"tx_bitrate,optional"`
+	BssFlags        *string  `parquet:"bss_flags,optional"`
+	DtimPeriod      *int64   `parquet:"dtim_period,optional"`
+	BeaconInt       *int64   `parquet:"beacon_int,optional"`
+	Flags           *string  `parquet:"flags,optional"`
+	MTU             *int64   `parquet:"mtu,optional"`
+	Ether           *string  `parquet:"ether,optional"`
+	TxQueueLen      *int64   `parquet:"tx_queue_len,optional"`
+	RXErrors        *int64   `parquet:"rx_errors,optional"`
+	RXDropped       *int64   `parquet:"rx_dropped,optional"`
+	RXOverruns      *int64   `parquet:"rx_overruns,optional"`
+	RXFrame         *int64   `parquet:"rx_frame,optional"`
+	TXErrors        *int64   `parquet:"tx_errors,optional"`
+	TXDropped       *int64   `parquet:"tx_dropped,optional"`
+	TXOverruns      *int64   `parquet:"tx_overruns,optional"`
+	TXCarrier       *int64   `parquet:"tx_carrier,optional"`
+	TXCollisions    *int64   `parquet:"tx_collisions,optional"`
+}
+
+// writePingParquet writes ping data from the complete test to a Parquet file at outputPath.
+// See pingRow for the schema.
+func writePingParquet(outputPath string, parsed []models.ParsedRawFile) (count uint, _ error) {
+	var rows []pingRow
+	for _, p := range parsed {
+		for _, ping := range p.Pings {
+			rows = append(rows, pingRow{
+				MovementNumber: int64(p.Timeframe),
+				TestFile:       ping.TestFile,
+				Src:            ping.Src,
+				Dst:            ping.Dst,
+				Tx:             optInt(ping.Tx),
+				Rx:             optInt(ping.Rx),
+				LossPct:        optFloat(ping.LossPct),
+				AvgRttMs:       optFloat(ping.AvgRttMs),
+				Timestamp:      optString(ping.Timestamp),
+			})
+		}
+	}
+	return uint(len(rows)), writeParquet(outputPath, rows)
+}
+
+// writeIWParquet writes the connection information of all stations, followed by all access points, to a Parquet file at outputPath.
+// See iwRow for the schema.
+func writeIWParquet(outputPath string, parsed []models.ParsedRawFile) (staCount, apCount uint, _ error) {
+	var rows []iwRow
+	for _, p := range parsed {
+		for _, sta := range p.Stations {
+			rows = append(rows, iwRow{
+				DeviceType:      "station",
+				TestFile:        sta.TestFile,
+				DeviceName:      sta.StationName,
+				ConnectedTo:     optString(sta.ConnectedTo),
+				ConnectedToName: optString(sta.ConnectedToName),
+				SSID:            optString(sta.SSID),
+				Freq:            optFloat(sta.Freq),
+				RXBytes:         optInt(sta.RXBytes),
+				RXPackets:       optInt(sta.RXPackets),
+				TXBytes:         optInt(sta.TXBytes),
+				TXPackets:       optInt(sta.TXPackets),
+				Signal:          optString(sta.Signal),
+				RxBitrate:       optString(sta.RxBitrate),
+				TxBitrate:       optString(sta.TxBitrate),
+				BssFlags:        optString(sta.BssFlags),
+				DtimPeriod:      optInt(sta.DtimPeriod),
+				BeaconInt:       optInt(sta.BeaconInt),
+			})
+			staCount += 1
+		}
+	}
+	for _, p := range parsed {
+		for _, ap := range p.APs {
+			rows = append(rows, iwRow{
+				DeviceType:   "access_point",
+				TestFile:     ap.TestFile,
+				DeviceName:   ap.APName,
+				Interface:    optString(ap.Interface),
+				RXBytes:      optInt(ap.RXBytes),
+				RXPackets:    optInt(ap.RXPackets),
+				TXBytes:      optInt(ap.TXBytes),
+				TXPackets:    optInt(ap.TXPackets),
+				Flags:        optString(ap.Flags),
+				MTU:          optInt(ap.MTU),
+				Ether:        optString(ap.Ether),
+				TxQueueLen:   optInt(ap.TxQueueLen),
+				RXErrors:     optInt(ap.RXErrors),
+				RXDropped:    optInt(ap.RXDropped),
+				RXOverruns:   optInt(ap.RXOverruns),
+				RXFrame:      optInt(ap.RXFrame),
+				TXErrors:     optInt(ap.TXErrors),
+				TXDropped:    optInt(ap.TXDropped),
+				TXOverruns:   optInt(ap.TXOverruns),
+				TXCarrier:    optInt(ap.TXCarrier),
+				TXCollisions: optInt(ap.TXCollisions),
+			})
+			apCount += 1
+		}
+	}
+	return staCount, apCount, writeParquet(outputPath, rows)
+}
+
+// writeParquet creates/truncates the file at outputPath and writes rows into it.
+func writeParquet[T any](outputPath string, rows []T) error {
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	wr := parquet.NewGenericWriter[T](f)
+	if _, err := wr.Write(rows); err != nil {
+		return err
+	}
+	if err := wr.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// optString returns nil if s is empty.
+func optString(s string) *string {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil
+	}
+	return &s
+}
+
+// optInt returns nil if s is not an integer.
+func optInt(s string) *int64 {
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+// optFloat returns nil if s is not a number.
+func optFloat(s string) *float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
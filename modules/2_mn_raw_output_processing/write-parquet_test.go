@@ -0,0 +1,41 @@
+package main
+
+import (
+	"Omen/modules/2_mn_raw_output_processing/models"
+	"path/filepath"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func Test_writePingParquet(t *testing.T) {
+	parsed := []models.ParsedRawFile{{
+		Timeframe: 1,
+		Pings: []models.PingRecord{
+			{TestFile: "timeframe1.txt", Src: "sta1", Dst: "ap1", Tx: "1", Rx: "1", LossPct: "0", AvgRttMs: "0.5"},
+			{TestFile: "timeframe1.txt", Src: "sta1", Dst: "sta2", Tx: "1", Rx: "0", LossPct: "100", AvgRttMs: ""},
+		},
+	}}
+
+	pth := filepath.Join(t.TempDir(), fullPingDataParquet)
+	if count, err := writePingParquet(pth, parsed); err != nil {
+		t.Fatal(err)
+	} else if count != 2 {
+		t.Errorf("wrote %d rows, want 2", count)
+	}
+
+	rows, err := parquet.ReadFile[pingRow](pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("read %d rows, want 2", len(rows))
+	}
+	if r := rows[0]; r.MovementNumber != 1 || r.Src != "sta1" || r.Dst != "ap1" ||
+		r.Tx == nil || *r.Tx != 1 || r.LossPct == nil || *r.LossPct != 0 || r.AvgRttMs == nil || *r.AvgRttMs != 0.5 {
+		t.Errorf("unexpected first row: %+v", r)
+	}
+	if rows[1].AvgRttMs != nil {
+		t.Errorf("expected a missing rtt to be null, got %v", *rows[1].AvgRttMs)
+	}
+}
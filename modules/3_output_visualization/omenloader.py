@@ -6,8 +6,8 @@
 WHAT THIS DOES
   One CLI with two subcommands:
     1) graph
-       - Loads up to THREE (nodes.csv, edges.csv) pairs into SQLite with a 
-         per-set prefix, e.g. <prefix>_nodes and <prefix>_edges
+       - Loads any number of (nodes.csv, edges.csv) pairs (--set1-*, --set2-*, ...) into
+         SQLite with a per-set prefix, e.g. <prefix>_nodes and <prefix>_edges
        - Optionally ingests a per-set timeseries CSV (e.g ping_data_movement_0.csv)
          into <prefix>_timeseries
        - Auto-derives latitudes/longitude from 'position+"x,y,z" ' if present
@@ -20,6 +20,8 @@ EXPECTED CSV SHAPES
              (position is "x,y,z" in meters; lat/lon optional fallback)
   edges.csv: id,source,target,status  (id optional; source/dest also accepted)
   ts.csv   : any flat table (e.g., ping_data_movement_*.csv)
+  Any of the above may instead be gzipped (<name>.csv.gz, as written by the coalesce module's --gzip-output);
+  a path given as <name>.csv is loaded from <name>.csv.gz if only the latter exists.
 
 NAMING / SCHEMA
   Per set (prefix = "netA" | "netB" | "netC" ...):
@@ -32,6 +34,12 @@ NAMING / SCHEMA
       <prefix>_edges(id TEXT PK, source TEXT, target TEXT, status TEXT)
       <prefix>_timeseries(...)   # columns taken as-is from CSV
 
+MERGING RUNS
+  Given --run-id (and optionally --run-ts), every row is stamped with run_id and run_ts columns
+  and tables are appended to rather than replaced, so several runs can share one database.
+  Reloading a run ID replaces only that run's rows. Node/edge keys become (run_id, id).
+  Tables created without --run-id cannot be merged into; use a fresh --db.
+
 USAGE (run from: Omen/modules/3_output_visualization): 
   python3 omenloader.py graph \
   --db /opt/homebrew/var/lib/grafana/omen.db \
@@ -51,8 +59,12 @@ python3 omenloader.py timeseries \
 
 import argparse
 import csv
+import gzip
 import math
+import re
 import sqlite3
+import sys
+from datetime import datetime, timezone
 from pathlib import Path
 from typing import Optional, Tuple, Union
 
@@ -60,6 +72,9 @@ import pandas as pd
 
 DEFAULT_DB = "/opt/homebrew/var/lib/grafana/omen.db"
 
+# Number of graph sets defined when no --setN-* argument requests more.
+DEFAULT_GRAPH_SETS = 3
+
 # Columns that represent loss percentages in the CSV (0-100) that we want as 0-1.
 LOSS_PERCENT_COLUMNS = [
     "loss_pct",
@@ -101,13 +116,27 @@ def open_db(db_path: Path) -> sqlite3.Connection:
 
 def resolve_path(p: Union[str, Path], root: Path) -> Path:
     # Resolve a possibly-relative path against --root.
+    # Falls back to the gzipped form (<path>.gz, as written by --gzip-output) if only it exists.
     p = Path(p)
-    return p if p.is_absolute() else (root / p)
+    p = p if p.is_absolute() else (root / p)
+    gz = p.with_name(p.name + ".gz")
+    return gz if not p.exists() and gz.exists() else p
+
+def open_csv(path: Path):
+    # Open a CSV for csv.DictReader, transparently decompressing it if it ends in .gz.
+    # (pandas.read_csv infers compression from the extension on its own.)
+    if path.suffix == ".gz":
+        return gzip.open(path, "rt", newline="", encoding="utf-8")
+    return path.open(newline="", encoding="utf-8")
 
 def qident(name: str) -> str:
     # Quote an identifier for SQLite (avoids clashes / reserved words).
     return '"' + name.replace('"', '""') + '"'
 
+def table_columns(conn: sqlite3.Connection, table: str) -> list:
+    # Column names of table; empty if it does not exist.
+    return [r[1] for r in conn.execute(f"PRAGMA table_info({qident(table)});")]
+
 def to_int(s: Optional[str]) -> Optional[int]:
     # Best-effort int parsing with None/null/'' tolerance.
     if s is None or s == "" or str(s).lower() == "null":
@@ -171,6 +200,39 @@ def severity_from_success(p: Optional[float]) -> str:
         return "warning"
     return "critical"
 
+# ------------------------ Merging runs (--run-id) ------------------------
+
+Run = Tuple[str, str]  # (run_id, run_ts)
+
+def add_run_args(sp: argparse.ArgumentParser):
+    # CLI arguments shared by both subcommands for merging runs into one database.
+    sp.add_argument("--run-id", help="Stamp rows with this run ID and append to existing tables instead of replacing them")
+    sp.add_argument("--run-ts", help="Timestamp to stamp rows with alongside --run-id (default: now, in UTC RFC3339)")
+
+def run_from_args(args: argparse.Namespace) -> Optional[Run]:
+    # The (run_id, run_ts) to stamp rows with, or None if not merging.
+    if not args.run_id:
+        return None
+    return (args.run_id, args.run_ts or datetime.now(timezone.utc).strftime("%Y-%m-%dT%H:%M:%SZ"))
+
+def check_run_scoped(conn: sqlite3.Connection, table: str):
+    # Refuse to merge into a table created without run columns.
+    cols = table_columns(conn, table)
+    if cols and "run_id" not in cols:
+        raise ValueError(f"table '{table}' was created without --run-id, so runs cannot be merged into it. Use a fresh --db.")
+
+def append_run_rows(conn: sqlite3.Connection, table: str, df: pd.DataFrame, run: Run) -> int:
+    # Replace this run's rows of table (creating it if needed) with df, leaving other runs' rows intact.
+    check_run_scoped(conn, table)
+    if table_columns(conn, table):
+        conn.execute(f"DELETE FROM {qident(table)} WHERE run_id = ?;", (run[0],))
+        conn.commit()
+    df = df.copy()
+    df.insert(0, "run_id", run[0])
+    df.insert(1, "run_ts", run[1])
+    df.to_sql(table, conn, if_exists="append", index=False)
+    return len(df)
+
 # ------------------------ GRAPH: schema + ingest ------------------------
 
 def drop_and_create_schema_for_prefix(conn: sqlite3.Connection, prefix: str):
@@ -239,16 +301,70 @@ def ensure_schema_for_prefix(conn: sqlite3.Connection, prefix: str):
     );""")
     conn.commit()
 
+def ensure_run_schema_for_prefix(conn: sqlite3.Connection, prefix: str):
+    # Create run-scoped <prefix>_nodes and <prefix>_edges (keyed by run_id and id) if they do not exist.
+    cur = conn.cursor()
+    nodes_tbl = f"{prefix}_nodes"
+    edges_tbl = f"{prefix}_edges"
+    check_run_scoped(conn, nodes_tbl)
+    check_run_scoped(conn, edges_tbl)
+    cur.execute(f"""
+    CREATE TABLE IF NOT EXISTS {qident(nodes_tbl)} (
+        run_id               TEXT NOT NULL,
+        run_ts               TEXT,
+        id                   TEXT NOT NULL,
+        title                TEXT,
+        subTitle             TEXT,
+        mainStat             REAL,
+        severity             TEXT,
+        detail__rx_bytes     INTEGER,
+        detail__rx_packets   INTEGER,
+        detail__tx_bytes     INTEGER,
+        detail__tx_packets   INTEGER,
+        detail__success_rate REAL,
+        arc__success         REAL,
+        arc__errors          REAL,
+        latitude             REAL,
+        longitude            REAL,
+        PRIMARY KEY (run_id, id)
+    );""")
+    cur.execute(f"""
+    CREATE TABLE IF NOT EXISTS {qident(edges_tbl)} (
+        run_id  TEXT NOT NULL,
+        run_ts  TEXT,
+        id      TEXT NOT NULL,
+        source  TEXT NOT NULL,
+        target  TEXT NOT NULL,
+        status  TEXT,
+        PRIMARY KEY (run_id, id)
+    );""")
+    conn.commit()
+
+def upsert(cur: sqlite3.Cursor, table: str, columns: list, values: tuple, run: Optional[Run]):
+    # INSERT a row into table, updating the existing row on a key conflict.
+    # The key is id or, if run is given, (run_id, id), in which case the run columns are prepended.
+    key = ["id"]
+    if run:
+        columns, values, key = ["run_id", "run_ts"] + columns, run + values, ["run_id", "id"]
+    updates = ",\n              ".join(f"{c}=excluded.{c}" for c in columns if c not in key)
+    cur.execute(f"""
+            INSERT INTO {qident(table)} ({", ".join(columns)})
+            VALUES ({",".join("?" * len(columns))})
+            ON CONFLICT({", ".join(key)}) DO UPDATE SET
+              {updates};
+            """, values)
+
 def ingest_nodes(conn: sqlite3.Connection, prefix: str, csv_path: Path,
-                 base_lat: float, base_lon: float, prefer_pos_over_latlon: bool = True) -> int:
+                 base_lat: float, base_lon: float, prefer_pos_over_latlon: bool = True,
+                 run: Optional[Run] = None) -> int:
     
-    # Insert/UPSERT rows from nodes.csv into <prefix>_nodes.
+    # Insert/UPSERT rows from nodes.csv into <prefix>_nodes (stamped with run, if given).
     # - Derives (lat,lon) from 'position' when available; falls bck to CSV lat/lon.
     # - Computes mainStat/severity/arcs from sucess_pct_rate for Node Graph visuals.
     table = f"{prefix}_nodes"
     cur = conn.cursor()
     count = 0
-    with csv_path.open(newline="", encoding="utf-8") as f:
+    with open_csv(csv_path) as f:
         reader = csv.DictReader(f)
         for row in reader:
             nid = row.get("id")
@@ -278,40 +394,24 @@ def ingest_nodes(conn: sqlite3.Connection, prefix: str, csv_path: Path,
                 if (lat is None or lon is None) and x is not None and y is not None:
                     lat, lon = cartesian_to_geo(x, y, base_lat, base_lon)
 
-            cur.execute(f"""
-            INSERT INTO {qident(table)} (
-              id, title, subTitle, mainStat, severity,
-              detail__rx_bytes, detail__rx_packets, detail__tx_bytes, detail__tx_packets,
-              detail__success_rate, arc__success, arc__errors, latitude, longitude
-            ) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?)
-            ON CONFLICT(id) DO UPDATE SET
-              title=excluded.title,
-              subTitle=excluded.subTitle,
-              mainStat=excluded.mainStat,
-              severity=excluded.severity,
-              detail__rx_bytes=excluded.detail__rx_bytes,
-              detail__rx_packets=excluded.detail__rx_packets,
-              detail__tx_bytes=excluded.detail__tx_bytes,
-              detail__tx_packets=excluded.detail__tx_packets,
-              detail__success_rate=excluded.detail__success_rate,
-              arc__success=excluded.arc__success,
-              arc__errors=excluded.arc__errors,
-              latitude=excluded.latitude,
-              longitude=excluded.longitude;
-            """, (nid, title, sub_title, main_stat, severity,
-                  rx_b, rx_p, tx_b, tx_p, succ, arc_success, arc_errors, lat, lon))
+            upsert(cur, table, [
+                "id", "title", "subTitle", "mainStat", "severity",
+                "detail__rx_bytes", "detail__rx_packets", "detail__tx_bytes", "detail__tx_packets",
+                "detail__success_rate", "arc__success", "arc__errors", "latitude", "longitude",
+            ], (nid, title, sub_title, main_stat, severity,
+                rx_b, rx_p, tx_b, tx_p, succ, arc_success, arc_errors, lat, lon), run)
             count += 1
     conn.commit()
     return count
 
-def ingest_edges(conn: sqlite3.Connection, prefix: str, csv_path: Path) -> int:
-    # Insert/UPSERT rows from edges.csv into <prefix>_edges.
+def ingest_edges(conn: sqlite3.Connection, prefix: str, csv_path: Path, run: Optional[Run] = None) -> int:
+    # Insert/UPSERT rows from edges.csv into <prefix>_edges (stamped with run, if given).
     # - If id missing, derive "source-target".
     # - Accepts 'source' | 'src' and 'target' | 'dst' naming variants.
     table = f"{prefix}_edges"
     cur = conn.cursor()
     count = 0
-    with csv_path.open(newline="", encoding="utf-8") as f:
+    with open_csv(csv_path) as f:
         reader = csv.DictReader(f)
         for row in reader:
             edge_id = row.get("id")
@@ -322,14 +422,7 @@ def ingest_edges(conn: sqlite3.Connection, prefix: str, csv_path: Path) -> int:
                 continue
             if not edge_id:
                 edge_id = f"{src}-{tgt}"
-            cur.execute(f"""
-            INSERT INTO {qident(table)} (id, source, target, status)
-            VALUES (?,?,?,?)
-            ON CONFLICT(id) DO UPDATE SET
-              source=excluded.source,
-              target=excluded.target,
-              status=excluded.status;
-            """, (edge_id, src, tgt, status))
+            upsert(cur, table, ["id", "source", "target", "status"], (edge_id, src, tgt, status), run)
             count += 1
     conn.commit()
     return count
@@ -344,13 +437,20 @@ def ingest_timeseries_raw(conn: sqlite3.Connection, table: str, csv_path: Path,
 
 # ------------------------ Subcommand: graph ------------------------
 
-def add_graph_args(sp: argparse.ArgumentParser):
-    # CLI arguments for the graph subcommand (supports up to 3 sets).
+def graph_set_count(argv) -> int:
+    # Number of sets to define for 'graph': the highest N among any --setN-* arguments, and at least DEFAULT_GRAPH_SETS.
+    found = [int(m.group(1)) for a in argv if (m := re.match(r"--set(\d+)-", a))]
+    return max([DEFAULT_GRAPH_SETS, *found])
+
+def add_graph_args(sp: argparse.ArgumentParser, sets: int = DEFAULT_GRAPH_SETS):
+    # CLI arguments for the graph subcommand (supports sets 1 through `sets`).
     sp.add_argument("--db", default=DEFAULT_DB, help=f"SQLite DB path (default: {DEFAULT_DB})")
-    sp.add_argument("--recreate", action="store_true", help="Drop & recreate tables for any provided set")
+    sp.add_argument("--recreate", action="store_true", help="Drop & recreate tables for any provided set (cannot be combined with --run-id)")
+    add_run_args(sp)
     sp.add_argument("--root", type=Path, default=Path(__file__).resolve().parent,
                     help="Base directory to resolve relative CSV paths (default: script folder)")
-    for i in (1, 2, 3):
+    sp.set_defaults(sets=sets)
+    for i in range(1, sets + 1):
         sp.add_argument(f"--set{i}-prefix", help=f"Table prefix for set {i}")
         sp.add_argument(f"--set{i}-dir", type=Path, help=f"Directory containing nodes.csv and edges.csv for set {i} (and optionally ping_data_movement_*.csv)")
         sp.add_argument(f"--set{i}-nodes", type=Path, help=f"nodes.csv for set {i}")
@@ -361,21 +461,24 @@ def add_graph_args(sp: argparse.ArgumentParser):
         sp.add_argument(f"--set{i}-pos-base-lon", type=float, default=-122.1690, help=f"Base longitude for set {i}")
 
 def _auto_detect_timeseries(set_dir: Path) -> Optional[Path]:
-    #If a set dir is provided, try to find a ping_data_movement_*.csv inside it.
+    #If a set dir is provided, try to find a ping_data_movement_*.csv (or .csv.gz) inside it.
     # Returns the first match if found, else None.
     if not set_dir:
         return None
-    candidates = sorted(set_dir.glob("ping_data_movement_*.csv"))
+    candidates = sorted([*set_dir.glob("ping_data_movement_*.csv"), *set_dir.glob("ping_data_movement_*.csv.gz")])
     return candidates[0] if candidates else None
 
 def run_graph(args: argparse.Namespace):
     # Driver for 'graph': load per-set nodes/edges (+ optional timeseries) into SQLite.
     root = args.root.resolve()
+    run = run_from_args(args)
+    if run and args.recreate:
+        raise ValueError("--recreate cannot be combined with --run-id (which merges into existing tables)")
     conn = open_db(Path(args.db))
     used = 0
 
     def process_set(idx: int):
-        # Process one of the up to three graph sets. 
+        # Process one of the graph sets. 
         prefix = getattr(args, f"set{idx}_prefix")
         set_dir = getattr(args, f"set{idx}_dir")
         nodes = getattr(args, f"set{idx}_nodes")
@@ -407,7 +510,9 @@ def run_graph(args: argparse.Namespace):
             raise FileNotFoundError(f"Set {idx}: edges file not found: {edges_path}")
 
         # Create/ensure schemas
-        if args.recreate:
+        if run:
+            ensure_run_schema_for_prefix(conn, prefix)
+        elif args.recreate:
             drop_and_create_schema_for_prefix(conn, prefix)
         else:
             ensure_schema_for_prefix(conn, prefix)
@@ -416,8 +521,8 @@ def run_graph(args: argparse.Namespace):
         lat = getattr(args, f"set{idx}_pos_base_lat")
         lon = getattr(args, f"set{idx}_pos_base_lon")
 
-        n = ingest_nodes(conn, prefix, nodes_path, base_lat=lat, base_lon=lon)
-        e = ingest_edges(conn, prefix, edges_path)
+        n = ingest_nodes(conn, prefix, nodes_path, base_lat=lat, base_lon=lon, run=run)
+        e = ingest_edges(conn, prefix, edges_path, run=run)
 
         # Optional per-set timeseries 
         if ts:
@@ -425,7 +530,10 @@ def run_graph(args: argparse.Namespace):
             if not ts_path.exists():
                 raise FileNotFoundError(f"Set {idx}: timeseries file not found: {ts_path}")
             ts_tbl = ts_table or f"{prefix}_timeseries"
-            rows = ingest_timeseries_raw(conn, ts_tbl, ts_path, if_exists="replace")
+            if run:
+                rows = append_run_rows(conn, ts_tbl, normalize_loss_fraction(pd.read_csv(ts_path)), run)
+            else:
+                rows = ingest_timeseries_raw(conn, ts_tbl, ts_path, if_exists="replace")
             print(f"[{prefix}] loaded timeseries table={ts_tbl} rows={rows}")
 
         # Helpful indexes for Grafana queries
@@ -435,16 +543,16 @@ def run_graph(args: argparse.Namespace):
         cur.execute(f"CREATE INDEX IF NOT EXISTS {qident(f'idx_{prefix}_edges_tgt')} ON {qident(prefix+'_edges')}(target);")
         conn.commit()
 
-        print(f"[{prefix}] loaded nodes={n}, edges={e}")
+        print(f"[{prefix}] loaded nodes={n}, edges={e}" + (f" for run {run[0]}" if run else ""))
         return True
     
-    # Process up to three sets
-    for i in (1, 2, 3):
+    # Process every defined set
+    for i in range(1, args.sets + 1):
         if process_set(i):
             used += 1
 
     if used == 0:
-        print("No sets provided. Use --set{1|2|3}-prefix + (--set{1|2|3}-dir OR --set{1|2|3}-nodes + --set{1|2|3}-edges) and optionally --set{1|2|3}-ts.")
+        print("No sets provided. Use --setN-prefix + (--setN-dir OR --setN-nodes + --setN-edges) and optionally --setN-ts, for N = 1, 2, ...")
     else:
         print(f"Done. Processed {used} set(s). DB: {args.db}")
     conn.close()
@@ -458,7 +566,9 @@ def add_timeseries_args(sp: argparse.ArgumentParser):
     sp.add_argument("--table", required=True, help="Destination table name for raw data")
     sp.add_argument("--aggregate-by", default=None, help="Column to group by (e.g., 'movement_number')")
     sp.add_argument("--aggregate-into", default=None, help="Name of aggregated result table (default: <table>_agg)")
-    sp.add_argument("--if-exists", choices=["replace", "append", "fail"], default="replace")
+    sp.add_argument("--if-exists", choices=["replace", "append", "fail"], default="replace",
+                    help="What to do if --table exists. Ignored with --run-id, which always merges")
+    add_run_args(sp)
     sp.add_argument("--root", type=Path, default=Path(__file__).resolve().parent,
                     help="Base directory to resolve relative CSV paths (default: script folder)")
 
@@ -479,8 +589,15 @@ def run_timeseries(args: argparse.Namespace):
     conn = open_db(Path(args.db))
     
     # Raw table
-    df.to_sql(args.table, conn, if_exists=args.if_exists, index=False)
-    print(f"Inserted raw table '{args.table}' into {args.db}.")
+    run = run_from_args(args)
+    if run:
+        append_run_rows(conn, args.table, df, run)
+        print(f"Merged run '{run[0]}' into raw table '{args.table}' in {args.db}.")
+        # aggregate across every run in the table, keeping runs apart
+        df = pd.read_sql(f"SELECT * FROM {qident(args.table)};", conn)
+    else:
+        df.to_sql(args.table, conn, if_exists=args.if_exists, index=False)
+        print(f"Inserted raw table '{args.table}' into {args.db}.")
     
     # Optional aggregation
     if args.aggregate_by:
@@ -489,7 +606,8 @@ def run_timeseries(args: argparse.Namespace):
         
         #Keep key column; average numeric columns only
         df_coerced = df.apply(pd.to_numeric, errors="ignore")
-        grouped = df_coerced.groupby(args.aggregate_by, as_index=False).mean(numeric_only=True)
+        keys = ["run_id", "run_ts", args.aggregate_by] if run else args.aggregate_by
+        grouped = df_coerced.groupby(keys, as_index=False).mean(numeric_only=True)
 
         agg_name = args.aggregate_into or f"{args.table}_agg"
         grouped.to_sql(agg_name, conn, if_exists="replace", index=False)
@@ -507,7 +625,7 @@ def main():
     
     # graph subcommand
     sp_graph = sub.add_parser("graph", help="Load nodes/edges CSVs into prefixed tables (+ optional per-set timeseries)")
-    add_graph_args(sp_graph)
+    add_graph_args(sp_graph, graph_set_count(sys.argv[1:]))
     sp_graph.set_defaults(func=run_graph)
     
     # timeseries subcommand
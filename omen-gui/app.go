@@ -5,22 +5,27 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"maps"
 	"net/netip"
 	"os"
+	"path/filepath"
 	"slices"
 	"strconv"
 
 	"github.com/rs/zerolog"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-const outPath string = "in.json"
+const outFileName string = "in.json"
 
 // App is the driver application itself.
 // Input is fully composed and marshaled in GenerateJSON.
 type App struct {
-	ctx context.Context
-	log zerolog.Logger
+	ctx      context.Context
+	log      zerolog.Logger
+	settings settings // persisted between launches
 
 	// input components
 
@@ -37,8 +42,13 @@ func NewApp() (*App, error) {
 		Timestamp().
 		Caller().
 		Logger().Level(zerolog.DebugLevel)
+	st, err := loadSettings()
+	if err != nil {
+		l.Warn().Err(err).Msg("failed to load settings; using defaults")
+	}
 	return &App{
-		log: l,
+		log:      l,
+		settings: st,
 
 		aps: map[string]AP{},
 		sta: map[string]Sta{},
@@ -51,6 +61,41 @@ func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
+// beforeClose is called when the window is about to close.
+// It records the current window dimensions and persists the settings.
+// Never prevents closing.
+func (a *App) beforeClose(ctx context.Context) (prevent bool) {
+	if w, h := runtime.WindowGetSize(ctx); w > 0 && h > 0 {
+		a.settings.Width, a.settings.Height = w, h
+	}
+	if err := a.settings.save(); err != nil {
+		a.log.Warn().Err(err).Msg("failed to save settings")
+	}
+	return false
+}
+
+// GetOutputDir returns the directory GenerateJSON will write into.
+// The empty string indicates the current working directory.
+func (a *App) GetOutputDir() string {
+	return a.settings.OutputDir
+}
+
+// SetOutputDir sets the directory GenerateJSON will write into.
+// The directory must already exist.
+// The empty string uses the current working directory.
+func (a *App) SetOutputDir(dir string) error {
+	if dir != "" {
+		if inf, err := os.Stat(dir); err != nil {
+			return err
+		} else if !inf.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+	}
+	a.settings.OutputDir = dir
+	a.log.Info().Str("directory", dir).Msg("set output directory")
+	return nil
+}
+
 // AddAP inserts a new access point to be marshalled into the Input.
 func (a *App) AddAP(ap AP) {
 	// check if we are adding or editing
@@ -75,56 +120,128 @@ func (a *App) AddSta(sta Sta) {
 	}
 }
 
+// ClearTopology drops every access point and station so a fresh topology can be composed.
+func (a *App) ClearTopology() {
+	a.aps = map[string]AP{}
+	a.sta = map[string]Sta{}
+	a.log.Info().Msg("cleared topology")
+}
+
+// ListAPs returns every access point currently held, sorted by ID.
+func (a *App) ListAPs() []AP {
+	aps := make([]AP, 0, len(a.aps))
+	for _, id := range slices.Sorted(maps.Keys(a.aps)) {
+		aps = append(aps, a.aps[id])
+	}
+	return aps
+}
+
+// ListStations returns every station currently held, sorted by ID.
+func (a *App) ListStations() []Sta {
+	stas := make([]Sta, 0, len(a.sta))
+	for _, id := range slices.Sorted(maps.Keys(a.sta)) {
+		stas = append(stas, a.sta[id])
+	}
+	return stas
+}
+
+// GetAP returns the access point with the given ID.
+func (a *App) GetAP(id string) (AP, error) {
+	ap, found := a.aps[id]
+	if !found {
+		return AP{}, fmt.Errorf("no access point with id %q", id)
+	}
+	return ap, nil
+}
+
+// GetSta returns the station with the given ID.
+func (a *App) GetSta(id string) (Sta, error) {
+	sta, found := a.sta[id]
+	if !found {
+		return Sta{}, fmt.Errorf("no station with id %q", id)
+	}
+	return sta, nil
+}
+
+// ValidateAddress checks that the given host and port form a valid ssh address.
+// Intended to be called as the user fills out the SSH fields, prior to GenerateJSON.
+func (a *App) ValidateAddress(host string, port uint) error {
+	_, err := composeAddress(host, port)
+	return err
+}
+
+// composeAddress joins the host and port into a "<host>:<port>" string and ensures it is a valid address.
+// The composed string is returned even if it is invalid.
+func composeAddress(host string, port uint) (string, error) {
+	strAddr := host + ":" + strconv.FormatUint(uint64(port), 10)
+	addr, err := netip.ParseAddrPort(strAddr)
+	if err != nil {
+		return strAddr, fmt.Errorf("failed to parse ssh address %q: %w", strAddr, err)
+	} else if !addr.IsValid() {
+		return strAddr, fmt.Errorf("ssh address %q is not valid", strAddr)
+	}
+	return strAddr, nil
+}
+
 // GenerateJSON composes an input json from the current input values.
+// Returns the path to the generated file.
+// If validation fails, all validation errors are joined into the returned error.
 // NOTE(rlandau): validation is expected to have taken place before this point!
-func (a *App) GenerateJSON(runName, sshUsername, sshPassword, sshHost string, sshPort uint, net Nets, tests []Test) (success bool) {
+func (a *App) GenerateJSON(runName, sshUsername, sshPassword, sshHost string, sshPort uint, net Nets, tests []Test) (string, error) {
 	// set non-inputtable data and pass in data not already held in the backend
 	var i = Input{
-		SchemaVersion: "1.0",
+		SchemaVersion: SchemaVersion,
 		Meta: Meta{
 			Backend:   "mininet-wifi",
 			Name:      runName,
 			DurationS: 60, // unused
 		},
 		Topo: Topo{
-			Nets: net,
-			// APs are held in the App
-			// Stations are held in the App
+			Nets:     net,
+			Aps:      slices.Collect(maps.Values(a.aps)),
+			Stations: slices.Collect(maps.Values(a.sta)),
 		},
 		Tests:    tests,
 		Username: sshUsername,
 		Password: sshPassword,
 		// address is parsed after this
 	}
+	// collect every validation error so they can be presented at once
+	var errs []error
 	{
-		strAddr := sshHost + ":" + strconv.FormatUint(uint64(sshPort), 10)
-		addr, err := netip.ParseAddrPort(sshHost + ":" + strconv.FormatUint(uint64(sshPort), 10))
-		if err != nil || !addr.IsValid() {
+		strAddr, err := composeAddress(sshHost, sshPort)
+		if err != nil {
 			a.log.Error().Str("given", strAddr).Err(err).Msg("failed to parse ssh address")
-			return false
+			errs = append(errs, err)
 		}
 		i.Address = strAddr
 	}
+	// refuse to write a file the input validator will reject
+	errs = append(errs, i.checkSchema()...)
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
 
+	outPath := filepath.Join(a.settings.OutputDir, outFileName)
 	f, err := os.Create(outPath)
 	if err != nil {
 		a.log.Error().Err(err).Str("output path", outPath).Msg("failed to create output file")
-		return false
+		return "", fmt.Errorf("failed to create output file %s: %w", outPath, err)
 	}
 	defer f.Close()
 
-	// compose all values into struct
-	i.Topo.Aps = slices.Collect(maps.Values(a.aps))
-	i.Topo.Stations = slices.Collect(maps.Values(a.sta))
-
 	a.log.Debug().Any("values", i).Msg("encoding values...")
 
 	enc := json.NewEncoder(f)
 	if err := enc.Encode(i); err != nil {
 		a.log.Error().Err(err).Str("output path", outPath).Msg("failed to encode values")
-		return false
+		return "", fmt.Errorf("failed to encode values into %s: %w", outPath, err)
 	}
 	a.log.Info().Str("output path", outPath).Msg("successfully generated JSON")
 
-	return true
+	// return the absolute path, if we can, so the user knows exactly where the file went
+	if abs, err := filepath.Abs(outPath); err == nil {
+		return abs, nil
+	}
+	return outPath, nil
 }
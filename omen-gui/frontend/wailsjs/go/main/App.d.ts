@@ -6,4 +6,22 @@ export function AddAP(arg1:main.AP):Promise<void>;
 
 export function AddSta(arg1:main.Sta):Promise<void>;
 
-export function GenerateJSON(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number,arg6:main.Nets,arg7:Array<main.Test>):Promise<boolean>;
+export function ClearTopology():Promise<void>;
+
+export function GenerateJSON(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number,arg6:main.Nets,arg7:Array<main.Test>):Promise<string>;
+
+export function GetAP(arg1:string):Promise<main.AP>;
+
+export function GetOutputDir():Promise<string>;
+
+export function GetSta(arg1:string):Promise<main.Sta>;
+
+export function ImportNodesCSV(arg1:string):Promise<number>;
+
+export function ListAPs():Promise<Array<main.AP>>;
+
+export function ListStations():Promise<Array<main.Sta>>;
+
+export function SetOutputDir(arg1:string):Promise<void>;
+
+export function ValidateAddress(arg1:string,arg2:number):Promise<void>;
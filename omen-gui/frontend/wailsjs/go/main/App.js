@@ -10,6 +10,42 @@ export function AddSta(arg1) {
   return window['go']['main']['App']['AddSta'](arg1);
 }
 
+export function ClearTopology() {
+  return window['go']['main']['App']['ClearTopology']();
+}
+
 export function GenerateJSON(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
   return window['go']['main']['App']['GenerateJSON'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
 }
+
+export function GetAP(arg1) {
+  return window['go']['main']['App']['GetAP'](arg1);
+}
+
+export function GetOutputDir() {
+  return window['go']['main']['App']['GetOutputDir']();
+}
+
+export function GetSta(arg1) {
+  return window['go']['main']['App']['GetSta'](arg1);
+}
+
+export function ImportNodesCSV(arg1) {
+  return window['go']['main']['App']['ImportNodesCSV'](arg1);
+}
+
+export function ListAPs() {
+  return window['go']['main']['App']['ListAPs']();
+}
+
+export function ListStations() {
+  return window['go']['main']['App']['ListStations']();
+}
+
+export function SetOutputDir(arg1) {
+  return window['go']['main']['App']['SetOutputDir'](arg1);
+}
+
+export function ValidateAddress(arg1, arg2) {
+  return window['go']['main']['App']['ValidateAddress'](arg1, arg2);
+}
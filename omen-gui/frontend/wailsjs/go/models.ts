@@ -12,6 +12,10 @@ export namespace main {
 	    n = "n",
 	    ac = "ac",
 	}
+	export enum TestType {
+	    Ping = "ping",
+	    NodeMovements = "node movements",
+	}
 	export class AP {
 	    id: string;
 	    mode: string;
@@ -97,10 +101,13 @@ export namespace main {
 	}
 	export class Test {
 	    name: string;
-	    type: string;
+	    type: TestType;
 	    timeframe: number;
-	    node: string;
-	    position: string;
+	    node?: string;
+	    position?: string;
+	    src?: string;
+	    dst?: string;
+	    count?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Test(source);
@@ -113,6 +120,9 @@ export namespace main {
 	        this.timeframe = source["timeframe"];
 	        this.node = source["node"];
 	        this.position = source["position"];
+	        this.src = source["src"];
+	        this.dst = source["dst"];
+	        this.count = source["count"];
 	    }
 	}
 
@@ -0,0 +1,100 @@
+package main
+
+// This file contains the functionality for importing nodes from external files.
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ImportNodesCSV reads the CSV at path and inserts each row as an access point or station.
+// Rows must be of the form `id,position[,mode,channel,ssid]`.
+// Rows with only an id and position are stations; rows that also specify mode, channel, and ssid are APs.
+// As positions are of the form "x,y,z", they must be quoted.
+// A header row (first column "id") is optional.
+//
+// The import is all-or-nothing: if any row is malformed, no nodes are inserted.
+// Existing nodes with a matching ID are overwritten.
+func (a *App) ImportNodesCSV(path string) (added int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	aps, stas, err := parseNodesCSV(f)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for _, ap := range aps {
+		a.AddAP(ap)
+	}
+	for _, sta := range stas {
+		a.AddSta(sta)
+	}
+	a.log.Info().Str("path", path).Int("access points", len(aps)).Int("stations", len(stas)).Msg("imported nodes")
+
+	return len(aps) + len(stas), nil
+}
+
+// parseNodesCSV consumes rows of `id,position[,mode,channel,ssid]` from r.
+// See ImportNodesCSV.
+func parseNodesCSV(r io.Reader) (aps []AP, stas []Sta, _ error) {
+	rdr := csv.NewReader(r)
+	rdr.FieldsPerRecord = -1 // stations and APs have differing field counts
+	rdr.TrimLeadingSpace = true
+
+	var errs []error
+	for first := true; ; first = false {
+		record, err := rdr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		line, _ := rdr.FieldPos(0)
+		// skip the header
+		if first && strings.EqualFold(strings.TrimSpace(record[0]), "id") {
+			continue
+		}
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+
+		if record[0] == "" {
+			errs = append(errs, fmt.Errorf("line %d: id cannot be empty", line))
+			continue
+		}
+		switch len(record) {
+		case 2:
+			stas = append(stas, Sta{ID: record[0], Position: record[1]})
+		case 5:
+			channel, err := strconv.Atoi(record[3])
+			if err != nil {
+				errs = append(errs, fmt.Errorf("line %d: channel %q must be a number", line, record[3]))
+				continue
+			}
+			aps = append(aps, AP{
+				ID:       record[0],
+				Position: record[1],
+				Mode:     record[2],
+				Channel:  channel,
+				SSID:     record[4],
+			})
+		default:
+			errs = append(errs, fmt.Errorf("line %d: expected 2 (station) or 5 (access point) fields, found %d."+
+				" Is the position quoted?", line, len(record)))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+
+	return aps, stas, nil
+}
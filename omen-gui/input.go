@@ -2,6 +2,12 @@ package main
 
 // This file exists because wails does not support anonymous structs so every sub-struct must be named.
 
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
 //#region enums
 
 // PropModel enumerates the three, supported propagation models mn-wifi supports
@@ -44,6 +50,32 @@ var AllWifiModes = []struct {
 	{AC, "ac"},
 }
 
+// TestType enumerates the test types the mininet driver script supports
+type TestType string
+
+const (
+	Ping          TestType = "ping"
+	NodeMovements TestType = "node movements"
+)
+
+var AllTestTypes = []struct {
+	Value  TestType
+	TSName string
+}{
+	{Ping, "Ping"},
+	{NodeMovements, "NodeMovements"},
+}
+
+// Supported returns whether or not the test type is one of the enumerated types.
+func (t TestType) Supported() bool {
+	for _, tt := range AllTestTypes {
+		if tt.Value == t {
+			return true
+		}
+	}
+	return false
+}
+
 //#endregion enums
 
 type Meta struct {
@@ -87,11 +119,14 @@ type Sta struct {
 //#endregion Topo and its children
 
 type Test struct {
-	Name      string `json:"name"`
-	Type      string `json:"type"`
-	Timeframe int    `json:"timeframe"`
-	Node      string `json:"node"`
-	Position  string `json:"position"`
+	Name      string   `json:"name"`
+	Type      TestType `json:"type"`
+	Timeframe int      `json:"timeframe"`
+	Node      string   `json:"node,omitempty"`     // node to move (for NodeMovements)
+	Position  string   `json:"position,omitempty"` // position to move Node to (for NodeMovements)
+	Src       string   `json:"src,omitempty"`      // node to ping from (for Ping)
+	Dst       string   `json:"dst,omitempty"`      // node to ping (for Ping)
+	Count     int      `json:"count,omitempty"`    // number of pings to send; the driver's default if 0 (for Ping)
 }
 
 type Input struct {
@@ -103,3 +138,67 @@ type Input struct {
 	Password      string `json:"password"`
 	Address       string `json:"address"`
 }
+
+// SchemaVersion is the version of the input schema GenerateJSON produces.
+const SchemaVersion = "1.0"
+
+// checkSchema performs a lightweight check that i is complete enough to pass the input validator.
+// Every violation found is returned.
+func (i Input) checkSchema() []error {
+	var errs []error
+	if i.SchemaVersion != SchemaVersion {
+		errs = append(errs, fmt.Errorf("unsupported schema version %q (expected %q)", i.SchemaVersion, SchemaVersion))
+	}
+	if len(i.Topo.Aps) == 0 {
+		errs = append(errs, errors.New("at least 1 access point is required"))
+	}
+	if len(i.Topo.Stations) == 0 {
+		errs = append(errs, errors.New("at least 1 station is required"))
+	}
+	if len(i.Tests) == 0 {
+		errs = append(errs, errors.New("at least 1 test is required"))
+	}
+	stations := make(map[string]bool, len(i.Topo.Stations))
+	nodes := make(map[string]bool, len(i.Topo.Aps)+len(i.Topo.Stations)) // every declared node, which pings may reference
+	for _, sta := range i.Topo.Stations {
+		stations[sta.ID] = true
+		nodes[sta.ID] = true
+	}
+	for _, ap := range i.Topo.Aps {
+		nodes[ap.ID] = true
+	}
+	for idx, t := range i.Tests {
+		name := t.Name
+		if name == "" {
+			name = "#" + strconv.Itoa(idx)
+			errs = append(errs, fmt.Errorf("test %s must have a name", name))
+		}
+		if !t.Type.Supported() {
+			errs = append(errs, fmt.Errorf("test %q has unsupported type %q", name, t.Type))
+		}
+		if t.Timeframe < 0 {
+			errs = append(errs, fmt.Errorf("test %q has negative timeframe %d", name, t.Timeframe))
+		}
+		if t.Type == NodeMovements {
+			if !stations[t.Node] {
+				errs = append(errs, fmt.Errorf("test %q moves %q, which is not a station", name, t.Node))
+			}
+			if t.Position == "" {
+				errs = append(errs, fmt.Errorf("test %q must specify a position", name))
+			}
+		}
+		if t.Type == Ping {
+			for _, end := range []struct{ field, node string }{{"src", t.Src}, {"dst", t.Dst}} {
+				if end.node == "" {
+					errs = append(errs, fmt.Errorf("test %q must specify a %s", name, end.field))
+				} else if !nodes[end.node] {
+					errs = append(errs, fmt.Errorf("test %q has %s %q, which is not a declared node", name, end.field, end.node))
+				}
+			}
+			if t.Count < 0 {
+				errs = append(errs, fmt.Errorf("test %q has negative count %d", name, t.Count))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// validInput returns an Input that passes checkSchema, for cases to break.
+func validInput() Input {
+	return Input{
+		SchemaVersion: SchemaVersion,
+		Topo: Topo{
+			Aps:      []AP{{ID: "ap1", Mode: "a", Channel: 36, SSID: "omen", Position: "0,0,0"}},
+			Stations: []Sta{{ID: "sta1", Position: "1,1,0"}, {ID: "sta2", Position: "2,2,0"}},
+		},
+		Tests: []Test{
+			{Name: "move sta1", Type: NodeMovements, Timeframe: 1, Node: "sta1", Position: "5,5,0"},
+			{Name: "ping ap1", Type: Ping, Timeframe: 1, Src: "sta1", Dst: "ap1", Count: 3},
+		},
+	}
+}
+
+func TestInputCheckSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Input)
+		want   []string // substrings of each expected error, in order
+	}{
+		{"valid", func(*Input) {}, nil},
+		{"wrong schema version", func(i *Input) { i.SchemaVersion = "0.9" }, []string{"unsupported schema version"}},
+		{"no APs", func(i *Input) {
+			i.Topo.Aps = nil
+			i.Tests = i.Tests[:1]
+		}, []string{"access point is required"}},
+		{"no stations", func(i *Input) {
+			i.Topo.Stations = nil
+			i.Tests = nil
+		}, []string{"station is required", "test is required"}},
+		{"unnamed test", func(i *Input) { i.Tests[0].Name = "" }, []string{"test #0 must have a name"}},
+		{"unsupported type", func(i *Input) { i.Tests[0].Type = "iperf" }, []string{`unsupported type "iperf"`}},
+		{"negative timeframe", func(i *Input) { i.Tests[0].Timeframe = -1 }, []string{"negative timeframe"}},
+		{"movement of a non-station", func(i *Input) { i.Tests[0].Node = "ap1" }, []string{`moves "ap1", which is not a station`}},
+		{"movement without a position", func(i *Input) { i.Tests[0].Position = "" }, []string{"must specify a position"}},
+		{"ping without ends", func(i *Input) { i.Tests[1].Src, i.Tests[1].Dst = "", "" }, []string{"must specify a src", "must specify a dst"}},
+		{"ping from an undeclared node", func(i *Input) { i.Tests[1].Src = "sta9" }, []string{`src "sta9", which is not a declared node`}},
+		{"ping to an undeclared node", func(i *Input) { i.Tests[1].Dst = "h1" }, []string{`dst "h1", which is not a declared node`}},
+		{"ping between stations", func(i *Input) { i.Tests[1].Dst = "sta2" }, nil},
+		{"negative ping count", func(i *Input) { i.Tests[1].Count = -1 }, []string{"negative count"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := validInput()
+			tt.modify(&in)
+			errs := in.checkSchema()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("checkSchema() = %v, want %d error(s)", errs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("error %d = %q, want it to contain %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestComposeAddress(t *testing.T) {
+	tests := []struct {
+		host    string
+		port    uint
+		want    string
+		wantErr bool
+	}{
+		{"127.0.0.1", 22, "127.0.0.1:22", false},
+		{"192.168.64.5", 2222, "192.168.64.5:2222", false},
+		{"", 22, ":22", true},
+		{"mininet.local", 22, "mininet.local:22", true},
+		{"127.0.0.1", 65536, "127.0.0.1:65536", true},
+	}
+	for _, tt := range tests {
+		got, err := composeAddress(tt.host, tt.port)
+		if got != tt.want {
+			t.Errorf("composeAddress(%q, %d) = %q, want %q", tt.host, tt.port, got, tt.want)
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("composeAddress(%q, %d) error = %v, wantErr %v", tt.host, tt.port, err, tt.wantErr)
+		}
+	}
+}
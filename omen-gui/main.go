@@ -27,19 +27,21 @@ func main() {
 	// set the basic parameters and bound objects
 	opts := options.App{
 		Title:  "input generator",
-		Width:  1024,
-		Height: 768,
+		Width:  app.settings.Width,
+		Height: app.settings.Height,
 		AssetServer: &assetserver.Options{
 			Assets: assets,
 		},
 		BackgroundColour: &options.RGBA{R: 60, G: 60, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnBeforeClose:    app.beforeClose,
 		Bind: []any{
 			app,
 		},
 		EnumBind: []any{
 			AllPropModels,
 			AllWifiModes,
+			AllTestTypes,
 		},
 	}
 
@@ -0,0 +1,79 @@
+package main
+
+// This file handles the settings persisted between launches of the GUI.
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	settingsDirName  string = "omen-gui"
+	settingsFileName string = "settings.json"
+)
+
+// Default window dimensions, used when no settings have been saved yet.
+const (
+	defaultWidth  int = 1024
+	defaultHeight int = 768
+)
+
+// settings holds user preferences that should survive a restart.
+// They are stored as JSON in the user's config directory.
+type settings struct {
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	OutputDir string `json:"output_dir"` // directory GenerateJSON writes into
+}
+
+// settingsPath returns the path to the settings file within the user's config directory.
+func settingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, settingsDirName, settingsFileName), nil
+}
+
+// loadSettings reads the settings file.
+// If the file does not exist, the default settings are returned.
+// Invalid or missing values are replaced by defaults.
+func loadSettings() (settings, error) {
+	s := settings{Width: defaultWidth, Height: defaultHeight}
+	pth, err := settingsPath()
+	if err != nil {
+		return s, err
+	}
+	data, err := os.ReadFile(pth)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return settings{Width: defaultWidth, Height: defaultHeight}, err
+	}
+	if s.Width <= 0 || s.Height <= 0 {
+		s.Width, s.Height = defaultWidth, defaultHeight
+	}
+	return s, nil
+}
+
+// save writes the settings into the settings file, creating the file and its directory if necessary.
+func (s settings) save() error {
+	pth, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(pth), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(pth, data, 0644)
+}
@@ -3,18 +3,114 @@
 package omen
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
 	"strings"
 
+	"github.com/charmbracelet/colorprofile"
 	"github.com/charmbracelet/fang"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
 )
 
 const (
 	Version string = "MS3"
 )
 
+// Build information injected at link time by the magefile (via -ldflags "-X").
+// Empty if the binary was built without them.
+var (
+	Commit    string
+	BuildTime string
+)
+
+// BuildInfo describes the build that produced the running binary.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified"` // the working tree had uncommitted changes at build time
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// ReadBuildInfo composes the BuildInfo of the running binary.
+// Commit and build time prefer the values injected at link time,
+// falling back to the VCS information stamped in by the Go toolchain, if available.
+func ReadBuildInfo() BuildInfo {
+	bi := BuildInfo{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				bi.Commit = setting.Value
+			case "vcs.time":
+				bi.BuildTime = setting.Value
+			case "vcs.modified":
+				bi.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if Commit != "" {
+		bi.Commit = Commit
+	}
+	if BuildTime != "" {
+		bi.BuildTime = BuildTime
+	}
+	return bi
+}
+
+// WriteBuildInfoJSON writes the BuildInfo of the running binary to w as a single line of JSON.
+func WriteBuildInfoJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(ReadBuildInfo())
+}
+
+// AttachJSONVersion adds a --json-version flag to the given command.
+// When the flag is given, the command skips argument validation and its normal execution,
+// instead printing its BuildInfo as JSON.
+//
+// Must be called after the command's Args, PreRunE, and RunE are set.
+func AttachJSONVersion(cmd *cobra.Command) {
+	var jsonVersion bool
+	cmd.Flags().BoolVar(&jsonVersion, "json-version", false, "print version and build information as JSON, then exit")
+
+	args, preRunE, runE := cmd.Args, cmd.PreRunE, cmd.RunE
+	cmd.Args = func(cmd *cobra.Command, a []string) error {
+		if jsonVersion || args == nil {
+			return nil
+		}
+		return args(cmd, a)
+	}
+	cmd.PreRunE = func(cmd *cobra.Command, a []string) error {
+		if jsonVersion || preRunE == nil {
+			return nil
+		}
+		return preRunE(cmd, a)
+	}
+	cmd.RunE = func(cmd *cobra.Command, a []string) error {
+		if jsonVersion {
+			return WriteBuildInfoJSON(cmd.OutOrStdout())
+		}
+		if runE == nil {
+			return nil
+		}
+		return runE(cmd, a)
+	}
+}
+
+// ResultsDirPrefix prefixes the line the test runner prints to report the local directory it copied raw results into.
+// The coordinator scans the test runner's output for it so it can pass exactly that directory to the coalesce output module.
+const ResultsDirPrefix string = "omen results directory: "
+
 // Docker-related
 const (
 	InputValidatorImage       string = "0_omen-input-validator"
@@ -25,22 +121,65 @@ const (
 // Contains styles we are using.
 // NOTE(rlandau): Most are ripped out of charmtone and based on the default color schemes Fang uses.
 
+// HeaderStyles are the styles used for the short, highlighted headers that precede output (ex: "ERROR").
+type HeaderStyles struct {
+	Error   lipgloss.Style
+	Warning lipgloss.Style
+	Success lipgloss.Style
+}
+
+// NewHeaderStyles returns the header styles.
+// If color is false, the headers are rendered without colors or emphasis.
+func NewHeaderStyles(color bool) HeaderStyles {
+	base := lipgloss.NewStyle().Padding(0, 1)
+	if !color {
+		return HeaderStyles{Error: base, Warning: base, Success: base}
+	}
+	base = base.Foreground(lipgloss.Color("#FFFAF1")).Bold(true)
+	return HeaderStyles{
+		Error:   base.Background(lipgloss.Color("#FF388B")),
+		Warning: base.Background(lipgloss.Color("#fff348")),
+		Success: base.Background(lipgloss.Color("#00A475")),
+	}
+}
+
+// Header styles for output to stdout.
+// Colored unless NO_COLOR is set or stdout is not a terminal.
 var (
-	ErrorHeaderSty = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFAF1")).
-			Background(lipgloss.Color("#FF388B")).
-			Padding(0, 1).
-			Bold(true)
-	WarningHeaderSty = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFFAF1")).
-				Background(lipgloss.Color("#fff348")).
-				Padding(0, 1).
-				Bold(true)
+	ErrorHeaderSty, WarningHeaderSty, SuccessHeaderSty lipgloss.Style
 )
 
+func init() {
+	hs := NewHeaderStyles(ColorEnabled(os.Stdout))
+	ErrorHeaderSty, WarningHeaderSty, SuccessHeaderSty = hs.Error, hs.Warning, hs.Success
+}
+
+// ColorEnabled reports whether styled output should be written to w.
+// Returns false if NO_COLOR is set or w is not a terminal.
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	// fang wraps the error writer in a colorprofile.Writer; check the writer underneath it
+	if cw, ok := w.(*colorprofile.Writer); ok {
+		w = cw.Forward
+	}
+	f, ok := w.(interface{ Fd() uintptr })
+	return ok && term.IsTerminal(f.Fd())
+}
+
+// FangErrorHandler prints err to w.
+// The error is styled only if ColorEnabled(w); otherwise it is printed as plain text so logs stay readable.
 func FangErrorHandler(w io.Writer, styles fang.Styles, err error) {
 	// we use a custom error handler as the default one transforms to title case (which collapses newlines and we don't want that)
-	fmt.Fprintln(w, ErrorHeaderSty.Margin(1).MarginLeft(2).Render("ERROR"))
+	if !ColorEnabled(w) { // piped or redirected; keep the output free of escape codes
+		fmt.Fprintf(w, "ERROR: %v\n", err)
+		if isUsageError(err) {
+			fmt.Fprintln(w, "Try --help for usage.")
+		}
+		return
+	}
+	fmt.Fprintln(w, NewHeaderStyles(true).Error.Margin(1).MarginLeft(2).Render("ERROR"))
 	fmt.Fprintln(w, styles.ErrorText.UnsetTransform().Render(err.Error()))
 	fmt.Fprintln(w)
 	if isUsageError(err) {
@@ -0,0 +1,91 @@
+// Package prompt reads interactive input from the user on stdin.
+// All reads share a single buffered reader so input typed ahead of a prompt is not lost between calls.
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ErrNonInteractive is returned by all reads while prompting is disabled.
+var ErrNonInteractive = errors.New("prompting is disabled; refusing to read from stdin")
+
+var (
+	stdin       = bufio.NewReader(os.Stdin)
+	interactive = true
+	assumeYes   bool
+)
+
+// SetInteractive enables or disables prompting.
+// While disabled, reads fail immediately with ErrNonInteractive instead of blocking on stdin
+// (ex: so a CI job cannot hang on a prompt that will never be answered).
+func SetInteractive(enabled bool) {
+	interactive = enabled
+}
+
+// SetAssumeYes enables or disables answering every confirmation (see Confirm) with yes, without reading stdin.
+// Unlike disabling prompting (which fails confirmations rather than proceeding), assuming yes proceeds.
+// Prompts for information (ex: Prompt, PromptSecret) are unaffected.
+func SetAssumeYes(enabled bool) {
+	assumeYes = enabled
+}
+
+// AssumingYes reports whether confirmations are being answered with yes (see SetAssumeYes).
+func AssumingYes() bool {
+	return assumeYes
+}
+
+// Confirm prints label (ex: "Overwrite out.json? [y/N] ") and reports whether the user answered yes ("y" or "yes", in any case).
+// Any other answer is no.
+// If assuming yes, returns true without reading stdin, even while prompting is disabled.
+// Otherwise, returns an error if prompting is disabled or stdin is closed before anything is entered.
+func Confirm(label string) (bool, error) {
+	if assumeYes {
+		fmt.Println(label + "yes (assumed)")
+		return true, nil
+	}
+	answer, err := Prompt(label)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// Prompt prints label and returns the line the user enters, with surrounding whitespace trimmed.
+// Returns an error if prompting is disabled or stdin is closed before anything is entered.
+func Prompt(label string) (string, error) {
+	if !interactive {
+		return "", fmt.Errorf("%w (prompt: %q)", ErrNonInteractive, strings.TrimSpace(label))
+	}
+	fmt.Print(label)
+	input, err := stdin.ReadString('\n')
+	if err != nil && input == "" {
+		return "", fmt.Errorf("read stdin: %w", err)
+	}
+	return strings.TrimSpace(input), nil
+}
+
+// PromptSecret prints label and returns the line the user enters without echoing it to the terminal.
+// If stdin is not a terminal (ex: input is piped in), it falls back to Prompt's behavior.
+func PromptSecret(label string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !interactive || !term.IsTerminal(fd) {
+		return Prompt(label)
+	}
+	fmt.Print(label)
+	secret, err := term.ReadPassword(fd)
+	fmt.Println() // the user's newline was not echoed
+	if err != nil {
+		return "", fmt.Errorf("read stdin: %w", err)
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
@@ -0,0 +1,73 @@
+package omen
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RunDirNameFormat is the timestamp format (see time.Layout) of the directories the driver script writes raw results into.
+const RunDirNameFormat string = "20060102_150405"
+
+// ParseRunDirName parses the name of a run directory, of the form <timestamp> or <timestamp>_<N>.
+// The test runner appends _<N> when a directory for the same timestamp already exists, so a higher suffix indicates a later run.
+func ParseRunDirName(name string) (timestamp time.Time, suffix uint, ok bool) {
+	tsPart, suffixPart, hasSuffix := name, "", false
+	if len(name) > len(RunDirNameFormat) {
+		tsPart, suffixPart = name[:len(RunDirNameFormat)], name[len(RunDirNameFormat):]
+		if suffixPart, hasSuffix = strings.CutPrefix(suffixPart, "_"); !hasSuffix {
+			return time.Time{}, 0, false
+		}
+	}
+	timestamp, err := time.Parse(RunDirNameFormat, tsPart)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	if hasSuffix {
+		n, err := strconv.ParseUint(suffixPart, 10, 32)
+		if err != nil {
+			return time.Time{}, 0, false
+		}
+		suffix = uint(n)
+	}
+	return timestamp, suffix, true
+}
+
+// SortRunDirNames returns the given run directory names (see ParseRunDirName) ordered newest first.
+// Equal timestamps are ordered by descending suffix; duplicated names keep their relative order.
+// Names that do not parse are omitted.
+func SortRunDirNames(names []string) []string {
+	type run struct {
+		name   string
+		ts     time.Time
+		suffix uint
+	}
+	var runs []run
+	for _, name := range names {
+		if ts, suffix, ok := ParseRunDirName(name); ok {
+			runs = append(runs, run{name, ts, suffix})
+		}
+	}
+	slices.SortStableFunc(runs, func(a, b run) int {
+		if c := b.ts.Compare(a.ts); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.suffix, a.suffix)
+	})
+	sorted := make([]string, len(runs))
+	for i, r := range runs {
+		sorted[i] = r.name
+	}
+	return sorted
+}
+
+// LatestRunDirName returns the newest of the given run directory names (see SortRunDirNames).
+// ok is false if no names parse.
+func LatestRunDirName(names []string) (latest string, ok bool) {
+	if sorted := SortRunDirNames(names); len(sorted) > 0 {
+		return sorted[0], true
+	}
+	return "", false
+}
@@ -0,0 +1,89 @@
+package omen
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseRunDirName(t *testing.T) {
+	tests := []struct {
+		name       string
+		dirName    string
+		wantSuffix uint
+		wantOk     bool
+	}{
+		{"timestamp", "20251103_143345", 0, true},
+		{"suffixed", "20251103_143345_2", 2, true},
+		{"bad timestamp", "20251303_143345", 0, false},
+		{"non-numeric suffix", "20251103_143345_a", 0, false},
+		{"missing separator", "20251103_1433451", 0, false},
+		{"empty suffix", "20251103_143345_", 0, false},
+		{"short", "20251103_1433", 0, false},
+		{"empty", "", 0, false},
+		{"garbage", "bad_sub_dir_name", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, suffix, ok := ParseRunDirName(tt.dirName)
+			if ok != tt.wantOk {
+				t.Fatalf("ParseRunDirName(%q) ok = %v, want %v", tt.dirName, ok, tt.wantOk)
+			} else if suffix != tt.wantSuffix {
+				t.Errorf("ParseRunDirName(%q) suffix = %v, want %v", tt.dirName, suffix, tt.wantSuffix)
+			}
+		})
+	}
+}
+
+func TestLatestRunDirName(t *testing.T) {
+	tests := []struct {
+		name   string
+		names  []string
+		want   string
+		wantOk bool
+	}{
+		{"none", nil, "", false},
+		{"only malformed", []string{"bad_sub_dir_name", "20251303_143345", "20251103_143345_a", ""}, "", false},
+		{"single", []string{"20251103_143345"}, "20251103_143345", true},
+		{"newest timestamp", []string{"20251001_000000", "20251103_143345", "20251102_235959"}, "20251103_143345", true},
+		{"malformed skipped", []string{"zzz", "20251103_143345", "20991303_000000", "20251103_143345x"}, "20251103_143345", true},
+		{"equal timestamps; higher suffix", []string{"20251103_143345", "20251103_143345_2", "20251103_143345_1"}, "20251103_143345_2", true},
+		{"equal timestamps; suffix beats none", []string{"20251103_143345_1", "20251103_143345"}, "20251103_143345_1", true},
+		{"newer timestamp beats suffix", []string{"20251102_235959_9", "20251103_000000"}, "20251103_000000", true},
+		{"duplicates", []string{"20251103_143345", "20251103_143345"}, "20251103_143345", true},
+		{"listing order irrelevant", []string{"20251103_143345_2", "20251001_000000", "20251103_143345_10"}, "20251103_143345_10", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LatestRunDirName(tt.names)
+			if ok != tt.wantOk {
+				t.Fatalf("LatestRunDirName(%q) ok = %v, want %v", tt.names, ok, tt.wantOk)
+			} else if got != tt.want {
+				t.Errorf("LatestRunDirName(%q) = %q, want %q", tt.names, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortRunDirNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{"none", nil, []string{}},
+		{"only malformed", []string{"bad_sub_dir_name", "20251303_143345", ""}, []string{}},
+		{"newest first", []string{"20251001_000000", "20251103_143345", "20251102_235959"},
+			[]string{"20251103_143345", "20251102_235959", "20251001_000000"}},
+		{"suffixes descend", []string{"20251103_143345", "20251103_143345_10", "20251103_143345_2", "20251104_000000"},
+			[]string{"20251104_000000", "20251103_143345_10", "20251103_143345_2", "20251103_143345"}},
+		{"malformed omitted", []string{"zzz", "20251102_000000", "20251103_143345x", "20251103_143345"},
+			[]string{"20251103_143345", "20251102_000000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SortRunDirNames(tt.names); !slices.Equal(got, tt.want) {
+				t.Errorf("SortRunDirNames(%q) = %q, want %q", tt.names, got, tt.want)
+			}
+		})
+	}
+}
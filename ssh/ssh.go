@@ -0,0 +1,607 @@
+// Package ssh provides the SSH functionality shared by the modules that drive a remote mininet host.
+// It wraps golang.org/x/crypto/ssh with helpers for running commands, moving files (over SFTP), and driving interactive shells.
+package ssh
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/sftp"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Client is an authenticated connection to a remote host.
+type Client struct {
+	// Output receives the output of interactive sessions.
+	// Defaults to os.Stdout.
+	Output io.Writer
+	// If non-nil, Log also receives each line of output from interactive sessions, prefixed with the time it arrived.
+	// Unlike Output, lines containing the client's password are kept, with the password replaced by "[hidden]".
+	// Each line is written as it arrives; Log is not buffered.
+	Log io.Writer
+	// If non-nil, Input is forwarded line-by-line to interactive sessions (ex: os.Stdin to let the user drive the remote shell).
+	Input io.Reader
+	// If true, interrupts (Ctrl+C) received by this process during an interactive session are forwarded to the remote as SIGINT
+	// rather than terminating this process.
+	ForwardInterrupts bool
+	// If positive, interactive sessions are closed (and ErrInactive returned) if no line of output arrives within InactivityTimeout.
+	InactivityTimeout time.Duration
+	// If positive, interactive sessions that have not completed within SessionTimeout are exited (and ErrSessionTimeout returned).
+	SessionTimeout time.Duration
+
+	client   *gossh.Client
+	password string
+	jump     *Client // the connection client is tunneled through, if any; closed with client
+
+	sftpOnce sync.Once
+	sftp     *sftp.Client // opened on the first transfer; see sftpClient
+	sftpErr  error
+}
+
+// Connect dials addr (<host>:<port>), verifies the host's key with hostKeys, and authenticates as username using creds.
+func Connect(addr, username string, creds Credentials, hostKeys HostKeyCallback, timeout time.Duration) (*Client, error) {
+	client, err := gossh.Dial("tcp", addr, clientConfig(username, creds, hostKeys, timeout))
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Output: os.Stdout, client: client, password: creds.Password}, nil
+}
+
+// ConnectVia dials addr (<host>:<port>) through jump (ex: a connection to a bastion), as ssh's ProxyJump does,
+// verifies the host's key with hostKeys, and authenticates as username using creds.
+// The returned client owns jump: closing it closes jump as well. On failure, jump is left open.
+func ConnectVia(jump *Client, addr, username string, creds Credentials, hostKeys HostKeyCallback, timeout time.Duration) (*Client, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	conn, err := jump.client.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s through the jump host: %w", addr, err)
+	}
+	// tunneled connections do not support deadlines, so bound the handshake by closing the connection instead
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	c, chans, reqs, err := gossh.NewClientConn(conn, addr, clientConfig(username, creds, hostKeys, timeout))
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("handshake with %s through the jump host: %w", addr, ctx.Err())
+		}
+		return nil, err
+	}
+	return &Client{Output: os.Stdout, client: gossh.NewClient(c, chans, reqs), password: creds.Password, jump: jump}, nil
+}
+
+// Retryable reports whether err, as returned by Connect or ConnectVia, may succeed if retried:
+// the host refused, reset, or timed out the connection (ex: as sshd has not started yet), or dropped it during the handshake.
+// Failures to authenticate or verify the host's key are not retryable.
+func Retryable(err error) bool {
+	var (
+		ne net.Error
+		oe *gossh.OpenChannelError
+	)
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return true
+	case errors.As(err, &ne) && ne.Timeout():
+		return true
+	case errors.As(err, &oe): // the jump host could not reach the target
+		return oe.Reason == gossh.ConnectionFailed
+	case errors.Is(err, io.EOF), errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+	return false
+}
+
+// Credentials are what a connection authenticates with.
+type Credentials struct {
+	// Password authenticates the connection if Key is nil.
+	// Either way, lines of interactive output containing it are not echoed (ex: as it is sent to sudo).
+	Password string
+	// If non-nil, Key authenticates the connection by public key (see LoadPrivateKey) instead of Password.
+	Key gossh.Signer
+}
+
+// ErrPassphraseMissing is returned (wrapped) by LoadPrivateKey when the key is encrypted and no passphrase was given.
+var ErrPassphraseMissing = errors.New("private key is encrypted and no passphrase was given")
+
+// LoadPrivateKey reads and parses the private key at path, decrypting it with passphrase if it is encrypted.
+func LoadPrivateKey(path, passphrase string) (gossh.Signer, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read private key: %w", err)
+	}
+	var signer gossh.Signer
+	if passphrase == "" {
+		signer, err = gossh.ParsePrivateKey(pem)
+	} else {
+		signer, err = gossh.ParsePrivateKeyWithPassphrase(pem, []byte(passphrase))
+	}
+	if err != nil {
+		var missing *gossh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, fmt.Errorf("%w: %s", ErrPassphraseMissing, path)
+		}
+		return nil, fmt.Errorf("parse private key %s: %w", path, err)
+	}
+	return signer, nil
+}
+
+// clientConfig returns the configuration of a connection authenticating as username using creds.
+func clientConfig(username string, creds Credentials, hostKeys HostKeyCallback, timeout time.Duration) *gossh.ClientConfig {
+	auth := gossh.Password(creds.Password)
+	if creds.Key != nil {
+		auth = gossh.PublicKeys(creds.Key)
+	}
+	return &gossh.ClientConfig{
+		User:            username,
+		Auth:            []gossh.AuthMethod{auth},
+		HostKeyCallback: hostKeys,
+		Timeout:         timeout,
+	}
+}
+
+// A HostKeyCallback verifies the key a host presents during the handshake, returning an error to reject the connection.
+type HostKeyCallback = gossh.HostKeyCallback
+
+// InsecureIgnoreHostKey returns a HostKeyCallback that accepts every host key.
+// Connections are left open to interception, so it should only be used on trusted (ex: lab) networks.
+func InsecureIgnoreHostKey() HostKeyCallback {
+	return gossh.InsecureIgnoreHostKey()
+}
+
+// ErrUnknownHost is returned (wrapped) by a KnownHosts callback when the host has no key in the file and the key it presented was not accepted.
+var ErrUnknownHost = errors.New("host is not in known_hosts")
+
+// A HostKeyAcceptor is asked whether to trust the key (described by fingerprint) presented by hostname, which has no key in known_hosts.
+type HostKeyAcceptor func(hostname, fingerprint string) (bool, error)
+
+// KnownHosts returns a HostKeyCallback that verifies host keys against the known_hosts file at path.
+// The file (and its directory) is created if it does not exist.
+// If a host has no key in the file, accept is asked whether to trust the key it presented; accepted keys are appended to the file.
+// If accept is nil, unknown hosts are rejected.
+// Hosts presenting a key other than the one recorded for them are always rejected.
+func KnownHosts(path string, accept HostKeyAcceptor) (HostKeyCallback, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("create known_hosts directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("create known_hosts file: %w", err)
+	}
+	f.Close()
+	check, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("read known_hosts file %s: %w", path, err)
+	}
+
+	var mu sync.Mutex // guards check, which is replaced as keys are accepted
+	return func(hostname string, remote net.Addr, key gossh.PublicKey) error {
+		mu.Lock()
+		defer mu.Unlock()
+		var ke *knownhosts.KeyError
+		if err := check(hostname, remote, key); !errors.As(err, &ke) {
+			return err
+		} else if len(ke.Want) > 0 {
+			return fmt.Errorf("the %s key presented by %s does not match the one recorded in %s:%d; the connection may be intercepted",
+				key.Type(), hostname, ke.Want[0].Filename, ke.Want[0].Line)
+		}
+
+		fingerprint := key.Type() + " " + gossh.FingerprintSHA256(key)
+		if accept == nil {
+			return fmt.Errorf("%w: %s (%s)", ErrUnknownHost, hostname, fingerprint)
+		}
+		if ok, err := accept(hostname, fingerprint); err != nil {
+			return fmt.Errorf("%w: %s (%s): %w", ErrUnknownHost, hostname, fingerprint, err)
+		} else if !ok {
+			return fmt.Errorf("%w: the key of %s (%s) was rejected", ErrUnknownHost, hostname, fingerprint)
+		}
+		if err := appendKnownHost(path, hostname, key); err != nil {
+			return err
+		}
+		// reload, so later connections (ex: reconnects) do not ask again
+		if check, err = knownhosts.New(path); err != nil {
+			return fmt.Errorf("reload known_hosts file %s: %w", path, err)
+		}
+		return nil
+	}, nil
+}
+
+// appendKnownHost appends a line trusting key for hostname to the known_hosts file at path.
+func appendKnownHost(path, hostname string, key gossh.PublicKey) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open known_hosts file: %w", err)
+	}
+	if _, err := fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)); err != nil {
+		f.Close()
+		return fmt.Errorf("append to known_hosts file: %w", err)
+	}
+	return f.Close()
+}
+
+// Close closes the underlying connection (and that of its jump host, if any).
+func (c *Client) Close() error {
+	var err error
+	if c.sftp != nil {
+		err = c.sftp.Close()
+	}
+	err = errors.Join(err, c.client.Close())
+	if c.jump != nil {
+		err = errors.Join(err, c.jump.Close())
+	}
+	return err
+}
+
+// Run executes command on the remote host and returns its stdout.
+func (c *Client) Run(command string) (string, error) {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("create session: %w", err)
+	}
+	defer session.Close()
+
+	output, err := session.Output(command)
+	if err != nil {
+		return "", fmt.Errorf("run command '%s': %w", command, err)
+	}
+
+	return string(output), nil
+}
+
+// sftpClient returns the client's SFTP session, opening it on first use.
+// Every transfer over the connection shares the one session.
+func (c *Client) sftpClient() (*sftp.Client, error) {
+	c.sftpOnce.Do(func() {
+		if c.sftp, c.sftpErr = sftp.NewClient(c.client); c.sftpErr != nil {
+			c.sftpErr = fmt.Errorf("start SFTP session: %w", c.sftpErr)
+		}
+	})
+	return c.sftp, c.sftpErr
+}
+
+// Upload copies the file at localPath to remotePath on the remote host, replacing remotePath if it exists.
+func (c *Client) Upload(localPath, remotePath string) error {
+	sc, err := c.sftpClient()
+	if err != nil {
+		return err
+	}
+	local, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("open local file: %w", err)
+	}
+	defer local.Close()
+
+	remote, err := sc.Create(remotePath)
+	if err != nil {
+		return fmt.Errorf("create remote file %s: %w", remotePath, err)
+	}
+	if _, err := remote.ReadFrom(local); err != nil {
+		remote.Close()
+		return fmt.Errorf("write remote file %s: %w", remotePath, err)
+	}
+	// the server may only report a failed write (ex: a full disk) once the file is closed
+	if err := remote.Close(); err != nil {
+		return fmt.Errorf("close remote file %s: %w", remotePath, err)
+	}
+	return nil
+}
+
+// Download copies the file at remotePath on the remote host to localPath.
+func (c *Client) Download(remotePath, localPath string) error {
+	sc, err := c.sftpClient()
+	if err != nil {
+		return err
+	}
+	remote, err := sc.Open(remotePath)
+	if err != nil {
+		return fmt.Errorf("open remote file %s: %w", remotePath, err)
+	}
+	defer remote.Close()
+
+	local, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("create local file %s: %w", localPath, err)
+	}
+	if _, err := remote.WriteTo(local); err != nil {
+		local.Close()
+		return fmt.Errorf("read remote file %s: %w", remotePath, err)
+	}
+	if err := local.Close(); err != nil {
+		return fmt.Errorf("write local file %s: %w", localPath, err)
+	}
+	return nil
+}
+
+// ErrInactive is returned by RunInteractive when the remote produced no output for the client's InactivityTimeout.
+var ErrInactive = errors.New("remote session produced no output")
+
+// ErrSessionTimeout is returned by RunInteractive when the session did not complete within the client's SessionTimeout.
+var ErrSessionTimeout = errors.New("remote session timed out")
+
+// logTimeFormat is the format of the timestamp prefixing each line written to a client's Log.
+const logTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// exitGrace bounds how long a timed out session is given to exit before it is closed.
+const exitGrace = 5 * time.Second
+
+// ErrConnectionLost is returned by RunInteractive when the session ended without the remote reporting an exit (ex: the connection dropped).
+var ErrConnectionLost = errors.New("connection to the remote host was lost")
+
+// inactiveTailLines is the number of lines of prior output included with an ErrInactive.
+const inactiveTailLines int = 20
+
+// A PromptHandler inspects each line of output from an interactive session.
+// If response is non-empty, it is sent to the remote shell (a newline is appended).
+// If exit is true, the remote shell is exited after the response (if any) is sent.
+type PromptHandler func(line string) (response string, exit bool)
+
+// maxSudoResponses caps the number of times a SudoPrompt handler sends the password in a session.
+// As the password does not change, a repeated prompt means it was rejected; resending it would only spam the terminal.
+const maxSudoResponses int = 1
+
+// SudoPrompt returns a PromptHandler that answers sudo password prompts with password, at most maxSudoResponses times.
+// If pattern is non-nil, it alone identifies prompts (ex: under a non-English locale or a custom `Defaults passprompt`);
+// otherwise, English heuristics (ex: "[sudo] password for") are used.
+// A prompt seen once the responses are exhausted (ex: as the password was wrong) exits the shell rather than leaving it waiting.
+func SudoPrompt(password string, pattern *regexp.Regexp) PromptHandler {
+	var sent int
+	return func(line string) (string, bool) {
+		if !isSudoPrompt(line, pattern) {
+			return "", false
+		} else if sent >= maxSudoResponses {
+			return "", true
+		}
+		sent++
+		return password, false
+	}
+}
+
+// isSudoPrompt reports whether line is a sudo password prompt, as identified by pattern or, if pattern is nil, the English heuristics.
+func isSudoPrompt(line string, pattern *regexp.Regexp) bool {
+	if pattern != nil {
+		return pattern.MatchString(line)
+	}
+	lowerLine := strings.ToLower(line)
+	return (strings.Contains(lowerLine, "password") && strings.Contains(lowerLine, "sudo")) ||
+		strings.Contains(line, "[sudo]") ||
+		strings.Contains(lowerLine, "password for") ||
+		(strings.HasSuffix(strings.TrimSpace(line), ":") && strings.Contains(lowerLine, "password"))
+}
+
+// RunInteractive opens a shell on the remote host (with a pty), sends script, and echoes the shell's output to c.Output.
+// Each line of output is passed to every handler, in order; see PromptHandler.
+// Lines containing the client's password are not echoed.
+//
+// Returns once the remote shell exits, or ErrConnectionLost if the session ends without it exiting.
+// If c.InactivityTimeout is positive and elapses without a new line of output, the session is closed
+// and ErrInactive is returned along with the last lines of output seen.
+// If c.SessionTimeout is positive and elapses before the shell exits, the shell is asked to exit and ErrSessionTimeout is returned.
+func (c *Client) RunInteractive(script string, handlers []PromptHandler) error {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+	defer session.Close()
+
+	if err := session.RequestPty("xterm", 120, 40, gossh.TerminalModes{}); err != nil {
+		return fmt.Errorf("request pty: %w", err)
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("create stdin pipe: %w", err)
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("create stdout pipe: %w", err)
+	}
+	stderr, err := session.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("create stderr pipe: %w", err)
+	}
+	if err := session.Shell(); err != nil {
+		return fmt.Errorf("start shell: %w", err)
+	}
+
+	var (
+		outputsDone = make(chan bool)
+		activity    = make(chan struct{}, 1) // signalled on each line of output
+		tail        outputTail
+	)
+	go func() {
+		defer close(outputsDone)
+
+		scanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
+		for scanner.Scan() {
+			line := scanner.Text()
+			if c.password == "" || !strings.Contains(line, c.password) { // forbid password output on terminal
+				fmt.Fprintln(c.Output, line)
+				tail.add(line)
+			}
+			if c.Log != nil {
+				logged := line
+				if c.password != "" {
+					logged = strings.ReplaceAll(line, c.password, "[hidden]")
+				}
+				fmt.Fprintf(c.Log, "%s %s\n", time.Now().Format(logTimeFormat), logged)
+			}
+			select {
+			case activity <- struct{}{}:
+			default: // a signal is already pending
+			}
+
+			for _, h := range handlers {
+				response, exit := h(line)
+				if response != "" {
+					time.Sleep(300 * time.Millisecond)
+					stdin.Write([]byte(response + "\n"))
+				}
+				if exit {
+					time.Sleep(500 * time.Millisecond)
+					stdin.Write([]byte("exit\n"))
+					time.Sleep(500 * time.Millisecond)
+					return
+				}
+			}
+		}
+	}()
+
+	time.Sleep(500 * time.Millisecond)                              // wait for shell to be ready
+	if _, err := stdin.Write([]byte(script + "\n\n")); err != nil { // double newline to trigger sudo prompt
+		return fmt.Errorf("send command: %w", err)
+	}
+
+	sessionDone := make(chan struct{}) // closed once the session exits, to unwind the goroutines feeding it
+	defer close(sessionDone)
+	if c.Input != nil {
+		go func() {
+			userInput := bufio.NewScanner(c.Input)
+			// NOTE: a read already blocked on Input cannot be cancelled; it is dropped once it returns
+			for userInput.Scan() {
+				select {
+				case <-sessionDone:
+					return
+				default:
+				}
+				line := userInput.Text()
+				stdin.Write([]byte(line + "\n"))
+				if line == "exit" {
+					break
+				}
+			}
+		}()
+	}
+	if c.ForwardInterrupts {
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt)
+		defer signal.Stop(interrupts)
+		go forwardInterrupts(session, stdin, interrupts, sessionDone)
+	}
+
+	if err := c.waitActive(session, stdin, activity, &tail); err != nil {
+		var (
+			ee *gossh.ExitError
+			em *gossh.ExitMissingError
+		)
+		if errors.Is(err, ErrInactive) || errors.Is(err, ErrSessionTimeout) {
+			return err
+		} else if errors.As(err, &em) || errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: %v", ErrConnectionLost, err)
+		} else if !(errors.As(err, &ee) && ee.ExitStatus() == 130) { // 130 is normal for Ctrl+C
+			return fmt.Errorf("session error: %w", err)
+		}
+	}
+
+	// give additional time to output processing
+	select {
+	case <-outputsDone:
+	case <-time.After(5 * time.Second):
+	}
+
+	return nil
+}
+
+// forwardInterrupts sends SIGINT to session for each signal received on interrupts, until done is closed.
+// Servers that do not support signal requests are sent the pty's interrupt character (ETX), as a local terminal would.
+func forwardInterrupts(session *gossh.Session, stdin io.Writer, interrupts <-chan os.Signal, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-interrupts:
+			if err := session.Signal(gossh.SIGINT); err != nil {
+				stdin.Write([]byte{0x03})
+			}
+		}
+	}
+}
+
+// outputTail holds the last inactiveTailLines lines of a session's output.
+type outputTail struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (t *outputTail) add(line string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.lines = append(t.lines, line); len(t.lines) > inactiveTailLines {
+		t.lines = t.lines[1:]
+	}
+}
+
+func (t *outputTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strings.Join(t.lines, "\n")
+}
+
+// waitActive waits for session to exit, resetting the inactivity timer each time activity is signalled.
+// If c.InactivityTimeout elapses between signals, the session is closed and an ErrInactive (with the output seen last) is returned.
+// If c.SessionTimeout elapses before the session exits, the remote shell is asked to exit (see exitGracefully) and an ErrSessionTimeout is returned.
+func (c *Client) waitActive(session *gossh.Session, stdin io.Writer, activity <-chan struct{}, tail *outputTail) error {
+	waitErr := make(chan error, 1)
+	go func() { waitErr <- session.Wait() }()
+
+	var inactive, deadline <-chan time.Time // nil, and so never firing, unless their timeout is set
+	var inactivity *time.Timer
+	if c.InactivityTimeout > 0 {
+		inactivity = time.NewTimer(c.InactivityTimeout)
+		defer inactivity.Stop()
+		inactive = inactivity.C
+	}
+	if c.SessionTimeout > 0 {
+		t := time.NewTimer(c.SessionTimeout)
+		defer t.Stop()
+		deadline = t.C
+	}
+	for {
+		select {
+		case err := <-waitErr:
+			return err
+		case <-activity:
+			if inactivity != nil {
+				inactivity.Reset(c.InactivityTimeout)
+			}
+		case <-inactive:
+			session.Close()
+			if last := tail.String(); last != "" {
+				return fmt.Errorf("%w for %v. Last output:\n%s", ErrInactive, c.InactivityTimeout, last)
+			}
+			return fmt.Errorf("%w for %v; nothing was output", ErrInactive, c.InactivityTimeout)
+		case <-deadline:
+			exitGracefully(session, stdin, waitErr)
+			return fmt.Errorf("%w: the session did not complete within %v, so the remote run was cut off", ErrSessionTimeout, c.SessionTimeout)
+		}
+	}
+}
+
+// exitGracefully interrupts whatever the remote shell is running and asks it to exit (and log out),
+// closing session if it has not exited within exitGrace. waitErr receives the session's exit.
+func exitGracefully(session *gossh.Session, stdin io.Writer, waitErr <-chan error) {
+	stdin.Write([]byte{0x03}) // the pty's interrupt character, as Ctrl+C sends
+	time.Sleep(500 * time.Millisecond)
+	stdin.Write([]byte("exit\nlogout\n"))
+	select {
+	case <-waitErr:
+	case <-time.After(exitGrace):
+	}
+	session.Close()
+}
@@ -0,0 +1,137 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// startSFTPServer serves SFTP (over the local filesystem) on a loopback port, accepting the given password.
+// Returns the address it listens on.
+func startSFTPServer(t *testing.T, password string) string {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := gossh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &gossh.ServerConfig{
+		PasswordCallback: func(_ gossh.ConnMetadata, pass []byte) (*gossh.Permissions, error) {
+			if string(pass) != password {
+				return nil, gossh.ErrNoAuth
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, config)
+		}
+	}()
+	return l.Addr().String()
+}
+
+// serveSFTP handles a single connection, serving the sftp subsystem on each session channel.
+func serveSFTP(conn net.Conn, config *gossh.ServerConfig) {
+	_, chans, reqs, err := gossh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go gossh.DiscardRequests(reqs)
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			newChan.Reject(gossh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		ch, requests, err := newChan.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					if server, err := sftp.NewServer(ch); err == nil {
+						server.Serve()
+					}
+					ch.Close()
+				}
+			}
+		}()
+	}
+}
+
+func TestUploadDownload(t *testing.T) {
+	addr := startSFTPServer(t, "secret")
+	client, err := Connect(addr, "omen", Credentials{Password: "secret"}, InsecureIgnoreHostKey(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("Connect() = %v", err)
+	}
+	defer client.Close()
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "topo.json")
+	want := []byte(`{"topo": {}}` + "\n")
+	if err := os.WriteFile(local, want, 0644); err != nil {
+		t.Fatal(err)
+	}
+	remote := filepath.Join(dir, "remote dir", "topo file.json") // spaces must survive the transfer
+	if err := os.Mkdir(filepath.Dir(remote), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Upload(local, remote); err != nil {
+		t.Fatalf("Upload() = %v", err)
+	}
+	if got, err := os.ReadFile(remote); err != nil {
+		t.Fatal(err)
+	} else if string(got) != string(want) {
+		t.Errorf("uploaded file holds %q, want %q", got, want)
+	}
+
+	// uploading again replaces, rather than appends to, the remote file
+	want = []byte("{}\n")
+	if err := os.WriteFile(local, want, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Upload(local, remote); err != nil {
+		t.Fatalf("Upload() (replacing) = %v", err)
+	}
+
+	downloaded := filepath.Join(dir, "downloaded.json")
+	if err := client.Download(remote, downloaded); err != nil {
+		t.Fatalf("Download() = %v", err)
+	}
+	if got, err := os.ReadFile(downloaded); err != nil {
+		t.Fatal(err)
+	} else if string(got) != string(want) {
+		t.Errorf("downloaded file holds %q, want %q", got, want)
+	}
+
+	if err := client.Download(filepath.Join(dir, "missing.json"), downloaded); err == nil {
+		t.Error("Download() of a missing remote file succeeded")
+	}
+}